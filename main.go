@@ -1,11 +1,14 @@
 package main
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/noborus/ov/oviewer"
 	"github.com/spf13/cobra"
@@ -33,8 +36,20 @@ var (
 	completion bool
 	// execCommand targets the output of executing the command.
 	execCommand bool
+	// concat concatenates the files into one document.
+	concat bool
+	// exitInvert fails the exit status if the exit pattern is found.
+	exitInvert bool
+	// exitCode is the exit status of ov.
+	exitCode int
+	// setValues is a list of key=value that overwrites the config.
+	setValues []string
 )
 
+// exitNoMatch is the exit status when the exit pattern is not found
+// (or found with --exit-invert).
+const exitNoMatch = 2
+
 var (
 	// ErrCompletion indicates that the completion argument was invalid.
 	ErrCompletion = errors.New("requires one of the arguments bash/zsh/fish/powershell")
@@ -59,43 +74,59 @@ It supports various compressed files(gzip, bzip2, zstd, lz4, and xz).
 			return nil
 		}
 		if helpKey {
-			HelpKey(cmd, args)
-			return nil
+			return HelpKey(cmd, args)
 		}
 
 		if completion {
 			return Completion(cmd, args)
 		}
 
+		if execCommand && config.WatchInterval > 0 {
+			return WatchCommand(cmd, args)
+		}
 		if execCommand {
 			return ExecCommand(cmd, args)
 		}
 
-		ov, err := oviewer.Open(args...)
+		open := oviewer.Open
+		if concat {
+			open = oviewer.OpenConcat
+		}
+		ov, err := open(args...)
 		if err != nil {
 			return err
 		}
 		ov.SetConfig(config)
+		ov.SetSaveConfig(saveConfig)
+		ov.SetLoadConfig(loadConfig)
+		ov.SetIsSet(viper.IsSet)
 
 		if err := ov.Run(); err != nil {
 			return err
 		}
 
-		if ov.AfterWrite {
+		if ov.SelectOutput {
+			ov.WriteSelected()
+		} else if ov.AfterWrite {
 			ov.WriteOriginal()
 		}
 		if ov.Debug {
 			ov.WriteLog()
 		}
+		setExitCode(ov)
 		return nil
 	},
 }
 
 // HelpKey displays key bindings and exits.
-func HelpKey(cmd *cobra.Command, _ []string) {
+func HelpKey(cmd *cobra.Command, _ []string) error {
 	fmt.Println(cmd.Short)
-	keyBind := oviewer.GetKeyBinds(config.Keybind)
+	keyBind, err := oviewer.GetPresetKeyBinds(config.KeybindPreset, config.Keybind)
+	if err != nil {
+		return err
+	}
 	fmt.Println(oviewer.KeyBindString(keyBind))
+	return nil
 }
 
 // Completion is shell completion.
@@ -143,21 +174,94 @@ func ExecCommand(cmd *cobra.Command, args []string) error {
 	}()
 
 	ov.SetConfig(config)
+	ov.SetSaveConfig(saveConfig)
+	ov.SetLoadConfig(loadConfig)
+	ov.SetIsSet(viper.IsSet)
 
 	if err := ov.Run(); err != nil {
 		return err
 	}
 
-	if ov.AfterWrite {
+	if ov.SelectOutput {
+		ov.WriteSelected()
+	} else if ov.AfterWrite {
 		ov.WriteOriginal()
 	}
 	if ov.Debug {
 		ov.WriteLog()
 	}
+	setExitCode(ov)
+
+	return nil
+}
+
+// WatchCommand targets the output of the command run periodically.
+func WatchCommand(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return ErrNoArgument
+	}
 
+	ov, err := oviewer.WatchCommand(args...)
+	if err != nil {
+		return err
+	}
+
+	ov.SetConfig(config)
+	ov.SetSaveConfig(saveConfig)
+	ov.SetLoadConfig(loadConfig)
+	ov.SetIsSet(viper.IsSet)
+
+	if err := ov.Run(); err != nil {
+		return err
+	}
+
+	if ov.SelectOutput {
+		ov.WriteSelected()
+	} else if ov.AfterWrite {
+		ov.WriteOriginal()
+	}
+	if ov.Debug {
+		ov.WriteLog()
+	}
 	return nil
 }
 
+// setExitCode sets the exit status by the match of the exit pattern.
+func setExitCode(ov *oviewer.Root) {
+	if ov.ExitPattern == "" {
+		return
+	}
+	if ov.PatternMatched() == exitInvert {
+		exitCode = exitNoMatch
+	}
+}
+
+// saveConfig writes the changed settings to the config file.
+// If no config file is used, it writes to $HOME/.ov.yaml.
+// The settings are applied to the contents of the file read again,
+// so that the command line flags, the --set values and the environment variables
+// given for the run are not written.
+func saveConfig(settings map[string]interface{}) error {
+	fileName := viper.ConfigFileUsed()
+	if fileName == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return err
+		}
+		fileName = filepath.Join(home, ".ov.yaml")
+	}
+
+	v := viper.New()
+	v.SetConfigFile(fileName)
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	for key, value := range settings {
+		v.Set(key, value)
+	}
+	return v.WriteConfig()
+}
+
 func init() {
 	config = oviewer.NewConfig()
 	cobra.OnInitialize(initConfig)
@@ -166,7 +270,9 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&ver, "version", "v", false, "display version information")
 	rootCmd.PersistentFlags().BoolVarP(&helpKey, "help-key", "", false, "display key bind information")
 	rootCmd.PersistentFlags().BoolVarP(&execCommand, "exec", "e", false, "exec command")
+	rootCmd.PersistentFlags().BoolVarP(&concat, "concat", "", false, "concatenate the files into one document")
 	rootCmd.PersistentFlags().BoolVarP(&completion, "completion", "", false, "generate completion script [bash|zsh|fish|powershell]")
+	rootCmd.PersistentFlags().StringArrayVarP(&setValues, "set", "", nil, "set config value (key=value) e.g. --set StyleHeader.Bold=false")
 
 	// Config.General
 	rootCmd.PersistentFlags().IntP("tab-width", "x", 8, "tab stop width")
@@ -178,16 +284,49 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("alternate-rows", "C", false, "alternately change the line color")
 	_ = viper.BindPFlag("general.AlternateRows", rootCmd.PersistentFlags().Lookup("alternate-rows"))
 
+	rootCmd.PersistentFlags().IntP("alternate-every", "", 2, "color every n-th row in alternate rows")
+	_ = viper.BindPFlag("general.AlternateEvery", rootCmd.PersistentFlags().Lookup("alternate-every"))
+
 	rootCmd.PersistentFlags().BoolP("column-mode", "c", false, "column mode")
 	_ = viper.BindPFlag("general.ColumnMode", rootCmd.PersistentFlags().Lookup("column-mode"))
 
+	rootCmd.PersistentFlags().BoolP("cursor-line", "", false, "highlight the current line")
+	_ = viper.BindPFlag("general.CursorLine", rootCmd.PersistentFlags().Lookup("cursor-line"))
+
+	rootCmd.PersistentFlags().BoolP("column-band", "", false, "highlight the selected column as a vertical band")
+	_ = viper.BindPFlag("general.ColumnBand", rootCmd.PersistentFlags().Lookup("column-band"))
+
+	rootCmd.PersistentFlags().BoolP("cursor-move", "", false, "apply cursor movement escape sequences")
+	_ = viper.BindPFlag("general.CursorMove", rootCmd.PersistentFlags().Lookup("cursor-move"))
+
+	rootCmd.PersistentFlags().BoolP("collapse-cr", "", false, "display only the last state of lines rewritten by carriage returns")
+	_ = viper.BindPFlag("general.CollapseCR", rootCmd.PersistentFlags().Lookup("collapse-cr"))
+
+	rootCmd.PersistentFlags().StringP("section-delimiter", "", "", "section delimiter (regular expression)")
+	_ = viper.BindPFlag("general.SectionDelimiter", rootCmd.PersistentFlags().Lookup("section-delimiter"))
+
+	rootCmd.PersistentFlags().IntP("header-line", "", 0, "line number of the header row displayed after it scrolls out")
+	_ = viper.BindPFlag("general.HeaderLine", rootCmd.PersistentFlags().Lookup("header-line"))
+
+	rootCmd.PersistentFlags().IntP("section-header-num", "", 0, "number of section header lines")
+	_ = viper.BindPFlag("general.SectionHeaderNum", rootCmd.PersistentFlags().Lookup("section-header-num"))
+
+	rootCmd.PersistentFlags().BoolP("column-rainbow", "", false, "column rainbow")
+	_ = viper.BindPFlag("general.ColumnRainbow", rootCmd.PersistentFlags().Lookup("column-rainbow"))
+
+	rootCmd.PersistentFlags().BoolP("log-level", "", false, "highlight the log level")
+	_ = viper.BindPFlag("general.LogLevel", rootCmd.PersistentFlags().Lookup("log-level"))
+
+	rootCmd.PersistentFlags().BoolP("unusual-space", "", false, "highlight the full-width and invisible spaces")
+	_ = viper.BindPFlag("general.UnusualSpace", rootCmd.PersistentFlags().Lookup("unusual-space"))
+
 	rootCmd.PersistentFlags().BoolP("line-number", "n", false, "line number mode")
 	_ = viper.BindPFlag("general.LineNumMode", rootCmd.PersistentFlags().Lookup("line-number"))
 
 	rootCmd.PersistentFlags().BoolP("wrap", "w", true, "wrap mode")
 	_ = viper.BindPFlag("general.WrapMode", rootCmd.PersistentFlags().Lookup("wrap"))
 
-	rootCmd.PersistentFlags().StringP("column-delimiter", "d", ",", "column delimiter")
+	rootCmd.PersistentFlags().StringP("column-delimiter", "d", "", "column delimiter (guessed if empty)")
 	_ = viper.BindPFlag("general.ColumnDelimiter", rootCmd.PersistentFlags().Lookup("column-delimiter"))
 
 	rootCmd.PersistentFlags().BoolP("follow-mode", "f", false, "follow mode")
@@ -196,19 +335,120 @@ func init() {
 	rootCmd.PersistentFlags().BoolP("follow-all", "A", false, "follow all")
 	_ = viper.BindPFlag("general.FollowAll", rootCmd.PersistentFlags().Lookup("follow-all"))
 
+	rootCmd.PersistentFlags().BoolP("timestamp", "", false, "prefix the lines appended in follow mode with the arrival time")
+	_ = viper.BindPFlag("general.Timestamp", rootCmd.PersistentFlags().Lookup("timestamp"))
+
+	rootCmd.PersistentFlags().IntP("max-lines", "", 0, "retain only the most recent lines in the buffer (0 is unlimited)")
+	_ = viper.BindPFlag("general.MaxLines", rootCmd.PersistentFlags().Lookup("max-lines"))
+
 	// Config
 	rootCmd.PersistentFlags().BoolP("disable-mouse", "", false, "disable mouse support")
 	_ = viper.BindPFlag("DisableMouse", rootCmd.PersistentFlags().Lookup("disable-mouse"))
 
+	rootCmd.PersistentFlags().BoolP("scroll-bar", "", false, "display the scroll bar with the marks of the search matches")
+	_ = viper.BindPFlag("ScrollBar", rootCmd.PersistentFlags().Lookup("scroll-bar"))
+
+	rootCmd.PersistentFlags().BoolP("paste-literal", "", true, "escape the regular expression of the text pasted into the search")
+	_ = viper.BindPFlag("PasteLiteral", rootCmd.PersistentFlags().Lookup("paste-literal"))
+
+	rootCmd.PersistentFlags().BoolP("mouse-shift-bypass", "", true, "release the mouse to the terminal by clicking with shift")
+	_ = viper.BindPFlag("MouseShiftBypass", rootCmd.PersistentFlags().Lookup("mouse-shift-bypass"))
+
 	rootCmd.PersistentFlags().BoolP("exit-write", "X", false, "output the current screen when exiting")
 	_ = viper.BindPFlag("AfterWrite", rootCmd.PersistentFlags().Lookup("exit-write"))
 
 	rootCmd.PersistentFlags().BoolP("quit-if-one-screen", "F", false, "quit if the output fits on one screen")
 	_ = viper.BindPFlag("QuitSmall", rootCmd.PersistentFlags().Lookup("quit-if-one-screen"))
 
+	rootCmd.PersistentFlags().IntP("quit-wait", "", 0, "milliseconds to wait for the end of the input before quit-if-one-screen")
+	_ = viper.BindPFlag("QuitSmallWait", rootCmd.PersistentFlags().Lookup("quit-wait"))
+
+	rootCmd.PersistentFlags().BoolP("keep-empty", "", false, "stay open with a message on the empty input")
+	_ = viper.BindPFlag("KeepEmpty", rootCmd.PersistentFlags().Lookup("keep-empty"))
+
+	rootCmd.PersistentFlags().BoolP("select-output", "", false, "output the selected lines when exiting")
+	_ = viper.BindPFlag("SelectOutput", rootCmd.PersistentFlags().Lookup("select-output"))
+
+	rootCmd.PersistentFlags().StringP("exit-pattern", "", "", "exit with status 2 if the pattern is not found")
+	_ = viper.BindPFlag("ExitPattern", rootCmd.PersistentFlags().Lookup("exit-pattern"))
+
+	rootCmd.PersistentFlags().BoolVarP(&exitInvert, "exit-invert", "", false, "exit with status 2 if the exit pattern is found")
+
+	rootCmd.PersistentFlags().BoolP("quit-on-match", "", false, "quit as soon as the exit pattern is found")
+	_ = viper.BindPFlag("QuitOnMatch", rootCmd.PersistentFlags().Lookup("quit-on-match"))
+
 	rootCmd.PersistentFlags().BoolP("case-sensitive", "i", false, "case-sensitive in search")
 	_ = viper.BindPFlag("CaseSensitive", rootCmd.PersistentFlags().Lookup("case-sensitive"))
 
+	rootCmd.PersistentFlags().BoolP("search-wrap", "", false, "search wraps around at the end")
+	_ = viper.BindPFlag("SearchWrap", rootCmd.PersistentFlags().Lookup("search-wrap"))
+
+	rootCmd.PersistentFlags().StringP("jump-target", "", "", "position of the line moved to by search [N|N%|center]")
+	_ = viper.BindPFlag("JumpTarget", rootCmd.PersistentFlags().Lookup("jump-target"))
+
+	rootCmd.PersistentFlags().BoolP("remember-position", "", true, "restore the last position of the files")
+	_ = viper.BindPFlag("RememberPosition", rootCmd.PersistentFlags().Lookup("remember-position"))
+
+	rootCmd.PersistentFlags().IntP("scroll-off", "", 0, "number of lines of context above the line moved to by search")
+	_ = viper.BindPFlag("ScrollOff", rootCmd.PersistentFlags().Lookup("scroll-off"))
+
+	rootCmd.PersistentFlags().IntP("smooth-scroll", "", 0, "duration in milliseconds of the smooth scroll of the page movements")
+	_ = viper.BindPFlag("SmoothScroll", rootCmd.PersistentFlags().Lookup("smooth-scroll"))
+
+	rootCmd.PersistentFlags().BoolP("file-links", "", false, "highlight and open the file:line references")
+	_ = viper.BindPFlag("FileLinks", rootCmd.PersistentFlags().Lookup("file-links"))
+
+	rootCmd.PersistentFlags().StringP("background", "", "", "background of the terminal for the default styles [light|dark] (detected if empty)")
+	_ = viper.BindPFlag("Background", rootCmd.PersistentFlags().Lookup("background"))
+
+	rootCmd.PersistentFlags().IntP("max-colors", "", 0, "number of the colors of the terminal [8|16|256] (0 is auto)")
+	_ = viper.BindPFlag("MaxColors", rootCmd.PersistentFlags().Lookup("max-colors"))
+
+	rootCmd.PersistentFlags().BoolP("monochrome", "", false, "display without colors")
+	_ = viper.BindPFlag("Monochrome", rootCmd.PersistentFlags().Lookup("monochrome"))
+
+	rootCmd.PersistentFlags().BoolP("modeline", "", true, "apply the settings of the ov: modeline of the file")
+	_ = viper.BindPFlag("Modeline", rootCmd.PersistentFlags().Lookup("modeline"))
+
+	rootCmd.PersistentFlags().BoolP("regexp-search", "", true, "search with the regular expression")
+	_ = viper.BindPFlag("RegexpSearch", rootCmd.PersistentFlags().Lookup("regexp-search"))
+
+	rootCmd.PersistentFlags().IntP("max-fps", "", 0, "maximum number of the redraws per second (0 is unlimited)")
+	_ = viper.BindPFlag("MaxFPS", rootCmd.PersistentFlags().Lookup("max-fps"))
+
+	rootCmd.PersistentFlags().IntP("update-interval", "", 100, "interval in milliseconds to check the appended lines and redraw")
+	_ = viper.BindPFlag("UpdateInterval", rootCmd.PersistentFlags().Lookup("update-interval"))
+
+	rootCmd.PersistentFlags().IntP("follow-poll-interval", "", 0, "interval in milliseconds to poll the followed files (0 disables polling)")
+	_ = viper.BindPFlag("FollowPollInterval", rootCmd.PersistentFlags().Lookup("follow-poll-interval"))
+
+	rootCmd.PersistentFlags().IntP("chunk-size", "", 0, "size in bytes of the chunk buffers that the lines read are stored in (0 is 64KB)")
+	_ = viper.BindPFlag("ChunkSize", rootCmd.PersistentFlags().Lookup("chunk-size"))
+
+	rootCmd.PersistentFlags().IntP("read-ahead", "", 1, "number of the screens of the lines prepared in the background around the screen")
+	_ = viper.BindPFlag("ReadAhead", rootCmd.PersistentFlags().Lookup("read-ahead"))
+
+	rootCmd.PersistentFlags().IntP("read-buffer-size", "", 0, "size in bytes of the buffer to read the followed files")
+	_ = viper.BindPFlag("ReadBufferSize", rootCmd.PersistentFlags().Lookup("read-buffer-size"))
+
+	rootCmd.PersistentFlags().StringP("backpressure", "", "", "strategy when the lines arrive faster than they are displayed [block|drop|sample]")
+	_ = viper.BindPFlag("Backpressure", rootCmd.PersistentFlags().Lookup("backpressure"))
+
+	rootCmd.PersistentFlags().IntP("backpressure-lines", "", 10000, "number of the lines that can be appended between the updates of the screen")
+	_ = viper.BindPFlag("BackpressureLines", rootCmd.PersistentFlags().Lookup("backpressure-lines"))
+
+	rootCmd.PersistentFlags().BoolP("auto-table", "", false, "detect the bordered table of psql and mysql and set the header and column mode")
+	_ = viper.BindPFlag("AutoTable", rootCmd.PersistentFlags().Lookup("auto-table"))
+
+	rootCmd.PersistentFlags().IntP("watch", "", 0, "run the command of --exec every N seconds")
+	_ = viper.BindPFlag("WatchInterval", rootCmd.PersistentFlags().Lookup("watch"))
+
+	rootCmd.PersistentFlags().BoolP("watch-append", "", false, "append the output of each run of --watch instead of replacing it")
+	_ = viper.BindPFlag("WatchAppend", rootCmd.PersistentFlags().Lookup("watch-append"))
+
+	rootCmd.PersistentFlags().StringP("keybind-preset", "", "default", "key binding preset [default|vi|emacs]")
+	_ = viper.BindPFlag("KeybindPreset", rootCmd.PersistentFlags().Lookup("keybind-preset"))
+
 	rootCmd.PersistentFlags().BoolP("debug", "", false, "debug mode")
 	_ = viper.BindPFlag("Debug", rootCmd.PersistentFlags().Lookup("debug"))
 }
@@ -231,19 +471,102 @@ func initConfig() {
 		viper.SetConfigName(".ov")
 	}
 
+	// Environment variables with the prefix "OV_" overwrite the config.
+	// For example, OV_GENERAL_TABWIDTH=4, OV_STYLEHEADER_BOLD=false.
+	viper.SetEnvPrefix(envPrefix)
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv() // read in environment variables that match
+	bindEnvs(oviewer.NewConfig())
 
 	// If a config file is found, read it in.
 	_ = viper.ReadInConfig()
 
-	if err := viper.Unmarshal(&config); err != nil {
+	for _, s := range setValues {
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			fmt.Printf("invalid --set value: %s\n", s)
+			os.Exit(1)
+		}
+		viper.Set(kv[0], kv[1])
+	}
+
+	if err := unmarshalConfig(&config); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// envPrefix is the prefix of environment variables that overwrite the config.
+const envPrefix = "OV"
+
+// bindEnvs binds all keys of the config to environment variables.
+// viper.AutomaticEnv alone does not find keys that are not in the config file.
+func bindEnvs(c oviewer.Config) {
+	buf, err := json.Marshal(c)
+	if err != nil {
+		return
+	}
+	settings := make(map[string]interface{})
+	if err := json.Unmarshal(buf, &settings); err != nil {
+		return
+	}
+	for _, key := range configKeys("", settings) {
+		_ = viper.BindEnv(key)
+	}
+}
+
+// configKeys returns the keys of the nested settings joined by ".".
+func configKeys(prefix string, settings map[string]interface{}) []string {
+	keys := make([]string, 0, len(settings))
+	for k, v := range settings {
+		switch v := v.(type) {
+		case nil:
+			continue
+		case map[string]interface{}:
+			keys = append(keys, configKeys(prefix+k+".", v)...)
+		default:
+			keys = append(keys, prefix+k)
+		}
+	}
+	return keys
+}
+
+// unmarshalConfig sets the values read by viper to c.
+func unmarshalConfig(c *oviewer.Config) error {
+	if err := viper.Unmarshal(c); err != nil {
+		return err
+	}
+
+	if c.HistoryFile == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			c.HistoryFile = filepath.Join(dir, "ov", "history.json")
+		}
+	}
+	if c.PositionFile == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			c.PositionFile = filepath.Join(dir, "ov", "position.json")
+		}
+	}
+	return nil
+}
+
+// loadConfig reads the config file again.
+func loadConfig() (oviewer.Config, error) {
+	c := oviewer.NewConfig()
+	if err := viper.ReadInConfig(); err != nil {
+		return c, err
+	}
+	if err := unmarshalConfig(&c); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
 }
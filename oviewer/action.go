@@ -3,7 +3,9 @@ package oviewer
 import (
 	"fmt"
 	"log"
+	"os"
 	"strconv"
+	"strings"
 )
 
 // toggleWrapMode toggles wrapMode each time it is called.
@@ -14,6 +16,18 @@ func (root *Root) toggleWrapMode() {
 	root.setMessage(fmt.Sprintf("Set WrapMode %t", root.Doc.WrapMode))
 }
 
+// toggleTruncateMode toggles TruncateMode each time it is called.
+// Truncate mode turns off the wrap mode.
+func (root *Root) toggleTruncateMode() {
+	root.Doc.TruncateMode = !root.Doc.TruncateMode
+	if root.Doc.TruncateMode && root.Doc.WrapMode {
+		root.Doc.WrapMode = false
+		root.Doc.x = 0
+		root.setWrapHeaderLen()
+	}
+	root.setMessage(fmt.Sprintf("Set TruncateMode %t", root.Doc.TruncateMode))
+}
+
 //  toggleColumnMode toggles ColumnMode each time it is called.
 func (root *Root) toggleColumnMode() {
 	root.Doc.ColumnMode = !root.Doc.ColumnMode
@@ -27,6 +41,30 @@ func (root *Root) toggleAlternateRows() {
 	root.setMessage(fmt.Sprintf("Set AlternateRows %t", root.Doc.AlternateRows))
 }
 
+// toggleDimUnmatched toggles DimUnmatched every time it is called.
+func (root *Root) toggleDimUnmatched() {
+	root.Doc.DimUnmatched = !root.Doc.DimUnmatched
+	root.setMessage(fmt.Sprintf("Set DimUnmatched %t", root.Doc.DimUnmatched))
+}
+
+// toggleSearchWrap toggles SearchWrap every time it is called.
+func (root *Root) toggleSearchWrap() {
+	root.SearchWrap = !root.SearchWrap
+	root.setMessage(fmt.Sprintf("Set SearchWrap %t", root.SearchWrap))
+}
+
+// toggleColumnSearch toggles the search in the selected column every time it is called.
+func (root *Root) toggleColumnSearch() {
+	root.columnSearch = !root.columnSearch
+	root.setMessage(fmt.Sprintf("Set ColumnSearch %t", root.columnSearch))
+}
+
+// toggleSectionSearch toggles the search in the current section every time it is called.
+func (root *Root) toggleSectionSearch() {
+	root.sectionSearch = !root.sectionSearch
+	root.setMessage(fmt.Sprintf("Set SectionSearch %t", root.sectionSearch))
+}
+
 // toggleLineNumMode toggles LineNumMode every time it is called.
 func (root *Root) toggleLineNumMode() {
 	root.Doc.LineNumMode = !root.Doc.LineNumMode
@@ -74,6 +112,17 @@ func (root *Root) logDisplay() {
 	root.toLogDoc()
 }
 
+// toggleDebug toggles the debug mode,
+// which writes the messages and the timing of drawing and searching to the log.
+func (root *Root) toggleDebug() {
+	root.Debug = !root.Debug
+	if root.Debug {
+		root.setMessage("debug on")
+		return
+	}
+	root.setMessage("debug off")
+}
+
 func (root *Root) toLogDoc() {
 	root.setDocument(root.logDoc)
 	root.screenMode = LogDoc
@@ -88,38 +137,112 @@ func (root *Root) toNormal() {
 }
 
 // setWrapHeaderLen sets the value in wrapHeaderLen.
+// It also prepares the section header of the current position.
 func (root *Root) setWrapHeaderLen() {
+	root.prepareSection()
+	key := root.newWrapHeaderKey()
+	if key == root.wrapHeaderKey {
+		return
+	}
+	root.wrapHeaderLen = root.wrapRows(0, root.headerNum)
+	root.wrapHeaderKey = key
+}
+
+// wrapHeaderKey is the state that the number of the rows of the wrapped header depends on.
+type wrapHeaderKey struct {
+	doc         *Document
+	cacheGen    int
+	lines       int
+	width       int
+	startX      int
+	markerWidth int
+	wrap        bool
+}
+
+// newWrapHeaderKey returns the current wrapHeaderKey.
+func (root *Root) newWrapHeaderKey() wrapHeaderKey {
 	m := root.Doc
-	root.wrapHeaderLen = 0
-	for y := 0; y < root.Doc.Header; y++ {
-		lc, err := m.lineToContents(y, root.Doc.TabWidth)
+	m.cacheMu.Lock()
+	gen := m.cacheGen
+	m.cacheMu.Unlock()
+	return wrapHeaderKey{
+		doc:         m,
+		cacheGen:    gen,
+		lines:       min(root.headerNum, m.BufEndNum()),
+		width:       root.vWidth,
+		startX:      root.startX,
+		markerWidth: root.wrapMarkerWidth(),
+		wrap:        m.WrapMode,
+	}
+}
+
+// wrapRows returns the number of rows of num lines from lN when wrapped.
+func (root *Root) wrapRows(lN int, num int) int {
+	rows := 0
+	for y := lN; y < lN+num; y++ {
+		listX, err := root.leftMostX(y)
 		if err != nil {
 			log.Println(err, "WrapHeaderLen", y)
 			continue
 		}
-		root.wrapHeaderLen++
-		root.wrapHeaderLen += ((len(lc) - 1) / (root.vWidth - root.startX))
+		rows += len(listX)
 	}
+	return rows
 }
 
 // goLine will move to the specified line.
+// LINE:COL also scrolls horizontally to the column (character) of the line.
+// A string that is not a number moves to the section whose name contains it.
 func (root *Root) goLine(input string) {
-	lN, err := strconv.Atoi(input)
+	lN, col, err := parseLineCol(input)
 	if err != nil {
+		if root.Doc.SectionDelimiter != "" {
+			root.goSection(input)
+			return
+		}
 		root.setMessage(ErrInvalidNumber.Error())
 		return
 	}
 
-	root.moveLine(lN - root.Doc.Header - 1)
+	// The line numbers include the lines dropped from the buffer.
+	bLN, ok := root.Doc.bufferLN(lN - 1)
+	if !ok {
+		root.setMessage(fmt.Sprintf("line %d dropped", lN))
+		return
+	}
+	root.jumpLine(bLN)
+	if col > 0 {
+		root.jumpColumn(bLN, col)
+		root.setMessage(fmt.Sprintf("Moved to line %d:%d", lN, col))
+		return
+	}
 	root.setMessage(fmt.Sprintf("Moved to line %d", lN))
 }
 
+// parseLineCol parses LINE or LINE:COL.
+// col is 0 if it is omitted.
+func parseLineCol(input string) (lN int, col int, err error) {
+	s := strings.SplitN(strings.TrimSpace(input), ":", 2)
+	lN, err = strconv.Atoi(s[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(s) == 2 {
+		col, err = strconv.Atoi(s[1])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return lN, col, nil
+}
+
 // markLineNum stores the specified number of lines.
 func (root *Root) markLineNum() {
-	s := strconv.Itoa(root.Doc.topLN + 1)
+	lN := root.Doc.streamLN(root.Doc.topLN)
+	s := strconv.Itoa(lN + 1)
 	root.input.GoCandidate.list = toLast(root.input.GoCandidate.list, s)
 	root.input.GoCandidate.p = 0
-	root.setMessage(fmt.Sprintf("Marked to line %d", root.Doc.topLN))
+	root.setMessage(fmt.Sprintf("Marked to line %d", lN))
 }
 
 // setHeader sets the number of lines in the header.
@@ -163,7 +286,7 @@ func (root *Root) previousDoc() {
 
 func (root *Root) switchDocument(docNum int) {
 	root.setDocumentNum(docNum)
-	root.debugMessage(fmt.Sprintf("switch document %s", root.Doc.FileName))
+	root.debugMessage(fmt.Sprintf("switch document %s", root.Doc.Title()))
 }
 
 func (root *Root) addDocument(m *Document) {
@@ -171,6 +294,8 @@ func (root *Root) addDocument(m *Document) {
 	defer root.mu.Unlock()
 	log.Printf("add: %s", m.FileName)
 	m.general = root.Config.General
+	root.setReadOptions(m)
+	root.applyModeRules(m)
 
 	root.DocList = append(root.DocList, m)
 	root.CurrentDoc = len(root.DocList) - 1
@@ -196,6 +321,65 @@ func (root *Root) closeDocument() {
 	doc := root.DocList[root.CurrentDoc]
 
 	root.setDocument(doc)
+	m.release()
+}
+
+// closeOtherDocuments closes all documents except the current document.
+func (root *Root) closeOtherDocuments() {
+	current := root.Doc
+	n := root.closeDocuments(func(m *Document) bool {
+		return m != current
+	})
+	root.setMessage(fmt.Sprintf("closed %d documents", n))
+}
+
+// closeDerivedDocuments closes all documents made from other documents
+// (filter, sort, pipe, etc.).
+func (root *Root) closeDerivedDocuments() {
+	n := root.closeDocuments(func(m *Document) bool {
+		return m.derived
+	})
+	root.setMessage(fmt.Sprintf("closed %d documents", n))
+}
+
+// closeDocuments closes the documents for which closing returns true,
+// and returns the number of the closed documents.
+// At least one document is left open.
+func (root *Root) closeDocuments(closing func(*Document) bool) int {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	keep := make([]*Document, 0, len(root.DocList))
+	closed := make([]*Document, 0, len(root.DocList))
+	current := 0
+	for n, m := range root.DocList {
+		if closing(m) {
+			closed = append(closed, m)
+			continue
+		}
+		if n <= root.CurrentDoc {
+			current = len(keep)
+		}
+		keep = append(keep, m)
+	}
+	if len(keep) == 0 {
+		keep = append(keep, closed[0])
+		closed = closed[1:]
+	}
+	if len(closed) == 0 {
+		return 0
+	}
+
+	for _, m := range closed {
+		log.Printf("close %s", m.FileName)
+	}
+	root.DocList = keep
+	root.CurrentDoc = current
+	root.setDocument(root.DocList[current])
+	for _, m := range closed {
+		m.release()
+	}
+	return len(closed)
 }
 
 func (root *Root) setDocumentNum(docNum int) {
@@ -215,6 +399,7 @@ func (root *Root) setDocumentNum(docNum int) {
 
 func (root *Root) toggleMouse() {
 	root.Config.DisableMouse = !root.Config.DisableMouse
+	root.mouseReleased = false
 	if root.Config.DisableMouse {
 		root.Screen.DisableMouse()
 		root.setMessage("Disable Mouse")
@@ -224,14 +409,25 @@ func (root *Root) toggleMouse() {
 	}
 }
 
+// setViewMode sets the view mode.
+// The mode replaces the current setting based on the general setting.
+// With the "+" prefix, the mode is applied on top of the current setting.
 func (root *Root) setViewMode(input string) {
-	c, ok := root.Config.Mode[input]
-	if !ok {
-		if input != "general" {
-			root.setMessage(fmt.Sprintf("%s mode not found", input))
+	base := root.General
+	name := input
+	if strings.HasPrefix(input, "+") {
+		base = root.Doc.general
+		name = strings.TrimPrefix(input, "+")
+	}
+
+	c := base
+	if name != "general" {
+		g, err := root.modeGeneral(base, name)
+		if err != nil {
+			root.setMessage(err.Error())
 			return
 		}
-		c = root.General
+		c = g
 	}
 
 	root.Doc.general = c
@@ -263,9 +459,46 @@ func (root *Root) setTabWidth(input string) {
 	root.Doc.ClearCache()
 }
 
+// saveBuffer saves the buffer of the current document to a file.
+func (root *Root) saveBuffer(input string) {
+	if root.restricted() {
+		return
+	}
+	fileName := strings.TrimSpace(input)
+	if fileName == "" {
+		root.setMessage("save canceled")
+		return
+	}
+	fileName = expandHome(fileName)
+
+	if _, err := os.Stat(fileName); err == nil {
+		root.setMessage(fmt.Sprintf("%s already exists", fileName))
+		return
+	}
+
+	file, err := os.Create(fileName)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	defer file.Close()
+
+	if err := root.Doc.Export(file); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	root.setMessage(fmt.Sprintf("saved %s", fileName))
+}
+
 // resize is a wrapper function that calls viewSync.
 func (root *Root) resize() {
+	m := root.Doc
+	// Keep the line moved to at the jump target if it has not been moved since.
+	jumped := m.jumpLN >= 0 && m.topLN == m.jumpTopLN
 	root.ViewSync()
+	if jumped {
+		root.jumpLine(m.jumpLN)
+	}
 }
 
 // ViewSync redraws the whole thing.
@@ -273,9 +506,26 @@ func (root *Root) ViewSync() {
 	root.resetSelect()
 	root.prepareStartX()
 	root.prepareView()
+	root.reflowTop()
 	root.Screen.Sync()
 }
 
+// reflowTop moves topLX to the beginning of the wrapped row that contains it,
+// because the wrapping changes with the width of the screen.
+// The same part of the top line stays at the top after a resize.
+func (root *Root) reflowTop() {
+	m := root.Doc
+	if !m.WrapMode || m.topLX == 0 {
+		return
+	}
+	listX, err := root.leftMostX(m.topLN + m.Header)
+	if err != nil {
+		m.topLX = 0
+		return
+	}
+	m.topLX = listX[numOfReverseSlice(listX, m.topLX)]
+}
+
 // TailSync move to tail and sync.
 func (root *Root) TailSync() {
 	root.moveBottom()
@@ -286,13 +536,17 @@ func (root *Root) TailSync() {
 func (root *Root) prepareStartX() {
 	root.startX = 0
 	if root.Doc.LineNumMode {
-		root.startX = len(fmt.Sprintf("%d", root.Doc.BufEndNum())) + 1
+		root.startX = len(fmt.Sprintf("%d", root.Doc.BufEndNum()+root.Doc.trimmed)) + 1
 	}
+	root.startX += root.timestampWidth()
 }
 
 // updateEndNum updates the last line number.
-func (root *Root) updateEndNum() {
-	root.debugMessage(fmt.Sprintf("Update EndNum:%d", root.Doc.BufEndNum()))
+func (root *Root) updateEndNum(lines int) {
+	root.ringBuffer()
+	root.releaseBackpressure()
+	root.debugMessage(fmt.Sprintf("Update EndNum:%d (+%d)", root.Doc.BufEndNum(), lines))
 	root.prepareStartX()
+	root.movePendingLocation()
 	root.statusDraw()
 }
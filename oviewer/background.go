@@ -0,0 +1,164 @@
+package oviewer
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// backgroundQueryTimeout is the maximum time to wait for the response of the terminal.
+const backgroundQueryTimeout = 300 * time.Millisecond
+
+// backgroundQuery queries the background color (OSC 11),
+// followed by the primary device attributes (DA1) that every terminal responds to,
+// so that the terminals that do not support OSC 11 do not wait for the timeout.
+const backgroundQuery = "\x1b]11;?\x1b\\\x1b[c"
+
+// backgroundRegexp matches the response of OSC 11.
+var backgroundRegexp = regexp.MustCompile(`\x1b\]11;rgb:([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})/([0-9a-fA-F]{1,4})`)
+
+// da1Regexp matches the response of DA1.
+var da1Regexp = regexp.MustCompile(`\x1b\[\?[0-9;]*c`)
+
+// termBackground is the function that returns the background of the terminal.
+// It is replaced in the tests.
+var termBackground = queryBackground
+
+// queryBackground queries the background color of the terminal,
+// and returns "light", "dark" or an empty string if it is unknown.
+// It must be called before the screen is initialized.
+func queryBackground() string {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return ""
+	}
+	defer tty.Close()
+	// The terminals that do not respond are given up at the deadline.
+	if err := tty.SetReadDeadline(time.Now().Add(backgroundQueryTimeout)); err != nil {
+		return ""
+	}
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return ""
+	}
+	var state *term.State
+	if err := conn.Control(func(fd uintptr) {
+		state, err = term.MakeRaw(int(fd))
+	}); err != nil || state == nil {
+		return ""
+	}
+	defer func() {
+		_ = conn.Control(func(fd uintptr) {
+			_ = term.Restore(int(fd), state)
+		})
+	}()
+
+	if _, err := tty.WriteString(backgroundQuery); err != nil {
+		return ""
+	}
+	var resp bytes.Buffer
+	buf := make([]byte, 64)
+	for !da1Regexp.Match(resp.Bytes()) {
+		n, err := tty.Read(buf)
+		if err != nil {
+			break
+		}
+		resp.Write(buf[:n])
+	}
+	return parseBackground(resp.Bytes())
+}
+
+// parseBackground returns "light" or "dark" from the response of OSC 11.
+func parseBackground(resp []byte) string {
+	m := backgroundRegexp.FindSubmatch(resp)
+	if m == nil {
+		return ""
+	}
+	var rgb [3]float64
+	for i := 0; i < 3; i++ {
+		v, err := strconv.ParseUint(string(m[i+1]), 16, 16)
+		if err != nil {
+			return ""
+		}
+		// The components have 1 to 4 hex digits.
+		rgb[i] = float64(v) / float64(uint64(1)<<(4*len(m[i+1]))-1)
+	}
+	if 0.299*rgb[0]+0.587*rgb[1]+0.114*rgb[2] > 0.5 {
+		return "light"
+	}
+	return "dark"
+}
+
+// setLightStyles changes the default styles that are hard to read on a light background.
+// The styles changed from the defaults are kept.
+func (c *Config) setLightStyles() {
+	dark := NewConfig()
+	light := func(style *ovStyle, defaultStyle ovStyle, lightStyle ovStyle) {
+		if *style == defaultStyle {
+			*style = lightStyle
+		}
+	}
+	light(&c.StyleAlternate, dark.StyleAlternate, ovStyle{Background: "#e4e4e4"})
+	light(&c.StyleColumnBand, dark.StyleColumnBand, ovStyle{Background: "#e4e4e4"})
+	light(&c.StyleSelectedLine, dark.StyleSelectedLine, ovStyle{Background: "#d0d0ff"})
+	light(&c.StyleTimestamp, dark.StyleTimestamp, ovStyle{Foreground: "darkgreen"})
+	light(&c.StyleDiffAdd, dark.StyleDiffAdd, ovStyle{Foreground: "darkgreen"})
+	light(&c.StyleDiffChange, dark.StyleDiffChange, ovStyle{Foreground: "#af5f00"})
+	light(&c.StyleWatchChange, dark.StyleWatchChange, ovStyle{Bold: true, Foreground: "#af5f00"})
+	light(&c.StyleReplace, dark.StyleReplace, ovStyle{Foreground: "#af5f00", Underline: true})
+	if style, ok := c.StyleLogLevel["info"]; ok {
+		light(&style, dark.StyleLogLevel["info"], ovStyle{Foreground: "darkgreen"})
+		c.StyleLogLevel["info"] = style
+	}
+	if style, ok := c.StyleLogLevel["warn"]; ok {
+		light(&style, dark.StyleLogLevel["warn"], ovStyle{Foreground: "#af5f00"})
+		c.StyleLogLevel["warn"] = style
+	}
+	if equalStyles(c.StyleColumnRainbow, dark.StyleColumnRainbow) {
+		c.StyleColumnRainbow = []ovStyle{
+			{Foreground: "crimson"},
+			{Foreground: "darkcyan"},
+			{Foreground: "chocolate"},
+			{Foreground: "green"},
+			{Foreground: "blue"},
+			{Foreground: "olive"},
+		}
+	}
+	if equalStyles(c.StyleMergeSource, dark.StyleMergeSource) {
+		c.StyleMergeSource = []ovStyle{
+			{Foreground: "darkcyan"},
+			{Foreground: "chocolate"},
+			{Foreground: "green"},
+			{Foreground: "darkmagenta"},
+		}
+	}
+}
+
+// equalStyles returns true if the lists of the styles are equal.
+func equalStyles(a []ovStyle, b []ovStyle) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// applyBackground applies the styles for the background.
+// Background overrides the background detected from the terminal.
+func (root *Root) applyBackground() {
+	background := root.Background
+	if background == "" {
+		background = root.termBackground
+	}
+	if background == "light" {
+		root.Config.setLightStyles()
+	}
+}
@@ -0,0 +1,45 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func Test_parseBackground(t *testing.T) {
+	tests := []struct {
+		name string
+		resp string
+		want string
+	}{
+		{name: "white", resp: "\x1b]11;rgb:ffff/ffff/ffff\x1b\\\x1b[?62;22c", want: "light"},
+		{name: "black", resp: "\x1b]11;rgb:0000/0000/0000\x07\x1b[?1;2c", want: "dark"},
+		{name: "two digits", resp: "\x1b]11;rgb:fd/f6/e3\x07", want: "light"},
+		{name: "dark blue", resp: "\x1b]11;rgb:0000/2b2b/3636\x07", want: "dark"},
+		{name: "no response", resp: "\x1b[?62;22c", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseBackground([]byte(tt.resp)); got != tt.want {
+				t.Errorf("parseBackground() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_setLightStyles(t *testing.T) {
+	c := NewConfig()
+	c.StyleAlternate = ovStyle{Background: "pink"}
+	c.setLightStyles()
+	if c.StyleAlternate != (ovStyle{Background: "pink"}) {
+		t.Errorf("setLightStyles() changed the custom style %v", c.StyleAlternate)
+	}
+	dark := NewConfig()
+	if c.StyleColumnBand == dark.StyleColumnBand {
+		t.Errorf("setLightStyles() did not change the default style %v", c.StyleColumnBand)
+	}
+	if c.StyleLogLevel["warn"] == dark.StyleLogLevel["warn"] {
+		t.Errorf("setLightStyles() did not change the log level style %v", c.StyleLogLevel["warn"])
+	}
+	if c.StyleLogLevel["error"] != dark.StyleLogLevel["error"] {
+		t.Errorf("setLightStyles() changed the log level style %v", c.StyleLogLevel["error"])
+	}
+}
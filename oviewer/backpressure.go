@@ -0,0 +1,111 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// The strategies of the backpressure when the lines arrive faster than they are displayed.
+const (
+	// backpressureNone appends all lines.
+	backpressureNone int32 = iota
+	// backpressureBlock stops reading until the lines are displayed.
+	backpressureBlock
+	// backpressureDrop drops the lines and inserts a marker of the dropped lines.
+	backpressureDrop
+	// backpressureSample appends one of every backpressureSampleRate lines
+	// and inserts a marker of the skipped lines.
+	backpressureSample
+)
+
+// backpressureSampleRate is the rate of the lines appended in the sample strategy.
+const backpressureSampleRate = 100
+
+// backpressureWait is the interval to check whether the lines are displayed in the block strategy.
+const backpressureWait = 10 * time.Millisecond
+
+// backpressureStrategy returns the strategy of the name.
+func backpressureStrategy(name string) (int32, error) {
+	switch name {
+	case "":
+		return backpressureNone, nil
+	case "block":
+		return backpressureBlock, nil
+	case "drop":
+		return backpressureDrop, nil
+	case "sample":
+		return backpressureSample, nil
+	}
+	return backpressureNone, fmt.Errorf("%w: %s", ErrInvalidBackpressure, name)
+}
+
+// setBackpressure sets the strategy and the number of the lines
+// that can be appended between the updates of the screen.
+func (m *Document) setBackpressure(strategy int32, limit int) {
+	atomic.StoreInt32(&m.backpressureLimit, int32(limit))
+	atomic.StoreInt32(&m.backpressure, strategy)
+}
+
+// admit returns true if the line is appended under the backpressure.
+// It is called by the reader before appending a line.
+// The lines of regular files are always appended, because they are not lost by waiting.
+func (m *Document) admit() bool {
+	strategy := atomic.LoadInt32(&m.backpressure)
+	limit := atomic.LoadInt32(&m.backpressureLimit)
+	if strategy == backpressureNone || limit <= 0 || atomic.LoadInt32(&m.fileRead) == 1 {
+		return true
+	}
+	pending := atomic.AddInt32(&m.pending, 1)
+	if pending <= limit {
+		m.appendDropMarker()
+		return true
+	}
+
+	switch strategy {
+	case backpressureBlock:
+		for atomic.LoadInt32(&m.pending) > limit {
+			if m.checkClose() {
+				return false
+			}
+			time.Sleep(backpressureWait)
+		}
+		atomic.AddInt32(&m.pending, 1)
+		return true
+	case backpressureSample:
+		if (pending-limit)%backpressureSampleRate == 0 {
+			m.appendDropMarker()
+			return true
+		}
+	}
+	m.dropped++
+	return false
+}
+
+// appendDropMarker appends the marker of the dropped lines if there are.
+func (m *Document) appendDropMarker() {
+	if m.dropped == 0 {
+		return
+	}
+	format := "--- dropped %d lines ---"
+	if atomic.LoadInt32(&m.backpressure) == backpressureSample {
+		format = "--- sampled: skipped %d lines ---"
+	}
+	log.Printf("%s: dropped %d lines", m.FileName, m.dropped)
+	if observer := m.getObserver(); observer != nil {
+		observer.AddCount(MetricDroppedLines, m.dropped)
+	}
+	m.append(fmt.Sprintf(format, m.dropped))
+	m.dropped = 0
+}
+
+// releaseBackpressure allows the reader to append the lines again
+// after the screen has been updated.
+func (root *Root) releaseBackpressure() {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for _, doc := range root.DocList {
+		atomic.StoreInt32(&doc.pending, 0)
+	}
+}
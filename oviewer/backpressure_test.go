@@ -0,0 +1,110 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDocument_admit(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy int32
+		want     []string
+	}{
+		{
+			name:     "none",
+			strategy: backpressureNone,
+			want:     []string{"0", "1", "2", "3", "4", "5"},
+		},
+		{
+			name:     "drop",
+			strategy: backpressureDrop,
+			want:     []string{"0", "1", "--- dropped 4 lines ---"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.setBackpressure(tt.strategy, 2)
+			var b strings.Builder
+			for n := 0; n < 6; n++ {
+				b.WriteString(strconv.Itoa(n) + "\n")
+			}
+			if err := m.ReadAll(strings.NewReader(b.String())); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			got := []string{}
+			for n := 0; n < m.BufEndNum(); n++ {
+				got = append(got, m.GetLine(n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.admit() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_admitFile(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "large.txt")
+	var b strings.Builder
+	for n := 0; n < 10; n++ {
+		b.WriteString(strconv.Itoa(n) + "\n")
+	}
+	if err := os.WriteFile(fileName, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.setBackpressure(backpressureDrop, 2)
+	if err := m.ReadFile(fileName); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	// The lines of the regular file are not dropped.
+	if got := m.BufEndNum(); got != 10 {
+		t.Errorf("Document.ReadFile() read %d lines, want 10", got)
+	}
+}
+
+func TestDocument_admitSample(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.setBackpressure(backpressureSample, 1)
+	admitted := 0
+	for n := 0; n < 1+backpressureSampleRate*2; n++ {
+		if m.admit() {
+			admitted++
+		}
+	}
+	if admitted != 3 {
+		t.Errorf("Document.admit() admitted = %v, want %v", admitted, 3)
+	}
+	atomic.StoreInt32(&m.pending, 0)
+	if !m.admit() {
+		t.Errorf("Document.admit() = false after release")
+	}
+}
+
+func Test_backpressureStrategy(t *testing.T) {
+	for _, name := range []string{"", "block", "drop", "sample"} {
+		if _, err := backpressureStrategy(name); err != nil {
+			t.Errorf("backpressureStrategy(%q) error = %v", name, err)
+		}
+	}
+	if _, err := backpressureStrategy("fast"); err == nil {
+		t.Errorf("backpressureStrategy(%q) error = nil", "fast")
+	}
+}
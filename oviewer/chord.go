@@ -0,0 +1,160 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"code.rocketnine.space/tslocum/cbind"
+	"github.com/gdamore/tcell/v2"
+)
+
+// chordTimeout is the time to wait for the next key of a key sequence.
+// When it expires, the keys typed so far are handled as single keys.
+const chordTimeout = time.Second
+
+// chordBinds represents the bindings of the key sequences such as "g g" and "space f".
+type chordBinds struct {
+	// handlers is the handlers of the key sequences.
+	handlers map[string]func()
+	// prefixes is the set of the beginnings of the key sequences.
+	prefixes map[string]bool
+	// pending is the keys typed so far of a key sequence.
+	pending []*tcell.EventKey
+	// gen is incremented each time the pending keys are reset.
+	gen int
+}
+
+// eventChordTimeout represents the timeout of the key sequence.
+type eventChordTimeout struct {
+	gen int
+	tcell.EventTime
+}
+
+func newChordBinds() *chordBinds {
+	return &chordBinds{
+		handlers: make(map[string]func()),
+		prefixes: make(map[string]bool),
+	}
+}
+
+// isChord returns true if the key string is a key sequence.
+func isChord(k string) bool {
+	return len(strings.Fields(k)) > 1
+}
+
+// set sets the handler of the key sequence.
+func (c *chordBinds) set(k string, handler func()) error {
+	fields := strings.Fields(k)
+	names := make([]string, 0, len(fields))
+	for _, f := range fields {
+		mod, key, ch, err := cbind.Decode(f)
+		if err != nil {
+			return err
+		}
+		names = append(names, keyName(mod, key, ch))
+		seq := strings.Join(names, " ")
+		if len(names) < len(fields) {
+			c.prefixes[seq] = true
+			continue
+		}
+		c.handlers[seq] = handler
+	}
+	return nil
+}
+
+// keyName returns the name of the key to look up the key sequence.
+func keyName(mod tcell.ModMask, key tcell.Key, ch rune) string {
+	if key != tcell.KeyRune {
+		return fmt.Sprintf("%d-%d", mod, key)
+	}
+	// 'N' may be entered as "shift+N".
+	if 'A' <= ch && ch <= 'Z' {
+		mod &^= tcell.ModShift
+	}
+	return fmt.Sprintf("%d:%d", mod, ch)
+}
+
+// sequence returns the name of the pending keys followed by ev.
+func (c *chordBinds) sequence(ev *tcell.EventKey) string {
+	names := make([]string, 0, len(c.pending)+1)
+	for _, p := range c.pending {
+		names = append(names, keyName(p.Modifiers(), p.Key(), p.Rune()))
+	}
+	names = append(names, keyName(ev.Modifiers(), ev.Key(), ev.Rune()))
+	return strings.Join(names, " ")
+}
+
+// reset discards the pending keys and returns them.
+func (c *chordBinds) reset() []*tcell.EventKey {
+	pending := c.pending
+	c.pending = nil
+	c.gen++
+	return pending
+}
+
+// chordCapture handles the key as a part of a key sequence.
+// It returns false if the key is not a part of a key sequence.
+func (root *Root) chordCapture(ev *tcell.EventKey) bool {
+	c := root.chords
+	if c == nil || len(c.prefixes) == 0 {
+		return false
+	}
+
+	seq := c.sequence(ev)
+	if handler, ok := c.handlers[seq]; ok {
+		c.reset()
+		handler()
+		root.count = 0
+		return true
+	}
+	if c.prefixes[seq] {
+		c.pending = append(c.pending, ev)
+		root.setMessage(fmt.Sprintf("keys:%s", root.pendingKeys()))
+		gen := c.gen
+		time.AfterFunc(chordTimeout, func() {
+			ev := &eventChordTimeout{gen: gen}
+			ev.SetEventNow()
+			if err := root.Screen.PostEvent(ev); err != nil {
+				log.Println(err)
+			}
+		})
+		return true
+	}
+	if len(c.pending) == 0 {
+		return false
+	}
+
+	// Not a key sequence, the keys typed so far are handled as single keys.
+	for _, p := range c.reset() {
+		root.keyConfig.Capture(p)
+	}
+	return false
+}
+
+// chordExpired handles the keys typed so far as single keys
+// when the next key of the key sequence is not typed in time.
+func (root *Root) chordExpired(ev *eventChordTimeout) {
+	c := root.chords
+	if c == nil || ev.gen != c.gen || len(c.pending) == 0 {
+		return
+	}
+	for _, p := range c.reset() {
+		root.keyConfig.Capture(p)
+	}
+	root.count = 0
+}
+
+// pendingKeys returns the string of the keys typed so far of the key sequence.
+func (root *Root) pendingKeys() string {
+	keys := make([]string, 0, len(root.chords.pending))
+	for _, p := range root.chords.pending {
+		k, err := cbind.Encode(p.Modifiers(), p.Key(), p.Rune())
+		if err != nil {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	return strings.Join(keys, " ")
+}
@@ -0,0 +1,106 @@
+package oviewer
+
+import (
+	"testing"
+
+	"code.rocketnine.space/tslocum/cbind"
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_isChord(t *testing.T) {
+	tests := []struct {
+		k    string
+		want bool
+	}{
+		{k: "g", want: false},
+		{k: "space", want: false},
+		{k: "ctrl+alt+d", want: false},
+		{k: "g g", want: true},
+		{k: "space f", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.k, func(t *testing.T) {
+			if got := isChord(tt.k); got != tt.want {
+				t.Errorf("isChord() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_chordCapture(t *testing.T) {
+	tests := []struct {
+		name       string
+		bind       string
+		pending    []*tcell.EventKey
+		ev         *tcell.EventKey
+		wantOK     bool
+		wantCalled bool
+		wantSingle int
+	}{
+		{
+			name:       "complete",
+			bind:       "g g",
+			pending:    []*tcell.EventKey{tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone)},
+			ev:         tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone),
+			wantOK:     true,
+			wantCalled: true,
+		},
+		{
+			name:       "space",
+			bind:       "space f",
+			pending:    []*tcell.EventKey{tcell.NewEventKey(tcell.KeyRune, ' ', tcell.ModNone)},
+			ev:         tcell.NewEventKey(tcell.KeyRune, 'f', tcell.ModNone),
+			wantOK:     true,
+			wantCalled: true,
+		},
+		{
+			name:       "shift",
+			bind:       "g G",
+			pending:    []*tcell.EventKey{tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone)},
+			ev:         tcell.NewEventKey(tcell.KeyRune, 'G', tcell.ModShift),
+			wantOK:     true,
+			wantCalled: true,
+		},
+		{
+			name:       "not a sequence",
+			bind:       "g g",
+			pending:    nil,
+			ev:         tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+			wantOK:     false,
+			wantCalled: false,
+		},
+		{
+			name:       "broken sequence",
+			bind:       "g g",
+			pending:    []*tcell.EventKey{tcell.NewEventKey(tcell.KeyRune, 'g', tcell.ModNone)},
+			ev:         tcell.NewEventKey(tcell.KeyRune, 'j', tcell.ModNone),
+			wantOK:     false,
+			wantCalled: false,
+			wantSingle: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			single := 0
+			root := &Root{keyConfig: cbind.NewConfiguration(), chords: newChordBinds()}
+			root.keyConfig.SetRune(tcell.ModNone, 'g', wrapEventHandler(func() { single++ }))
+			if err := root.chords.set(tt.bind, func() { called = true }); err != nil {
+				t.Fatal(err)
+			}
+			root.chords.pending = tt.pending
+			if got := root.chordCapture(tt.ev); got != tt.wantOK {
+				t.Errorf("Root.chordCapture() = %v, want %v", got, tt.wantOK)
+			}
+			if called != tt.wantCalled {
+				t.Errorf("Root.chordCapture() called = %v, want %v", called, tt.wantCalled)
+			}
+			if single != tt.wantSingle {
+				t.Errorf("Root.chordCapture() single key called %v times, want %v", single, tt.wantSingle)
+			}
+			if len(root.chords.pending) != 0 {
+				t.Errorf("Root.chordCapture() pending = %v", root.chords.pending)
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package oviewer
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// defaultChunkSize is the size of the chunk buffer that the lines read are stored in
+// when ChunkSize is not set.
+const defaultChunkSize = 64 * 1024
+
+// setChunkSize sets the size of the chunk buffers, or the default size if size is not positive.
+func (m *Document) setChunkSize(size int) {
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	atomic.StoreInt32(&m.chunkSize, int32(size))
+}
+
+// chunkString returns the string of b stored in the chunk buffer.
+// The lines share the chunk buffer instead of being allocated one by one,
+// which reduces the allocations and the memory overhead per line.
+// The chunk buffer is only appended to, so the returned string never changes.
+// Lines longer than a quarter of the chunk are copied on their own.
+func (m *Document) chunkString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	size := int(atomic.LoadInt32(&m.chunkSize))
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	if len(b) > size/4 {
+		return string(b)
+	}
+	if cap(m.chunk)-len(m.chunk) < len(b) {
+		m.chunk = make([]byte, 0, size)
+	}
+	start := len(m.chunk)
+	m.chunk = append(m.chunk, b...)
+	s := m.chunk[start:len(m.chunk):len(m.chunk)]
+	return *(*string)(unsafe.Pointer(&s))
+}
@@ -0,0 +1,65 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument_chunkString(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		str  string
+	}{
+		{name: "empty", str: ""},
+		{name: "short", str: "abc"},
+		{name: "long", str: strings.Repeat("x", defaultChunkSize/2)},
+		{name: "almost a chunk", str: strings.Repeat("y", defaultChunkSize/4)},
+	}
+	got := make([]string, 0, len(tests))
+	for _, tt := range tests {
+		b := []byte(tt.str)
+		got = append(got, m.chunkString(b))
+		// The buffer read into is reused.
+		for i := range b {
+			b[i] = '-'
+		}
+	}
+	for i, tt := range tests {
+		if got[i] != tt.str {
+			t.Errorf("%s: Document.chunkString() = %.10q, want %.10q", tt.name, got[i], tt.str)
+		}
+	}
+
+	// The lines share the chunk buffer.
+	line := []byte("0123456789")
+	allocs := testing.AllocsPerRun(100, func() {
+		m.chunkString(line)
+	})
+	if allocs > 0.1 {
+		t.Errorf("Document.chunkString() allocs = %v, want less than 0.1", allocs)
+	}
+}
+
+func TestDocument_ReadAll_chunk(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make([]string, 20000)
+	for i := range lines {
+		lines[i] = strings.Repeat(string(rune('a'+i%26)), i%50)
+	}
+	if err := m.ReadAll(strings.NewReader(strings.Join(lines, "\n"))); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	for i, want := range lines {
+		if got := m.GetLine(i); got != want {
+			t.Fatalf("Document.GetLine(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
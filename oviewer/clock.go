@@ -0,0 +1,58 @@
+package oviewer
+
+import "time"
+
+// Clock is the interface of the time source of the periodic processing,
+// such as the follow mode and the watch mode.
+// It can be replaced with SetClock to drive the intervals deterministically.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a Ticker that delivers the time at the interval.
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker is the interface of time.Ticker.
+type Ticker interface {
+	// C returns the channel on which the ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker.
+	Stop()
+}
+
+// realClock is the Clock of the time package.
+type realClock struct{}
+
+// Now returns the current time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTicker returns a Ticker of time.Ticker.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+// realTicker is the Ticker of time.Ticker.
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+// C returns the channel on which the ticks are delivered.
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Stop turns off the ticker.
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// SetClock sets the Clock used for the update interval, follow polling,
+// exit pattern checking and the watch mode.
+func (root *Root) SetClock(clock Clock) {
+	if clock == nil {
+		clock = realClock{}
+	}
+	root.clock = clock
+}
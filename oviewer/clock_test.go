@@ -0,0 +1,68 @@
+package oviewer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock is the Clock whose ticks are sent by the test.
+type fakeClock struct {
+	now time.Time
+	c   chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), c: make(chan time.Time)}
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func (f *fakeClock) NewTicker(d time.Duration) Ticker {
+	return f
+}
+
+func (f *fakeClock) C() <-chan time.Time {
+	return f.c
+}
+
+func (f *fakeClock) Stop() {}
+
+// tick advances the clock and delivers the tick.
+func (f *fakeClock) tick(d time.Duration) {
+	f.now = f.now.Add(d)
+	f.c <- f.now
+}
+
+func TestRoot_watchLoop_clock(t *testing.T) {
+	clock := newFakeClock()
+	w := &watchCommand{args: []string{"echo", "a"}}
+	m, err := w.run(nil, clock.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &Root{Doc: m, DocList: []*Document{m}, watch: w}
+	root.WatchAppend = true
+	root.SetClock(clock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		root.watchLoop(ctx)
+		close(done)
+	}()
+	clock.tick(90 * time.Second)
+	clock.tick(90 * time.Second)
+	cancel()
+	<-done
+
+	// The second tick is received after the first run is finished.
+	want := []string{"a", "==> 00:01:30 <==", "a"}
+	for i, line := range want {
+		if got := m.GetLine(i); got != line {
+			t.Errorf("watchLoop() line %d = %v, want %v", i, got, line)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package oviewer
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// monochromeStyle returns the style without the colors.
+// The attributes such as bold and underline are kept if keepAttr is true,
+// otherwise only reverse is kept so that the highlights are still visible.
+func monochromeStyle(style tcell.Style, keepAttr bool) tcell.Style {
+	_, _, attr := style.Decompose()
+	if !keepAttr {
+		attr &= tcell.AttrReverse
+	}
+	return tcell.StyleDefault.Attributes(attr)
+}
+
+// colorPalette returns the palette of the first n colors.
+func colorPalette(n int) []tcell.Color {
+	palette := make([]tcell.Color, n)
+	for i := 0; i < n; i++ {
+		palette[i] = tcell.ColorValid + tcell.Color(i)
+	}
+	return palette
+}
+
+// downColor returns the nearest color of the palette,
+// if the color is an RGB color or out of the palette.
+func downColor(c tcell.Color, palette []tcell.Color) tcell.Color {
+	if !c.Valid() || (!c.IsRGB() && int(c-tcell.ColorValid) < len(palette)) {
+		return c
+	}
+	return tcell.FindColor(c, palette)
+}
+
+// downColorStyle returns the style with the colors of the palette.
+func downColorStyle(style tcell.Style, palette []tcell.Color) tcell.Style {
+	fg, bg, _ := style.Decompose()
+	return style.Foreground(downColor(fg, palette)).Background(downColor(bg, palette))
+}
+
+// convertColors converts the colors of the rows [y1, y2) of the screen
+// for Monochrome or Colors.
+// The colors of the escape sequences and the styles are converted
+// without changing the document.
+func (root *Root) convertColors(y1 int, y2 int) {
+	var palette []tcell.Color
+	if !root.Monochrome {
+		if root.MaxColors <= 0 || root.MaxColors >= 256 {
+			return
+		}
+		palette = colorPalette(root.MaxColors)
+	}
+	for y := y1; y < y2; y++ {
+		for x := 0; x < root.vWidth; x++ {
+			mainc, combc, style, _ := root.GetContent(x, y)
+			if root.Monochrome {
+				style = monochromeStyle(style, root.MonochromeAttributes)
+			} else {
+				style = downColorStyle(style, palette)
+			}
+			root.SetContent(x, y, mainc, combc, style)
+		}
+	}
+}
+
+// toggleMonochrome toggles Monochrome every time it is called.
+func (root *Root) toggleMonochrome() {
+	root.Monochrome = !root.Monochrome
+	root.setMessage(fmt.Sprintf("Set Monochrome %t", root.Monochrome))
+}
@@ -0,0 +1,70 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_monochromeStyle(t *testing.T) {
+	colored := tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlue)
+	tests := []struct {
+		name     string
+		style    tcell.Style
+		keepAttr bool
+		want     tcell.Style
+	}{
+		{
+			name:     "color",
+			style:    colored,
+			keepAttr: true,
+			want:     tcell.StyleDefault,
+		},
+		{
+			name:     "keep attributes",
+			style:    colored.Bold(true).Underline(true),
+			keepAttr: true,
+			want:     tcell.StyleDefault.Bold(true).Underline(true),
+		},
+		{
+			name:     "drop attributes",
+			style:    colored.Bold(true).Underline(true),
+			keepAttr: false,
+			want:     tcell.StyleDefault,
+		},
+		{
+			name:     "keep reverse",
+			style:    colored.Bold(true).Reverse(true),
+			keepAttr: false,
+			want:     tcell.StyleDefault.Reverse(true),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := monochromeStyle(tt.style, tt.keepAttr); got != tt.want {
+				t.Errorf("monochromeStyle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_downColor(t *testing.T) {
+	palette := colorPalette(16)
+	tests := []struct {
+		name  string
+		color tcell.Color
+		want  tcell.Color
+	}{
+		{name: "default", color: tcell.ColorDefault, want: tcell.ColorDefault},
+		{name: "in palette", color: tcell.ColorRed, want: tcell.ColorRed},
+		{name: "rgb red", color: tcell.NewRGBColor(250, 0, 0), want: tcell.ColorRed},
+		{name: "256 color", color: tcell.ColorValid + 21, want: tcell.ColorBlue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := downColor(tt.color, palette); got != tt.want {
+				t.Errorf("downColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package oviewer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// completer is implemented by the input that supports Tab completion.
+type completer interface {
+	// Complete returns the completed string and a hint.
+	Complete(str string) (string, string)
+}
+
+// hinter is implemented by the input that displays a hint while typing.
+type hinter interface {
+	// Hint returns a hint for the input string.
+	Hint(str string) string
+}
+
+// canceler is an EventInput that restores its state when the input is canceled.
+type canceler interface {
+	// Cancel is called when the input is canceled by Escape.
+	Cancel()
+}
+
+// cycleCompletion represents the state of completion.
+// Pressing Tab repeatedly cycles through the candidates.
+type cycleCompletion struct {
+	// list is the list of candidates.
+	list []string
+	// p is the position of the current candidate.
+	p int
+	// last is the string returned last time.
+	last string
+}
+
+// pathCompletion represents the state of file path completion.
+type pathCompletion struct {
+	cycleCompletion
+}
+
+// Complete returns the completed path and a hint of the candidates.
+func (c *pathCompletion) Complete(str string) (string, string) {
+	return c.complete(str, pathCandidates)
+}
+
+// complete returns the next candidate if str is the last candidate,
+// otherwise the first of the candidates of str, and a hint of the candidates.
+// If str is the only candidate, it is completed again,
+// so that a completed directory lists its entries.
+func (c *cycleCompletion) complete(str string, candidates func(string) []string) (string, string) {
+	if len(c.list) > 1 && str == c.last {
+		c.p = (c.p + 1) % len(c.list)
+		c.last = c.list[c.p]
+		return c.last, c.hint()
+	}
+
+	c.list = candidates(str)
+	c.p = 0
+	if len(c.list) == 0 {
+		c.last = ""
+		return str, "no match"
+	}
+	c.last = c.list[c.p]
+	return c.last, c.hint()
+}
+
+// hint returns the position of the current candidate.
+func (c *cycleCompletion) hint() string {
+	if len(c.list) == 1 {
+		return ""
+	}
+	return fmt.Sprintf("[%d/%d]", c.p+1, len(c.list))
+}
+
+// pathCandidates returns a list of paths that start with str.
+// Directories have a trailing path separator.
+func pathCandidates(str string) []string {
+	dir, prefix := filepath.Split(str)
+	readDir := expandHome(dir)
+	if readDir == "" {
+		readDir = "."
+	}
+
+	entries, err := os.ReadDir(readDir)
+	if err != nil {
+		return nil
+	}
+
+	list := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		// Hidden files are candidates only when explicitly requested.
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".") {
+			continue
+		}
+		path := dir + name
+		if isDirEntry(readDir, entry) {
+			path += string(filepath.Separator)
+		}
+		list = append(list, path)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// isDirEntry returns true if the entry is a directory or a symbolic link to a directory.
+func isDirEntry(dir string, entry os.DirEntry) bool {
+	if entry.Type()&os.ModeSymlink == 0 {
+		return entry.IsDir()
+	}
+	fi, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && fi.IsDir()
+}
+
+// expandHome replaces the leading "~" with the home directory.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return home + path[1:]
+}
@@ -0,0 +1,99 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_pathCandidates(t *testing.T) {
+	type args struct {
+		str string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "testFile",
+			args: args{str: "../testdata/te"},
+			want: []string{"../testdata/test.txt"},
+		},
+		{
+			name: "testDir",
+			args: args{str: "../testd"},
+			want: []string{"../testdata/"},
+		},
+		{
+			name: "testNoMatch",
+			args: args{str: "../testdata/notfound"},
+			want: []string{},
+		},
+		{
+			name: "testNoDir",
+			args: args{str: "../notfound/file"},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pathCandidates(tt.args.str); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pathCandidates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_pathCompletion_Complete(t *testing.T) {
+	c := &pathCompletion{}
+	got, hint := c.Complete("../testdata/te")
+	if got != "../testdata/test.txt" || hint != "" {
+		t.Errorf("pathCompletion.Complete() = %v, %v", got, hint)
+	}
+	got, hint = c.Complete("../testdata/notfound")
+	if got != "../testdata/notfound" || hint != "no match" {
+		t.Errorf("pathCompletion.Complete() = %v, %v", got, hint)
+	}
+}
+
+func Test_pathCompletion_CompleteDir(t *testing.T) {
+	tmp := t.TempDir()
+	dir := filepath.Join(tmp, "sub")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sep := string(filepath.Separator)
+	c := &pathCompletion{}
+	tests := []struct {
+		str      string
+		want     string
+		wantHint string
+	}{
+		{str: tmp + sep + "su", want: dir + sep, wantHint: ""},
+		// The next Tab lists the entries of the completed directory.
+		{str: dir + sep, want: dir + sep + "a.txt", wantHint: "[1/2]"},
+		{str: dir + sep + "a.txt", want: dir + sep + "b.txt", wantHint: "[2/2]"},
+	}
+	for _, tt := range tests {
+		got, hint := c.Complete(tt.str)
+		if got != tt.want || hint != tt.wantHint {
+			t.Errorf("pathCompletion.Complete(%q) = %q, %q, want %q, %q", tt.str, got, hint, tt.want, tt.wantHint)
+		}
+	}
+
+	// A symbolic link to a directory is completed as a directory.
+	link := filepath.Join(tmp, "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skip(err)
+	}
+	if got := pathCandidates(link); !reflect.DeepEqual(got, []string{link + sep}) {
+		t.Errorf("pathCandidates() = %v, want %v", got, []string{link + sep})
+	}
+}
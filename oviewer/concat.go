@@ -0,0 +1,190 @@
+package oviewer
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// concatSeparator is the format of the separator line at the beginning of each file.
+const concatSeparator = "==> %s <=="
+
+// concatFile represents a file of the concatenated document.
+type concatFile struct {
+	// lN is the line number of the separator line,
+	// or the first line left if the separator line has been trimmed.
+	lN   int
+	name string
+	// trimmed is true if the separator line has been trimmed.
+	trimmed bool
+}
+
+// OpenConcat reads the files into one document
+// with a separator line at the beginning of each file.
+func OpenConcat(fileNames ...string) (*Root, error) {
+	if len(fileNames) == 0 {
+		return openSTDIN()
+	}
+
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ReadConcat(fileNames); err != nil {
+		return nil, err
+	}
+	return NewOviewer(m)
+}
+
+// ReadConcat reads the files in order, each following the separator line.
+func (m *Document) ReadConcat(fileNames []string) error {
+	names := make([]string, 0, len(fileNames))
+	for _, fileName := range fileNames {
+		fi, err := os.Stat(fileName)
+		if err != nil {
+			log.Println(err, fileName)
+			continue
+		}
+		if fi.IsDir() {
+			continue
+		}
+		names = append(names, fileName)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("%w: %s", ErrMissingFile, fileNames[0])
+	}
+	m.FileName = strings.Join(names, ",")
+	atomic.StoreInt32(&m.fileRead, 1)
+
+	go func() {
+		for _, name := range names {
+			if m.checkClose() {
+				return
+			}
+			if err := m.readConcatFile(name); err != nil {
+				log.Printf("%s: %v", name, err)
+			}
+		}
+		close(m.eofCh)
+		atomic.StoreInt32(&m.eof, 1)
+	}()
+	return nil
+}
+
+// readConcatFile appends the separator line and the lines of the file.
+func (m *Document) readConcatFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	m.mu.Lock()
+	m.concatFiles = append(m.concatFiles, concatFile{lN: m.endNum, name: name})
+	m.mu.Unlock()
+	m.append(fmt.Sprintf(concatSeparator, name))
+
+	_, r := uncompressedReader(f)
+	defer stopReader(r)
+	if err := m.readAll(bufio.NewReader(r)); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// concatFileIndex returns the index of the file that contains the line.
+// It returns -1 if the document is not concatenated.
+func (m *Document) concatFileIndex(lN int) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	i := sort.Search(len(m.concatFiles), func(i int) bool {
+		return m.concatFiles[i].lN > lN
+	})
+	return i - 1
+}
+
+// concatFileName returns the name of the file that contains the line.
+func (m *Document) concatFileName(lN int) string {
+	i := m.concatFileIndex(lN)
+	if i < 0 {
+		return ""
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.concatFiles[i].name
+}
+
+// isConcatSeparator returns true if the line is the separator line of a file.
+func (m *Document) isConcatSeparator(lN int) bool {
+	i := m.concatFileIndex(lN)
+	if i < 0 {
+		return false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.concatFiles[i].lN == lN && !m.concatFiles[i].trimmed
+}
+
+// trimConcatFiles returns the files of the concatenated document after n lines from start are dropped.
+// The files whose lines are all dropped are removed,
+// and the file whose separator line is dropped starts at the first line left.
+func trimConcatFiles(files []concatFile, start int, n int) []concatFile {
+	result := make([]concatFile, 0, len(files))
+	for i, f := range files {
+		if lN, ok := trimmedLN(f.lN, start, n); ok {
+			f.lN = lN
+			result = append(result, f)
+			continue
+		}
+		if i+1 < len(files) && files[i+1].lN <= start+n {
+			continue
+		}
+		f.lN = start
+		f.trimmed = true
+		result = append(result, f)
+	}
+	return result
+}
+
+// concatFileLine returns the line number of the separator line of the i-th file.
+func (m *Document) concatFileLine(i int) (int, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i < 0 || i >= len(m.concatFiles) {
+		return 0, false
+	}
+	return m.concatFiles[i].lN, true
+}
+
+// nextFile moves to the beginning of the next file of the concatenated document.
+func (root *Root) nextFile() {
+	root.moveFile(1)
+}
+
+// previousFile moves to the beginning of the current or previous file of the concatenated document.
+func (root *Root) previousFile() {
+	m := root.Doc
+	if m.isConcatSeparator(m.topLN + m.Header) {
+		root.moveFile(-1)
+		return
+	}
+	root.moveFile(0)
+}
+
+// moveFile moves to the file relative to the file of the current line.
+func (root *Root) moveFile(delta int) {
+	m := root.Doc
+	i := m.concatFileIndex(m.topLN+m.Header) + delta
+	lN, ok := m.concatFileLine(i)
+	if !ok {
+		return
+	}
+	root.moveLine(lN - m.Header)
+	root.setMessage(fmt.Sprintf("Moved to %s", m.concatFileName(lN)))
+}
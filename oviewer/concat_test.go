@@ -0,0 +1,67 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_ReadConcat(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.txt")
+	b := filepath.Join(dir, "b.txt")
+	if err := os.WriteFile(a, []byte("a1\na2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b, []byte("b1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadConcat([]string{a, dir, b}); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+
+	got := make([]string, 0)
+	for n := 0; n < m.BufEndNum(); n++ {
+		got = append(got, m.GetLine(n))
+	}
+	want := []string{"==> " + a + " <==", "a1", "a2", "==> " + b + " <==", "b1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Document.ReadConcat() = %v, want %v", got, want)
+	}
+
+	tests := []struct {
+		lN            int
+		wantName      string
+		wantSeparator bool
+	}{
+		{lN: 0, wantName: a, wantSeparator: true},
+		{lN: 2, wantName: a, wantSeparator: false},
+		{lN: 3, wantName: b, wantSeparator: true},
+		{lN: 4, wantName: b, wantSeparator: false},
+	}
+	for _, tt := range tests {
+		if got := m.concatFileName(tt.lN); got != tt.wantName {
+			t.Errorf("Document.concatFileName(%d) = %v, want %v", tt.lN, got, tt.wantName)
+		}
+		if got := m.isConcatSeparator(tt.lN); got != tt.wantSeparator {
+			t.Errorf("Document.isConcatSeparator(%d) = %v, want %v", tt.lN, got, tt.wantSeparator)
+		}
+	}
+}
+
+func TestDocument_ReadConcatMissing(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadConcat([]string{filepath.Join(t.TempDir(), "none")}); err == nil {
+		t.Errorf("Document.ReadConcat() error = nil, want error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"log"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -42,7 +43,36 @@ var DefaultContent = content{
 // parseString converts a string to lineContents.
 // parseString includes escape sequences and tabs.
 func parseString(line string, tabWidth int) lineContents {
+	return parseLine(line, tabWidth, false)
+}
+
+// parseLine converts a string to lineContents.
+// If cursorMove is true, carriage return and the cursor movement and erase
+// sequences move the position to write, as on the terminal.
+func parseLine(line string, tabWidth int, cursorMove bool) lineContents {
 	lc := lineContents{}
+	// cursor is the position to write, or -1 to append.
+	cursor := -1
+	if cursorMove {
+		cursor = 0
+	}
+	put := func(cs ...content) {
+		if cursor < 0 {
+			lc = append(lc, cs...)
+			return
+		}
+		for len(lc) < cursor {
+			lc = append(lc, spaceContent)
+		}
+		for _, c := range cs {
+			if cursor < len(lc) {
+				lc[cursor] = c
+			} else {
+				lc = append(lc, c)
+			}
+			cursor++
+		}
+	}
 	state := ansiText
 	csiParameter := new(bytes.Buffer)
 	style := tcell.StyleDefault
@@ -69,17 +99,27 @@ func parseString(line string, tabWidth int) lineContents {
 			case 'P', ']', 'X', '^', '_': // Substrings and commands.
 				state = ansiSubstring
 				continue
+			case '\\': // String Terminator.
+				state = ansiText
+				continue
 			default: // Ignore.
 				state = ansiText
 			}
 		case ansiSubstring:
-			if runeValue == 0x1b {
+			switch runeValue {
+			case 0x1b: // The beginning of String Terminator.
 				state = ansiEscape
-				continue
+			case 0x07: // BEL also terminates (e.g. the window title of ConPTY).
+				state = ansiText
 			}
+			// The substrings are not displayed.
+			continue
 		case ansiControlSequence:
 			if runeValue == 'm' {
 				style = csToStyle(style, csiParameter)
+			} else if cursor >= 0 && strings.ContainsRune("CDGKXP", runeValue) {
+				lc, cursor = csiCursor(lc, cursor, runeValue, csiParameter.String())
+				tabX = cursor
 			} else if runeValue >= 'A' && runeValue <= 'T' {
 				// Ignore.
 			} else {
@@ -98,6 +138,14 @@ func parseString(line string, tabWidth int) lineContents {
 			continue
 		case '\n':
 			continue
+		case '\r':
+			if cursor >= 0 {
+				cursor = 0
+				tabX = 0
+			}
+			// A carriage return is not displayed,
+			// such as the stray one left by CRLF or CRCRLF newlines.
+			continue
 		}
 
 		switch runewidth.RuneWidth(runeValue) {
@@ -110,25 +158,30 @@ func parseString(line string, tabWidth int) lineContents {
 					c.width = 1
 					c.style = style
 					c.mainc = rune('\t')
-					lc = append(lc, c)
+					put(c)
 					tabX++
 					c.mainc = 0
 					for i := 0; i < tabStop-1; i++ {
-						lc = append(lc, c)
+						put(c)
 						tabX++
 					}
 				case tabWidth < 0:
 					c.width = 1
 					c.style = style.Reverse(true)
 					c.mainc = rune('\\')
-					lc = append(lc, c)
+					put(c)
 					c.mainc = rune('t')
-					lc = append(lc, c)
+					put(c)
 					tabX += 2
 				default:
 				}
 				continue
 			case '\b': // BackSpace
+				if cursor >= 0 {
+					cursor = max(cursor-1, 0)
+					tabX = cursor
+					continue
+				}
 				if len(lc) == 0 {
 					continue
 				}
@@ -160,7 +213,7 @@ func parseString(line string, tabWidth int) lineContents {
 				bsFlag = false
 				bsContent = DefaultContent
 			}
-			lc = append(lc, c)
+			put(c)
 			tabX++
 		case 2:
 			c.mainc = runeValue
@@ -174,13 +227,77 @@ func parseString(line string, tabWidth int) lineContents {
 				bsFlag = false
 				bsContent = DefaultContent
 			}
-			lc = append(lc, c, DefaultContent)
+			put(c, DefaultContent)
 			tabX += 2
 		}
 	}
 	return lc
 }
 
+// sgrSequence is a regular expression that matches the SGR escape sequences.
+var sgrSequence = regexp.MustCompile("\x1b\\[[\\d;]*m")
+
+// collapseCR returns the last state of the line rewritten by carriage returns,
+// such as a progress bar or a spinner.
+// The SGR escape sequences of the rewritten part are kept to preserve the style.
+func collapseCR(line string) string {
+	str := strings.TrimRight(line, "\r")
+	n := strings.LastIndexByte(str, '\r')
+	if n < 0 {
+		return line
+	}
+	sgr := sgrSequence.FindAllString(str[:n], -1)
+	return strings.Join(sgr, "") + str[n+1:]
+}
+
+// spaceContent is a blank to fill the gap when the cursor moves beyond the end.
+var spaceContent = content{
+	mainc: ' ',
+	combc: nil,
+	width: 1,
+	style: tcell.StyleDefault,
+}
+
+// csiCursor applies the cursor movement or erase control sequence
+// and returns the contents and the cursor position.
+func csiCursor(lc lineContents, cursor int, final rune, param string) (lineContents, int) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		n = -1
+	}
+	count := max(n, 1)
+	switch final {
+	case 'C': // Cursor Forward.
+		cursor += count
+	case 'D': // Cursor Back.
+		cursor = max(cursor-count, 0)
+	case 'G': // Cursor Horizontal Absolute.
+		cursor = count - 1
+	case 'K': // Erase in Line.
+		switch n {
+		case 1:
+			for x := 0; x <= cursor && x < len(lc); x++ {
+				lc[x] = spaceContent
+			}
+		case 2:
+			lc = lc[:0]
+		default:
+			if cursor < len(lc) {
+				lc = lc[:cursor]
+			}
+		}
+	case 'X': // Erase Character.
+		for x := cursor; x < cursor+count && x < len(lc); x++ {
+			lc[x] = spaceContent
+		}
+	case 'P': // Delete Character.
+		if cursor < len(lc) {
+			lc = append(lc[:cursor], lc[min(cursor+count, len(lc)):]...)
+		}
+	}
+	return lc, cursor
+}
+
 // overstrike returns an overstrike tcell.Style.
 func overstrike(p, m rune, style tcell.Style) tcell.Style {
 	if p == m {
@@ -352,7 +469,8 @@ func strToContents(str string, tabWidth int) lineContents {
 // and byte length and contents length conversion table.
 func contentsToStr(lc lineContents) (string, map[int]int) {
 	var buff bytes.Buffer
-	byteMap := make(map[int]int)
+	buff.Grow(len(lc))
+	byteMap := make(map[int]int, len(lc)+1)
 
 	bn := 0
 	for n, c := range lc {
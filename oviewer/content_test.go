@@ -226,6 +226,30 @@ func Test_parseString2(t *testing.T) {
 				{width: 0, style: tcell.StyleDefault, mainc: 0, combc: nil},
 			},
 		},
+		{
+			name: "testStrayCR",
+			args: args{line: "ab\r", tabWidth: 4},
+			want: lineContents{
+				{width: 1, style: tcell.StyleDefault, mainc: rune('a'), combc: nil},
+				{width: 1, style: tcell.StyleDefault, mainc: rune('b'), combc: nil},
+			},
+		},
+		{
+			name: "testOSCBEL",
+			args: args{line: "\x1b]0;title\x07ab", tabWidth: 4},
+			want: lineContents{
+				{width: 1, style: tcell.StyleDefault, mainc: rune('a'), combc: nil},
+				{width: 1, style: tcell.StyleDefault, mainc: rune('b'), combc: nil},
+			},
+		},
+		{
+			name: "testOSCST",
+			args: args{line: "\x1b]0;title\x1b\\ab", tabWidth: 4},
+			want: lineContents{
+				{width: 1, style: tcell.StyleDefault, mainc: rune('a'), combc: nil},
+				{width: 1, style: tcell.StyleDefault, mainc: rune('b'), combc: nil},
+			},
+		},
 	}
 	for _, tt := range tests {
 		SetupStyle()
@@ -238,6 +262,129 @@ func Test_parseString2(t *testing.T) {
 	}
 }
 
+func Test_parseLineCursorMove(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "plain",
+			line: "abc",
+			want: "abc",
+		},
+		{
+			name: "carriageReturn",
+			line: "10%\r20%\r100%",
+			want: "100%",
+		},
+		{
+			name: "carriageReturnShort",
+			line: "abcdef\rxy",
+			want: "xycdef",
+		},
+		{
+			name: "eraseLine",
+			line: "abcdef\r\x1b[Kxy",
+			want: "xy",
+		},
+		{
+			name: "eraseLineAll",
+			line: "abcdef\x1b[2K\rxy",
+			want: "xy",
+		},
+		{
+			name: "eraseLineStart",
+			line: "abcdef\x1b[3D\x1b[1K",
+			want: "    ef",
+		},
+		{
+			name: "cursorBack",
+			line: "abcdef\x1b[2DXY",
+			want: "abcdXY",
+		},
+		{
+			name: "cursorForward",
+			line: "ab\x1b[3Cc",
+			want: "ab   c",
+		},
+		{
+			name: "cursorAbsolute",
+			line: "abcdef\x1b[3GX",
+			want: "abXdef",
+		},
+		{
+			name: "eraseCharacter",
+			line: "abcdef\r\x1b[2X",
+			want: "  cdef",
+		},
+		{
+			name: "deleteCharacter",
+			line: "abcdef\r\x1b[2P",
+			want: "cdef",
+		},
+		{
+			name: "backSpace",
+			line: "abc\b\bX",
+			want: "aXc",
+		},
+		{
+			name: "style",
+			line: "\x1b[31mloading\r\x1b[Kdone",
+			want: "done",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := contentsToStr(parseLine(tt.line, 8, true))
+			if got != tt.want {
+				t.Errorf("parseLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_collapseCR(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "noCR",
+			line: "abc",
+			want: "abc",
+		},
+		{
+			name: "progress",
+			line: "10%\r20%\r100%",
+			want: "100%",
+		},
+		{
+			name: "trailingCR",
+			line: "50%\rdone\r",
+			want: "done",
+		},
+		{
+			name: "CRLF",
+			line: "abc\r",
+			want: "abc\r",
+		},
+		{
+			name: "keepStyle",
+			line: "\x1b[32m10%\r20%\rdone\x1b[0m",
+			want: "\x1b[32mdone\x1b[0m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := collapseCR(tt.line); got != tt.want {
+				t.Errorf("collapseCR() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_lastContent(t *testing.T) {
 	type args struct {
 		lc lineContents
@@ -0,0 +1,218 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// asyncBufferSize is the size of the buffer decompressed ahead by the async reader.
+const asyncBufferSize = 256 * 1024
+
+// bgzfHeaderSize is the size of the header of a BGZF block up to BSIZE.
+const bgzfHeaderSize = 18
+
+// errNotBGZF indicates that the block is not a BGZF block.
+var errNotBGZF = errors.New("not a BGZF block")
+
+// stopper is implemented by the readers that decompress in other goroutines.
+// stop stops the goroutines when the reading is finished.
+type stopper interface {
+	stop()
+}
+
+// stopReader stops the goroutines of the reader if it has them.
+func stopReader(r io.Reader) {
+	if s, ok := r.(stopper); ok {
+		s.stop()
+	}
+}
+
+// decompressed is the result of decompressing a block.
+type decompressed struct {
+	buf []byte
+	err error
+}
+
+// orderedReader reads the blocks decompressed in other goroutines in order.
+type orderedReader struct {
+	// results is the results of the blocks in order.
+	// Each result is sent to its channel when the block is decompressed.
+	results chan chan decompressed
+	done    chan struct{}
+	once    sync.Once
+	buf     []byte
+	err     error
+}
+
+func newOrderedReader(ahead int) *orderedReader {
+	return &orderedReader{
+		results: make(chan chan decompressed, ahead),
+		done:    make(chan struct{}),
+	}
+}
+
+// Read reads the decompressed data.
+func (r *orderedReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		ch, ok := <-r.results
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		result := <-ch
+		r.buf, r.err = result.buf, result.err
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// send adds the channel of the result of the next block,
+// and returns false if the reader has been stopped.
+func (r *orderedReader) send(ch chan decompressed) bool {
+	select {
+	case r.results <- ch:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+// sendResult adds the result of the next block.
+func (r *orderedReader) sendResult(buf []byte, err error) bool {
+	ch := make(chan decompressed, 1)
+	ch <- decompressed{buf: buf, err: err}
+	return r.send(ch)
+}
+
+func (r *orderedReader) stop() {
+	r.once.Do(func() {
+		close(r.done)
+	})
+}
+
+// newAsyncReader returns a reader that decompresses src in another goroutine,
+// so that the decompression and the splitting into lines run in parallel.
+func newAsyncReader(src io.Reader) *orderedReader {
+	r := newOrderedReader(4)
+	go func() {
+		defer close(r.results)
+		for {
+			buf := make([]byte, asyncBufferSize)
+			n, err := src.Read(buf)
+			if n == 0 && err == nil {
+				continue
+			}
+			if !r.sendResult(buf[:n], err) || err != nil {
+				return
+			}
+		}
+	}()
+	return r
+}
+
+// isBGZF returns true if the header is the header of a BGZF block,
+// the gzip member with the size of the block in the extra field (e.g. by bgzip).
+func isBGZF(header []byte) bool {
+	if len(header) < bgzfHeaderSize {
+		return false
+	}
+	return header[0] == 0x1f && header[1] == 0x8b && header[2] == 8 &&
+		header[3]&4 != 0 &&
+		binary.LittleEndian.Uint16(header[10:12]) == 6 &&
+		header[12] == 'B' && header[13] == 'C' &&
+		binary.LittleEndian.Uint16(header[14:16]) == 2
+}
+
+// readBGZFBlock reads a BGZF block.
+func readBGZFBlock(src *bufio.Reader) ([]byte, error) {
+	header, err := src.Peek(bgzfHeaderSize)
+	if len(header) == 0 && errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	}
+	if !isBGZF(header) {
+		return nil, errNotBGZF
+	}
+	block := make([]byte, int(binary.LittleEndian.Uint16(header[16:18]))+1)
+	if _, err := io.ReadFull(src, block); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// inflateBlock decompresses a gzip member.
+func inflateBlock(block []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(block))
+	if err != nil {
+		return nil, err
+	}
+	zr.Multistream(false)
+	return io.ReadAll(zr)
+}
+
+// newBGZFReader returns a reader that decompresses the BGZF blocks of src
+// by the workers in parallel.
+func newBGZFReader(src *bufio.Reader, workers int) *orderedReader {
+	r := newOrderedReader(workers * 2)
+	sem := make(chan struct{}, workers)
+	go func() {
+		defer close(r.results)
+		for {
+			block, err := readBGZFBlock(src)
+			if err != nil {
+				r.sendResult(nil, err)
+				return
+			}
+			ch := make(chan decompressed, 1)
+			if !r.send(ch) {
+				return
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-r.done:
+				return
+			}
+			go func() {
+				defer func() { <-sem }()
+				buf, err := inflateBlock(block)
+				ch <- decompressed{buf: buf, err: err}
+			}()
+		}
+	}()
+	return r
+}
+
+// newGzipReader returns the reader of the gzip data.
+// The BGZF blocks are decompressed in parallel,
+// and the other gzip data is decompressed in another goroutine.
+func newGzipReader(reader io.Reader) io.Reader {
+	br := bufio.NewReader(reader)
+	if header, _ := br.Peek(bgzfHeaderSize); isBGZF(header) {
+		return newBGZFReader(br, runtime.GOMAXPROCS(0))
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		return br
+	}
+	return newAsyncReader(zr)
+}
+
+// zstdReader is the zstd decoder that is closed when the reading is finished.
+type zstdReader struct {
+	*zstd.Decoder
+}
+
+func (r zstdReader) stop() {
+	r.Decoder.Close()
+}
@@ -0,0 +1,90 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+// bgzfBlock returns a BGZF block of the data.
+func bgzfBlock(t *testing.T, data string) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	zw := gzip.NewWriter(&b)
+	zw.Header.Extra = []byte{'B', 'C', 2, 0, 0, 0}
+	if _, err := zw.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	block := b.Bytes()
+	binary.LittleEndian.PutUint16(block[16:18], uint16(len(block)-1))
+	return block
+}
+
+func Test_newGzipReader(t *testing.T) {
+	var want strings.Builder
+	var bgzf bytes.Buffer
+	for i := 0; i < 100; i++ {
+		data := strings.Repeat(fmt.Sprintf("line %d\n", i), 100)
+		want.WriteString(data)
+		bgzf.Write(bgzfBlock(t, data))
+	}
+	// The empty block of the end of the file.
+	bgzf.Write(bgzfBlock(t, ""))
+
+	var plain bytes.Buffer
+	zw := gzip.NewWriter(&plain)
+	if _, err := zw.Write([]byte(want.String())); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{name: "BGZF", data: bgzf.Bytes(), want: want.String()},
+		{name: "gzip", data: plain.Bytes(), want: want.String()},
+		{name: "broken BGZF", data: append(bgzfBlock(t, "abc"), "broken"...), want: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newGzipReader(bytes.NewReader(tt.data))
+			defer stopReader(r)
+			got, _ := io.ReadAll(r)
+			if string(got) != tt.want {
+				t.Errorf("newGzipReader() = %d bytes, want %d bytes", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func Test_orderedReader_stop(t *testing.T) {
+	var data bytes.Buffer
+	for i := 0; i < 100; i++ {
+		data.Write(bgzfBlock(t, strings.Repeat("a", 1000)))
+	}
+	r := newBGZFReader(bufioReader(data.Bytes()), 2)
+	buf := make([]byte, 10)
+	if _, err := r.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	// The goroutines stop without reading to the end.
+	r.stop()
+	for range r.results {
+	}
+}
+
+func bufioReader(b []byte) *bufio.Reader {
+	return bufio.NewReader(bytes.NewReader(b))
+}
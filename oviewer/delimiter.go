@@ -0,0 +1,84 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// delimiterSampleLines is the number of the lines sampled to guess the delimiter.
+const delimiterSampleLines = 100
+
+// delimiterCandidates is the delimiters guessed in order of priority.
+var delimiterCandidates = []string{",", "\t", ";", "|", " "}
+
+// guessDelimiter returns the delimiter that splits the lines
+// into the same number of columns most consistently.
+// The lines of whitespace alignment are guessed as " ".
+func guessDelimiter(lines []string) (string, bool) {
+	best := ""
+	bestScore := 0
+	for _, delimiter := range delimiterCandidates {
+		score := delimiterScore(lines, delimiter)
+		if score > bestScore {
+			best, bestScore = delimiter, score
+		}
+	}
+	return best, bestScore > 0
+}
+
+// delimiterScore returns the number of the lines that have
+// the most frequent number of the delimiters (more than zero).
+func delimiterScore(lines []string, delimiter string) int {
+	counts := make(map[int]int)
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		if delimiter == " " {
+			line = strings.Join(strings.Fields(line), " ")
+		}
+		counts[strings.Count(line, delimiter)]++
+	}
+	score := 0
+	for n, c := range counts {
+		if n > 0 && c > score {
+			score = c
+		}
+	}
+	// The line must be split consistently in more than half of the lines.
+	if score*2 <= len(lines) {
+		return 0
+	}
+	return score
+}
+
+// autoDelimiter guesses the delimiter of the current document
+// when the column mode is enabled without the delimiter.
+// It is guessed only once for each document.
+func (root *Root) autoDelimiter() {
+	m := root.Doc
+	if !m.ColumnMode || m.ColumnDelimiter != "" || m.delimiterGuessed || root.screenMode != Docs {
+		return
+	}
+	end := m.BufEndNum()
+	if end < delimiterSampleLines && atomic.LoadInt32(&m.eof) == 0 {
+		return
+	}
+	m.delimiterGuessed = true
+
+	lines := make([]string, 0, delimiterSampleLines)
+	for n := m.Header; n < min(end, m.Header+delimiterSampleLines); n++ {
+		lines = append(lines, plainLine(m.GetLine(n)))
+	}
+	delimiter, ok := guessDelimiter(lines)
+	if !ok {
+		root.setMessage("Delimiter could not be guessed")
+		return
+	}
+	log.Printf("%s: guess delimiter %q", m.FileName, delimiter)
+	m.ColumnDelimiter = delimiter
+	m.ClearCache()
+	root.setMessage(fmt.Sprintf("Guessed delimiter %q (%s to change)", delimiter, root.delimiterKey))
+}
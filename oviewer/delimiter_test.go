@@ -0,0 +1,57 @@
+package oviewer
+
+import "testing"
+
+func Test_guessDelimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		lines  []string
+		want   string
+		wantOK bool
+	}{
+		{
+			name:   "csv",
+			lines:  []string{"a,b,c", "1,2,3", "4,5,6"},
+			want:   ",",
+			wantOK: true,
+		},
+		{
+			name:   "tsv",
+			lines:  []string{"a\tb,x\tc", "1\t2\t3", "4\t5\t6"},
+			want:   "\t",
+			wantOK: true,
+		},
+		{
+			name:   "semicolon",
+			lines:  []string{"a;b", "1;2", "3;4"},
+			want:   ";",
+			wantOK: true,
+		},
+		{
+			name:   "pipe",
+			lines:  []string{"a | b", "1 | 2", "3 | 4"},
+			want:   "|",
+			wantOK: true,
+		},
+		{
+			name:   "whitespace",
+			lines:  []string{"PID   TTY   CMD", "1     ?     init", "200   pts/0 bash"},
+			want:   " ",
+			wantOK: true,
+		},
+		{
+			name:   "text",
+			lines:  []string{"Hello", "World"},
+			want:   "",
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := guessDelimiter(tt.lines)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("guessDelimiter() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
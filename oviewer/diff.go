@@ -0,0 +1,382 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// diffOp represents the type of a line of the diff.
+type diffOp int
+
+const (
+	diffEqual diffOp = iota
+	diffDelete
+	diffInsert
+	diffChange
+)
+
+// diffMarkers is the marker between the left and right lines, like diff -y.
+var diffMarkers = [...]string{
+	diffEqual:  "   ",
+	diffDelete: " < ",
+	diffInsert: " > ",
+	diffChange: " | ",
+}
+
+// diffRow represents a row of the side-by-side diff.
+type diffRow struct {
+	op    diffOp
+	left  string
+	right string
+}
+
+// diffMaxCost is the maximum number of edits searched for each half of the shortest edit script.
+// The parts that differ more are treated as deleted and inserted entirely,
+// so that the documents with few lines in common do not take quadratic time.
+const diffMaxCost = 1000
+
+// diffLines returns the edit script that changes a into b
+// as a list of diffEqual, diffDelete and diffInsert by Myers' algorithm.
+// The linear space variant (the middle snake) is used,
+// and the search is cut off at diffMaxCost edits.
+func diffLines(a, b []string) []diffOp {
+	return diffAppend(make([]diffOp, 0, len(a)+len(b)), a, b)
+}
+
+// diffAppend appends the edit script that changes a into b to ops.
+func diffAppend(ops []diffOp, a, b []string) []diffOp {
+	// Common prefix.
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		ops = append(ops, diffEqual)
+		a, b = a[1:], b[1:]
+	}
+	// Common suffix.
+	suffix := 0
+	for len(a) > suffix && len(b) > suffix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	a, b = a[:len(a)-suffix], b[:len(b)-suffix]
+
+	switch {
+	case len(a) == 0:
+		ops = appendOps(ops, diffInsert, len(b))
+	case len(b) == 0:
+		ops = appendOps(ops, diffDelete, len(a))
+	default:
+		x, y, u, v, ok := middleSnake(a, b)
+		if !ok {
+			ops = appendOps(ops, diffDelete, len(a))
+			ops = appendOps(ops, diffInsert, len(b))
+			break
+		}
+		ops = diffAppend(ops, a[:x], b[:y])
+		ops = appendOps(ops, diffEqual, u-x)
+		ops = diffAppend(ops, a[u:], b[v:])
+	}
+	return appendOps(ops, diffEqual, suffix)
+}
+
+// appendOps appends n ops.
+func appendOps(ops []diffOp, op diffOp, n int) []diffOp {
+	for i := 0; i < n; i++ {
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// middleSnake returns the middle snake (x, y)-(u, v) of the shortest edit script of a and b,
+// by searching from the beginning and from the end at the same time.
+// ok is false if the edit script is longer than diffMaxCost in each direction.
+// The backward search is the forward search of the reversed a and b.
+func middleSnake(a, b []string) (x, y, u, v int, ok bool) {
+	n, m := len(a), len(b)
+	delta := n - m
+	odd := delta%2 != 0
+	maxD := min((n+m+1)/2, diffMaxCost)
+	offset := maxD + 1
+	// vf is the furthest x of each diagonal k of the forward search,
+	// and vb is the furthest x of the reversed sequences on the diagonal delta-k.
+	vf := make([]int, 2*offset+1)
+	vb := make([]int, 2*offset+1)
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d; k <= d; k += 2 {
+			var fx int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				fx = vf[offset+k+1]
+			} else {
+				fx = vf[offset+k-1] + 1
+			}
+			fy := fx - k
+			sx, sy := fx, fy
+			for fx < n && fy < m && a[fx] == b[fy] {
+				fx++
+				fy++
+			}
+			vf[offset+k] = fx
+			if kr := delta - k; odd && -d < kr && kr < d && fx >= n-vb[offset+kr] {
+				return sx, sy, fx, fy, true
+			}
+		}
+		for kr := -d; kr <= d; kr += 2 {
+			var rx int
+			if kr == -d || (kr != d && vb[offset+kr-1] < vb[offset+kr+1]) {
+				rx = vb[offset+kr+1]
+			} else {
+				rx = vb[offset+kr-1] + 1
+			}
+			ry := rx - kr
+			sx, sy := rx, ry
+			for rx < n && ry < m && a[n-1-rx] == b[m-1-ry] {
+				rx++
+				ry++
+			}
+			vb[offset+kr] = rx
+			if k := delta - kr; !odd && -d <= k && k <= d && vf[offset+k] >= n-rx {
+				return n - rx, m - ry, n - sx, m - sy, true
+			}
+		}
+	}
+	return 0, 0, 0, 0, false
+}
+
+// charDiffMax is the maximum number of characters of the lines
+// that are compared character by character.
+const charDiffMax = 1000
+
+// diffSpan represents the range [start, end) of the columns changed in a line.
+type diffSpan struct {
+	start int
+	end   int
+}
+
+// charDiff returns the spans of the columns of a and b
+// that are changed between a and b by character.
+// It returns nil if the lines are too long to compare.
+func charDiff(a, b string) ([]diffSpan, []diffSpan) {
+	ar, br := []rune(a), []rune(b)
+	if len(ar) > charDiffMax || len(br) > charDiffMax {
+		return nil, nil
+	}
+	as, bs := make([]string, len(ar)), make([]string, len(br))
+	for i, r := range ar {
+		as[i] = string(r)
+	}
+	for i, r := range br {
+		bs[i] = string(r)
+	}
+
+	var aSpans, bSpans []diffSpan
+	ax, bx := 0, 0
+	i, j := 0, 0
+	for _, op := range diffLines(as, bs) {
+		switch op {
+		case diffEqual:
+			ax += runewidth.RuneWidth(ar[i])
+			bx += runewidth.RuneWidth(br[j])
+			i++
+			j++
+		case diffDelete:
+			w := runewidth.RuneWidth(ar[i])
+			aSpans = appendSpan(aSpans, ax, ax+w)
+			ax += w
+			i++
+		case diffInsert:
+			w := runewidth.RuneWidth(br[j])
+			bSpans = appendSpan(bSpans, bx, bx+w)
+			bx += w
+			j++
+		}
+	}
+	return aSpans, bSpans
+}
+
+// appendSpan appends the span [start, end) to spans, joining it to the last span if adjacent.
+func appendSpan(spans []diffSpan, start int, end int) []diffSpan {
+	if n := len(spans); n > 0 && spans[n-1].end == start {
+		spans[n-1].end = end
+		return spans
+	}
+	return append(spans, diffSpan{start: start, end: end})
+}
+
+// spanStyle applies the style to the spans of lc shifted by offset.
+func spanStyle(lc lineContents, spans []diffSpan, offset int, style ovStyle) {
+	for _, s := range spans {
+		RangeStyle(lc, min(offset+s.start, len(lc)), min(offset+s.end, len(lc)), style)
+	}
+}
+
+// diffRows returns the rows of the side-by-side diff of a and b.
+// The deleted and inserted lines between the same lines are paired as changes.
+func diffRows(a, b []string) []diffRow {
+	ops := diffLines(a, b)
+	rows := make([]diffRow, 0, len(ops))
+	var dels, ins []string
+	flush := func() {
+		for i := 0; i < len(dels) || i < len(ins); i++ {
+			switch {
+			case i < len(dels) && i < len(ins):
+				rows = append(rows, diffRow{op: diffChange, left: dels[i], right: ins[i]})
+			case i < len(dels):
+				rows = append(rows, diffRow{op: diffDelete, left: dels[i]})
+			default:
+				rows = append(rows, diffRow{op: diffInsert, right: ins[i]})
+			}
+		}
+		dels, ins = dels[:0], ins[:0]
+	}
+
+	x, y := 0, 0
+	for _, op := range ops {
+		switch op {
+		case diffEqual:
+			flush()
+			rows = append(rows, diffRow{op: diffEqual, left: a[x], right: b[y]})
+			x++
+			y++
+		case diffDelete:
+			dels = append(dels, a[x])
+			x++
+		case diffInsert:
+			ins = append(ins, b[y])
+			y++
+		}
+	}
+	flush()
+	return rows
+}
+
+// diffText returns the lines of the document for the diff,
+// without escape sequences and with the tabs expanded.
+func diffText(m *Document) []string {
+	end := m.BufEndNum()
+	lines := make([]string, 0, end)
+	for n := 0; n < end; n++ {
+		line := m.GetLine(n)
+		if strings.ContainsAny(line, "\x1b\b") {
+			line = stripEscapeSequence.ReplaceAllString(line, "")
+		}
+		lines = append(lines, expandTabs(line, m.TabWidth))
+	}
+	return lines
+}
+
+// expandTabs replaces the tabs with spaces up to the next tab stop.
+func expandTabs(str string, tabWidth int) string {
+	if !strings.Contains(str, "\t") || tabWidth <= 0 {
+		return str
+	}
+	var b strings.Builder
+	x := 0
+	for _, r := range str {
+		if r == '\t' {
+			w := tabWidth - x%tabWidth
+			b.WriteString(strings.Repeat(" ", w))
+			x += w
+			continue
+		}
+		b.WriteRune(r)
+		x += runewidth.RuneWidth(r)
+	}
+	return b.String()
+}
+
+// diffDocument returns a new document that shows the diff of a and b side by side.
+func diffDocument(a, b *Document) (*Document, error) {
+	rows := diffRows(diffText(a), diffText(b))
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, runewidth.StringWidth(row.left))
+	}
+
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	ops := make([]diffOp, 0, len(rows))
+	spans := make(map[int][2][]diffSpan)
+	for n, row := range rows {
+		left := row.left + strings.Repeat(" ", width-runewidth.StringWidth(row.left))
+		m.append(strings.TrimRight(left+diffMarkers[row.op]+row.right, " "))
+		ops = append(ops, row.op)
+		if row.op == diffChange {
+			l, r := charDiff(row.left, row.right)
+			spans[n] = [2][]diffSpan{l, r}
+		}
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	m.diffOps = ops
+	m.diffSpans = spans
+	m.diffWidth = width
+	return m, nil
+}
+
+// diffDocuments adds a document of the diff of the previous document and the current document.
+func (root *Root) diffDocuments() {
+	root.mu.RLock()
+	if len(root.DocList) < 2 {
+		root.mu.RUnlock()
+		root.setMessage("Diff requires two documents")
+		return
+	}
+	prev := root.CurrentDoc - 1
+	if prev < 0 {
+		prev = 1
+	}
+	a, b := root.DocList[prev], root.Doc
+	if prev > root.CurrentDoc {
+		a, b = b, a
+	}
+	root.mu.RUnlock()
+
+	m, err := diffDocument(a, b)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	changes := 0
+	for _, op := range m.diffOps {
+		if op != diffEqual {
+			changes++
+		}
+	}
+
+	src := root.Doc
+	m.FileName = fmt.Sprintf("diff:%s:%s", a.FileName, b.FileName)
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	m.Header = 0
+	m.WrapMode = false
+	m.ColumnMode = false
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("diff %s %s: %d changed lines", a.FileName, b.FileName, changes))
+}
+
+// diffStyle applies the style of the change to the sides of the diff line.
+func (root *Root) diffStyle(lc lineContents, lN int) {
+	m := root.Doc
+	if lN < 0 || lN >= len(m.diffOps) {
+		return
+	}
+	right := m.diffWidth + len(diffMarkers[diffEqual])
+	switch m.diffOps[lN] {
+	case diffDelete:
+		RangeStyle(lc, 0, min(m.diffWidth, len(lc)), root.StyleDiffDelete)
+	case diffInsert:
+		RangeStyle(lc, min(right, len(lc)), len(lc), root.StyleDiffAdd)
+	case diffChange:
+		RangeStyle(lc, 0, min(m.diffWidth, len(lc)), root.StyleDiffChange)
+		RangeStyle(lc, min(right, len(lc)), len(lc), root.StyleDiffChange)
+		spans := m.diffSpans[lN]
+		spanStyle(lc, spans[0], 0, root.StyleDiffChar)
+		spanStyle(lc, spans[1], right, root.StyleDiffChar)
+	}
+}
@@ -0,0 +1,232 @@
+package oviewer
+
+import (
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func Test_diffRows(t *testing.T) {
+	type args struct {
+		a []string
+		b []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []diffRow
+	}{
+		{
+			name: "same",
+			args: args{a: []string{"a", "b"}, b: []string{"a", "b"}},
+			want: []diffRow{
+				{op: diffEqual, left: "a", right: "a"},
+				{op: diffEqual, left: "b", right: "b"},
+			},
+		},
+		{
+			name: "empty",
+			args: args{a: nil, b: []string{"a"}},
+			want: []diffRow{
+				{op: diffInsert, right: "a"},
+			},
+		},
+		{
+			name: "insertDelete",
+			args: args{a: []string{"a", "b", "c"}, b: []string{"a", "c", "d"}},
+			want: []diffRow{
+				{op: diffEqual, left: "a", right: "a"},
+				{op: diffDelete, left: "b"},
+				{op: diffEqual, left: "c", right: "c"},
+				{op: diffInsert, right: "d"},
+			},
+		},
+		{
+			name: "change",
+			args: args{a: []string{"a", "x=1", "y=2", "z"}, b: []string{"a", "x=3", "z"}},
+			want: []diffRow{
+				{op: diffEqual, left: "a", right: "a"},
+				{op: diffChange, left: "x=1", right: "x=3"},
+				{op: diffDelete, left: "y=2"},
+				{op: diffEqual, left: "z", right: "z"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffRows(tt.args.a, tt.args.b); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// applyDiff applies the edit script to a, and returns the result and the number of equal lines.
+func applyDiff(t *testing.T, a, b []string, ops []diffOp) ([]string, int) {
+	t.Helper()
+	var got []string
+	i, j, equal := 0, 0, 0
+	for _, op := range ops {
+		switch op {
+		case diffEqual:
+			if a[i] != b[j] {
+				t.Fatalf("equal op for %q and %q", a[i], b[j])
+			}
+			got = append(got, a[i])
+			i, j, equal = i+1, j+1, equal+1
+		case diffDelete:
+			i++
+		case diffInsert:
+			got = append(got, b[j])
+			j++
+		}
+	}
+	if i != len(a) || j != len(b) {
+		t.Fatalf("the edit script ends at %d, %d, want %d, %d", i, j, len(a), len(b))
+	}
+	return got, equal
+}
+
+// lcsLength returns the length of the longest common subsequence of a and b.
+func lcsLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				cur[j+1] = prev[j] + 1
+			} else {
+				cur[j+1] = max(prev[j+1], cur[j])
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func Test_diffLines(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(30))
+		for i := range lines {
+			lines[i] = strconv.Itoa(rnd.Intn(4))
+		}
+		return lines
+	}
+	for n := 0; n < 500; n++ {
+		a, b := randomLines(), randomLines()
+		got, equal := applyDiff(t, a, b, diffLines(a, b))
+		if !reflect.DeepEqual(got, b) && len(b) > 0 {
+			t.Fatalf("diffLines(%v, %v) makes %v", a, b, got)
+		}
+		if want := lcsLength(a, b); equal != want {
+			t.Fatalf("diffLines(%v, %v) has %d equal lines, want %d", a, b, equal, want)
+		}
+	}
+}
+
+func Test_diffLinesNothingInCommon(t *testing.T) {
+	a := make([]string, 100000)
+	b := make([]string, 100000)
+	for i := range a {
+		a[i] = "a" + strconv.Itoa(i)
+		b[i] = "b" + strconv.Itoa(i)
+	}
+	b[50000] = a[50000]
+	start := time.Now()
+	ops := diffLines(a, b)
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("diffLines() took %v", d)
+	}
+	applyDiff(t, a, b, ops)
+}
+
+func Test_diffDocument(t *testing.T) {
+	a := testLineDocument(t, 0, "key=1", "same")
+	b := testLineDocument(t, 0, "key=22", "same", "new")
+	m, err := diffDocument(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0)
+	for n := 0; n < m.BufEndNum(); n++ {
+		got = append(got, m.GetLine(n))
+	}
+	want := []string{
+		"key=1 | key=22",
+		"same    same",
+		"      > new",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diffDocument() = %q, want %q", got, want)
+	}
+}
+
+func Test_expandTabs(t *testing.T) {
+	tests := []struct {
+		name     string
+		str      string
+		tabWidth int
+		want     string
+	}{
+		{name: "noTab", str: "abc", tabWidth: 4, want: "abc"},
+		{name: "tab", str: "a\tb", tabWidth: 4, want: "a   b"},
+		{name: "wide", str: "あ\tb", tabWidth: 4, want: "あ  b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandTabs(tt.str, tt.tabWidth); got != tt.want {
+				t.Errorf("expandTabs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_charDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		a         string
+		b         string
+		wantLeft  []diffSpan
+		wantRight []diffSpan
+	}{
+		{
+			name:      "same",
+			a:         "port = 80",
+			b:         "port = 80",
+			wantLeft:  nil,
+			wantRight: nil,
+		},
+		{
+			name:      "oneChar",
+			a:         "port = 80",
+			b:         "port = 81",
+			wantLeft:  []diffSpan{{start: 8, end: 9}},
+			wantRight: []diffSpan{{start: 8, end: 9}},
+		},
+		{
+			name:      "insert",
+			a:         "abc",
+			b:         "abxyc",
+			wantLeft:  nil,
+			wantRight: []diffSpan{{start: 2, end: 4}},
+		},
+		{
+			name:      "wide",
+			a:         "あいう",
+			b:         "あえう",
+			wantLeft:  []diffSpan{{start: 2, end: 4}},
+			wantRight: []diffSpan{{start: 2, end: 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right := charDiff(tt.a, tt.b)
+			if !reflect.DeepEqual(left, tt.wantLeft) || !reflect.DeepEqual(right, tt.wantRight) {
+				t.Errorf("charDiff() = %v, %v, want %v, %v", left, right, tt.wantLeft, tt.wantRight)
+			}
+		})
+	}
+}
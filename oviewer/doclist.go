@@ -0,0 +1,136 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Title returns the name of the document to display.
+// It is Caption if set, otherwise FileName.
+func (m *Document) Title() string {
+	if m.Caption != "" {
+		return m.Caption
+	}
+	return m.FileName
+}
+
+// renameInput represents the rename input mode.
+type renameInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newRenameInput returns renameInput.
+func newRenameInput(clist *candidate) *renameInput {
+	return &renameInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (r *renameInput) Prompt() string {
+	return "Rename:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (r *renameInput) Confirm(str string) tcell.Event {
+	r.value = str
+	r.clist.list = toLast(r.clist.list, str)
+	r.clist.p = 0
+	r.SetEventNow()
+	return r
+}
+
+// Up returns strings when the up key is pressed during input.
+func (r *renameInput) Up(str string) string {
+	return r.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (r *renameInput) Down(str string) string {
+	return r.clist.match(str).down()
+}
+
+// setRenameMode sets the rename input mode.
+func (root *Root) setRenameMode() {
+	input := root.input
+	input.value = root.Doc.Caption
+	input.cursorX = runeWidth(input.value)
+	input.mode = Rename
+	input.EventInput = newRenameInput(input.RenameCandidate)
+}
+
+// rename sets the caption of the current document.
+// An empty string restores FileName.
+func (root *Root) rename(str string) {
+	root.Doc.Caption = str
+	root.setMessage(fmt.Sprintf("Rename to %s", root.Doc.Title()))
+}
+
+// RenameDocument sets the caption of the document of docNum.
+func (root *Root) RenameDocument(docNum int, caption string) {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	if docNum < 0 || docNum >= len(root.DocList) {
+		return
+	}
+	root.DocList[docNum].Caption = caption
+}
+
+// moveDocument moves the document of from to the position of to in the document list.
+func (root *Root) moveDocument(from int, to int) {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.DocList = moveDocList(root.DocList, from, to)
+	for n, doc := range root.DocList {
+		if doc == root.Doc {
+			root.CurrentDoc = n
+		}
+	}
+}
+
+// moveDocList returns the list with the element of from moved to the position of to.
+func moveDocList(list []*Document, from int, to int) []*Document {
+	if from < 0 || from >= len(list) || to < 0 || to >= len(list) || from == to {
+		return list
+	}
+	m := list[from]
+	list = append(list[:from], list[from+1:]...)
+	list = append(list[:to], append([]*Document{m}, list[to:]...)...)
+	return list
+}
+
+// moveDocLeft moves the current document to the left in the document list.
+func (root *Root) moveDocLeft() {
+	root.moveDocument(root.CurrentDoc, root.CurrentDoc-1)
+	root.setMessage(fmt.Sprintf("Move document to %d", root.CurrentDoc))
+}
+
+// moveDocRight moves the current document to the right in the document list.
+func (root *Root) moveDocRight() {
+	root.moveDocument(root.CurrentDoc, root.CurrentDoc+1)
+	root.setMessage(fmt.Sprintf("Move document to %d", root.CurrentDoc))
+}
+
+// sortDocuments sorts the document list by the title.
+func (root *Root) sortDocuments() {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	sortDocList(root.DocList)
+	for n, doc := range root.DocList {
+		if doc == root.Doc {
+			root.CurrentDoc = n
+		}
+	}
+	log.Printf("sort documents")
+	root.setMessage("Sort documents by title")
+}
+
+// sortDocList sorts the list by the title.
+func sortDocList(list []*Document) {
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Title() < list[j].Title()
+	})
+}
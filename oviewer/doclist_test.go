@@ -0,0 +1,120 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func testDocList(t *testing.T, names ...string) []*Document {
+	t.Helper()
+	list := make([]*Document, 0, len(names))
+	for _, name := range names {
+		m, err := NewDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.FileName = name
+		list = append(list, m)
+	}
+	return list
+}
+
+func docTitles(list []*Document) []string {
+	titles := make([]string, 0, len(list))
+	for _, m := range list {
+		titles = append(titles, m.Title())
+	}
+	return titles
+}
+
+func Test_moveDocList(t *testing.T) {
+	tests := []struct {
+		name string
+		from int
+		to   int
+		want []string
+	}{
+		{name: "right", from: 0, to: 1, want: []string{"b", "a", "c"}},
+		{name: "left", from: 2, to: 1, want: []string{"a", "c", "b"}},
+		{name: "last", from: 0, to: 2, want: []string{"b", "c", "a"}},
+		{name: "outOfRange", from: 0, to: -1, want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			list := testDocList(t, "a", "b", "c")
+			if got := docTitles(moveDocList(list, tt.from, tt.to)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("moveDocList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sortDocList(t *testing.T) {
+	list := testDocList(t, "pipe:wc", "b.txt", "filter:x")
+	list[0].Caption = "a"
+	sortDocList(list)
+	want := []string{"a", "b.txt", "filter:x"}
+	if got := docTitles(list); !reflect.DeepEqual(got, want) {
+		t.Errorf("sortDocList() = %v, want %v", got, want)
+	}
+}
+
+func TestRoot_closeDocuments(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name    string
+		current int
+		derived []bool
+		want    []string
+		wantCur string
+	}{
+		{
+			name:    "derived",
+			current: 2,
+			derived: []bool{false, true, true, false},
+			want:    []string{"a", "d"},
+			wantCur: "a",
+		},
+		{
+			name:    "keepCurrent",
+			current: 3,
+			derived: []bool{false, true, true, false},
+			want:    []string{"a", "d"},
+			wantCur: "d",
+		},
+		{
+			name:    "all",
+			current: 0,
+			derived: []bool{true, true, true, true},
+			want:    []string{"a"},
+			wantCur: "a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := testDocList(t, "a", "b", "c", "d")
+			for n, d := range tt.derived {
+				docs[n].derived = d
+			}
+			root, err := NewOviewer(docs...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.setDocumentNum(tt.current)
+			root.closeDocuments(func(m *Document) bool {
+				return m.derived
+			})
+			if got := docTitles(root.DocList); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Root.closeDocuments() = %v, want %v", got, tt.want)
+			}
+			if got := root.Doc.Title(); got != tt.wantCur {
+				t.Errorf("Root.closeDocuments() current = %v, want %v", got, tt.wantCur)
+			}
+		})
+	}
+}
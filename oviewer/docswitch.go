@@ -0,0 +1,182 @@
+package oviewer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// docPreviewLen is the maximum length of the preview of the document in the hint.
+const docPreviewLen = 60
+
+// docSwitchInput represents the document switch input mode.
+type docSwitchInput struct {
+	value string
+	clist *candidate
+	// matches is the candidates filtered by the input.
+	matches *candidate
+	// preview returns the top lines of the document of the candidate.
+	preview func(string) string
+	tcell.EventTime
+}
+
+// newDocSwitchInput returns docSwitchInput.
+func newDocSwitchInput(list []string, preview func(string) string) *docSwitchInput {
+	return &docSwitchInput{clist: &candidate{list: list}, preview: preview}
+}
+
+// Prompt returns the prompt string in the input field.
+func (d *docSwitchInput) Prompt() string {
+	return "Document:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (d *docSwitchInput) Confirm(str string) tcell.Event {
+	d.value = str
+	if matches := fuzzyFilter(d.clist.list, str); str != "" && len(matches) > 0 && !d.isCandidate(str) {
+		d.value = matches[0]
+	}
+	d.SetEventNow()
+	return d
+}
+
+// Up returns strings when the up key is pressed during input.
+func (d *docSwitchInput) Up(str string) string {
+	return d.filter(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (d *docSwitchInput) Down(str string) string {
+	return d.filter(str).down()
+}
+
+// Complete returns the next candidate that matches the input.
+func (d *docSwitchInput) Complete(str string) (string, string) {
+	c := d.filter(str)
+	if len(c.list) == 0 {
+		return str, "no match"
+	}
+	s := c.down()
+	return s, d.Hint(s)
+}
+
+// Hint returns the number of the matches and the preview of the best match.
+func (d *docSwitchInput) Hint(str string) string {
+	matches := fuzzyFilter(d.clist.list, str)
+	if d.isCandidate(str) {
+		matches = []string{str}
+	}
+	if len(matches) == 0 {
+		return "no match"
+	}
+	return fmt.Sprintf("[%d] %s | %s", len(matches), matches[0], d.preview(matches[0]))
+}
+
+// isCandidate returns true if str is one of the candidates.
+func (d *docSwitchInput) isCandidate(str string) bool {
+	for _, s := range d.clist.list {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
+
+// filter returns the candidates filtered by the input with fuzzy matching.
+// If the input is one of the filtered candidates, it keeps cycling through them.
+func (d *docSwitchInput) filter(str string) *candidate {
+	if d.matches != nil {
+		for _, s := range d.matches.list {
+			if s == str {
+				return d.matches
+			}
+		}
+	}
+	d.matches = &candidate{list: fuzzyFilter(d.clist.list, str), p: -1}
+	return d.matches
+}
+
+// docCandidates returns the candidates of the document switch as "number:title".
+func (root *Root) docCandidates() []string {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	list := make([]string, 0, len(root.DocList))
+	for n, doc := range root.DocList {
+		list = append(list, fmt.Sprintf("%d:%s", n, doc.Title()))
+	}
+	return list
+}
+
+// docCandidateNum returns the document number of the candidate.
+func docCandidateNum(str string) (int, bool) {
+	i := strings.IndexByte(str, ':')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(str[:i])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// docPreview returns the first lines of the document of the candidate.
+func (root *Root) docPreview(str string) string {
+	n, ok := docCandidateNum(str)
+	if !ok {
+		return ""
+	}
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	if n < 0 || n >= len(root.DocList) {
+		return ""
+	}
+	return documentPreview(root.DocList[n], docPreviewLen)
+}
+
+// documentPreview returns the first non-empty lines of the document
+// joined with " / " up to width runes.
+func documentPreview(m *Document, width int) string {
+	var b strings.Builder
+	for n := 0; n < m.BufEndNum() && b.Len() < width*4; n++ {
+		line := strings.TrimSpace(plainLine(m.GetLine(n)))
+		if line == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(" / ")
+		}
+		b.WriteString(line)
+	}
+	runes := []rune(b.String())
+	if len(runes) > width {
+		return string(runes[:width-1]) + "…"
+	}
+	return string(runes)
+}
+
+// setDocSwitchMode sets the document switch input mode.
+func (root *Root) setDocSwitchMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = DocSwitch
+	d := newDocSwitchInput(root.docCandidates(), root.docPreview)
+	input.EventInput = d
+	input.hint = d.Hint("")
+}
+
+// docSwitch switches to the document of the input.
+func (root *Root) docSwitch(str string) {
+	if str == "" {
+		return
+	}
+	n, ok := docCandidateNum(str)
+	if !ok {
+		root.setMessage(fmt.Sprintf("no document: %s", str))
+		return
+	}
+	root.switchDocument(n)
+}
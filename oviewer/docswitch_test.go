@@ -0,0 +1,64 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_docSwitchInput_Confirm(t *testing.T) {
+	list := []string{"0:README.md", "1:filter:error:README.md", "2:pipe:wc -l"}
+	tests := []struct {
+		name string
+		str  string
+		want string
+	}{
+		{name: "candidate", str: "1:filter:error:README.md", want: "1:filter:error:README.md"},
+		{name: "fuzzy", str: "wc", want: "2:pipe:wc -l"},
+		{name: "prefix", str: "0:", want: "0:README.md"},
+		{name: "noMatch", str: "zzz", want: "zzz"},
+		{name: "empty", str: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newDocSwitchInput(list, func(string) string { return "" })
+			d.Confirm(tt.str)
+			if d.value != tt.want {
+				t.Errorf("docSwitchInput.Confirm() = %v, want %v", d.value, tt.want)
+			}
+		})
+	}
+}
+
+func Test_docCandidateNum(t *testing.T) {
+	tests := []struct {
+		str    string
+		want   int
+		wantOK bool
+	}{
+		{str: "2:pipe:wc", want: 2, wantOK: true},
+		{str: "README.md", want: 0, wantOK: false},
+		{str: "a:b", want: 0, wantOK: false},
+	}
+	for _, tt := range tests {
+		got, ok := docCandidateNum(tt.str)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("docCandidateNum(%q) = %v, %v, want %v, %v", tt.str, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func Test_documentPreview(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ReadAll(strings.NewReader("first\n\nsecond\n" + strings.Repeat("x", 100) + "\n")); err != nil {
+		t.Fatal(err)
+	}
+	<-m.eofCh
+	got := documentPreview(m, 20)
+	want := "first / second / xx…"
+	if got != want {
+		t.Errorf("documentPreview() = %q, want %q", got, want)
+	}
+}
@@ -1,10 +1,14 @@
 package oviewer
 
 import (
+	"fmt"
+	"io"
 	"log"
 	"os"
+	"regexp"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/dgraph-io/ristretto"
 )
@@ -14,6 +18,8 @@ import (
 type Document struct {
 	// fileName is the file name to display.
 	FileName string
+	// Caption is the title to display instead of FileName.
+	Caption string
 
 	// File is the os.File.
 	file *os.File
@@ -21,12 +27,52 @@ type Document struct {
 	offset int64
 	// CFormat is a compressed format.
 	CFormat Compressed
+	// encoding is the encoding detected by the BOM.
+	encoding string
+	// newline is the newline style (LF or CRLF) of the first line.
+	newline string
 
 	// lines stores the contents of the file in slices of strings.
 	// lines,endNum and eof is updated by reader goroutine.
 	lines []string
+	// chunk is the chunk buffer that the strings of the lines read refer to.
+	// It is only used by the reader goroutine.
+	chunk []byte
+	// chunkSize is the size of the chunk buffers (0 is the default).
+	chunkSize int32
 	// endNum is the number of the last line read.
 	endNum int
+	// size is the total size in bytes of the lines.
+	size int64
+	// trimmed is the number of the lines dropped from the buffer.
+	trimmed int
+	// readBufferSize is the size of the read buffer, or 0 for the default size.
+	readBufferSize int
+	// backpressure is the strategy when the lines arrive faster than they are displayed.
+	backpressure int32
+	// backpressureLimit is the number of the lines that can be appended between the updates.
+	backpressureLimit int32
+	// pending is the number of the lines appended since the last update.
+	pending int32
+	// fileRead is 1 while the lines of regular files are read.
+	// The backpressure applies only to the streams, such as stdin and the followed files.
+	fileRead int32
+	// dropped is the number of the lines dropped by the backpressure since the last marker.
+	dropped int
+	// observer receives the measurements of reading.
+	observer Observer
+	// segmentSize is the maximum length of a line.
+	// Longer lines are split into segments of this size.
+	segmentSize int
+	// continued is the line numbers of the segments that continue to the next line,
+	// in ascending order. The segments of a long line are the lines of the buffer,
+	// and are counted as one line in the line numbers.
+	continued []int
+	// arrival is the time when each line was appended in follow mode.
+	// It is only displayed and is not part of the contents.
+	arrival map[int]time.Time
+	// 1 if the arrival time is recorded.
+	recordArrival int32
 
 	// 1 if EOF is reached.
 	eof int32
@@ -39,17 +85,38 @@ type Document struct {
 
 	// 1 if there is a changed.
 	changed int32
+	// appended is the number of the lines appended since the last update event.
+	appended int
 	// notify when a file changes.
 	changCh chan struct{}
 	// notify close document.
 	closeCh chan struct{}
+	// closeOnce closes closeCh only once.
+	closeOnce sync.Once
+	// tableChecked is true if the table has been detected by AutoTable.
+	tableChecked bool
+	// modelineChecked is true if the modeline has been searched for.
+	modelineChecked bool
+	// delimiterGuessed is true if the delimiter has been guessed.
+	delimiterGuessed bool
+	// derived is true if the document is made from other documents
+	// (filter, sort, pipe, etc.).
+	derived bool
+	// filtering is 1 while the lines are filtered into the document.
+	filtering int32
 
 	// cache represents a cache of contents.
 	cache *ristretto.Cache
+	// cacheMu protects cacheGen.
+	cacheMu sync.Mutex
+	// cacheGen is incremented when the cache is cleared,
+	// so that the contents prepared in the background with the old options are not cached.
+	cacheGen int
 
-	lastContentsNum int
-	lastContentsStr string
-	lastContentsMap map[int]int
+	// drawLines is the cache of the displayed lines (see beginLines).
+	drawLines map[int]*lineCache
+	// linesGen is cacheGen when the lines were last drawn.
+	linesGen int
 
 	// status is the display status of the document.
 	general
@@ -65,18 +132,73 @@ type Document struct {
 	// columnNum is the number of columns.
 	columnNum int
 
+	// jsonSource is the source document of the JSON fields document.
+	jsonSource *Document
+	// jsonSourceLN is the line number of the raw line in jsonSource for each line.
+	jsonSourceLN []int
+
+	// diffOps is the change of each line of the diff document.
+	diffOps []diffOp
+	// diffWidth is the width of the left side of the diff document.
+	diffWidth int
+	// diffSpans is the changed columns of the left and right sides of the changed lines.
+	diffSpans map[int][2][]diffSpan
+
+	// concatFiles is the list of the files of the concatenated document.
+	concatFiles []concatFile
+
+	// mergeSource is the index of the source document for each line of the merged document.
+	mergeSource []int
+
+	// selected is the set of the line numbers selected to be written on exit.
+	selected map[int]bool
+
+	// fileInfo is the information of the file when it was opened.
+	fileInfo os.FileInfo
+	// diskChanged is the change of the file on disk detected while viewing.
+	diskChanged string
+
+	// watchChanged is the lines changed from the previous run of the watch command,
+	// mapped to the lines of the previous run.
+	watchChanged map[int]string
+
+	// jumpLN is the line moved to by jumpLine, or -1.
+	jumpLN int
+	// jumpTopLN is topLN after jumpLine.
+	jumpTopLN int
+
+	// substitution is applied to the display and the export of the lines in the replace mode.
+	substitution *substitution
+
+	// sectionReg is the compiled SectionDelimiter.
+	sectionReg *regexp.Regexp
+	// sectionRegStr is the SectionDelimiter of sectionReg.
+	sectionRegStr string
+	// sectionCacheLN is the line of the last sectionStart, or -1 if none.
+	sectionCacheLN int
+	// sectionCacheStart is the result of the last sectionStart.
+	sectionCacheStart int
+	// sectionCacheHeader is the Header of the last sectionStart.
+	sectionCacheHeader int
+
 	// mu controls the mutex.
 	mu sync.Mutex
 }
 
+// defaultSegmentSize is the default maximum length of a line.
+const defaultSegmentSize = 1 << 20
+
 // NewDocument returns Document.
 func NewDocument() (*Document, error) {
 	m := &Document{
-		lines:    make([]string, 0),
-		eofCh:    make(chan struct{}),
-		reOpenCh: make(chan struct{}),
-		changCh:  make(chan struct{}),
-		closeCh:  make(chan struct{}),
+		lines:          make([]string, 0),
+		eofCh:          make(chan struct{}),
+		reOpenCh:       make(chan struct{}),
+		changCh:        make(chan struct{}),
+		closeCh:        make(chan struct{}),
+		segmentSize:    defaultSegmentSize,
+		jumpLN:         -1,
+		sectionCacheLN: -1,
 		general: general{
 			ColumnDelimiter: "",
 			TabWidth:        8,
@@ -106,6 +228,32 @@ func (m *Document) BufEndNum() int {
 	return m.endNum
 }
 
+// Export exports the buffer of the document to the writer.
+func (m *Document) Export(w io.Writer) error {
+	m.mu.Lock()
+	lines := m.lines[:m.endNum]
+	continued := m.continued
+	m.mu.Unlock()
+
+	for n, line := range lines {
+		// The segments of a long line are joined.
+		if len(continued) > 0 && continued[0] == n {
+			continued = continued[1:]
+			if _, err := io.WriteString(w, line); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.substitution != nil {
+			line = m.substitution.replaceString(line)
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BufEOF return true if EOF is reached.
 func (m *Document) BufEOF() bool {
 	return atomic.LoadInt32(&m.eof) == 1
@@ -127,9 +275,20 @@ func (m *Document) NewCache() error {
 
 // ClearCache clears the cache.
 func (m *Document) ClearCache() {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	m.cacheGen++
 	m.cache.Clear()
 }
 
+// arrivalTime returns the time when the line was appended in follow mode.
+func (m *Document) arrivalTime(n int) (time.Time, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.arrival[n]
+	return t, ok
+}
+
 // lineToContents returns contents from line number.
 func (m *Document) lineToContents(lN int, tabWidth int) (lineContents, error) {
 	if lN < 0 || lN >= m.BufEndNum() {
@@ -145,12 +304,68 @@ func (m *Document) lineToContents(lN int, tabWidth int) (lineContents, error) {
 		return lc, nil
 	}
 
-	lc := parseString(m.GetLine(lN), tabWidth)
-
+	lc := m.contentsOption(tabWidth).parse(m.GetLine(lN))
 	m.cache.Set(lN, lc, 1)
 	return lc, nil
 }
 
+// contentsOption is the options of converting a line to the contents.
+type contentsOption struct {
+	tabWidth     int
+	collapseCR   bool
+	cursorMove   bool
+	substitution *substitution
+	// cacheGen is the generation of the cache when the options are taken.
+	cacheGen int
+}
+
+// contentsOption returns the current options of converting a line to the contents.
+func (m *Document) contentsOption(tabWidth int) contentsOption {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	return contentsOption{
+		tabWidth:     tabWidth,
+		collapseCR:   m.CollapseCR,
+		cursorMove:   m.CursorMove,
+		substitution: m.substitution,
+		cacheGen:     m.cacheGen,
+	}
+}
+
+// parse converts the line to the contents.
+func (o contentsOption) parse(str string) lineContents {
+	if o.collapseCR {
+		str = collapseCR(str)
+	}
+	lc := parseLine(str, o.tabWidth, o.cursorMove)
+	if o.substitution != nil {
+		lc = substituteContents(lc, o.substitution, o.tabWidth)
+	}
+	return lc
+}
+
+// release closes the document and releases the buffer.
+func (m *Document) release() {
+	m.stopReading()
+	m.mu.Lock()
+	m.lines = nil
+	m.endNum = 0
+	m.size = 0
+	m.continued = nil
+	m.arrival = nil
+	m.mu.Unlock()
+	m.resetSectionCache()
+	m.ClearCache()
+}
+
+// stopReading stops the reading of the document.
+// The lines read so far are kept.
+func (m *Document) stopReading() {
+	m.closeOnce.Do(func() {
+		close(m.closeCh)
+	})
+}
+
 func (m *Document) checkClose() bool {
 	select {
 	case <-m.closeCh:
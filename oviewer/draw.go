@@ -3,12 +3,16 @@ package oviewer
 import (
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 // draw is the main routine that draws the screen.
 func (root *Root) draw() {
+	defer root.observeDuration(MetricDraw, time.Now())
 	m := root.Doc
 
 	if m.BufEndNum() == 0 || root.vHight == 0 {
@@ -18,8 +22,15 @@ func (root *Root) draw() {
 		return
 	}
 
+	m.beginLines()
+	defer m.endLines()
+
+	// The section of the current position may have changed.
+	root.setWrapHeaderLen()
+
 	// Header
-	lY := root.drawHeader()
+	hy := root.drawHeader()
+	lY := m.Header
 
 	lX := 0
 	if m.WrapMode {
@@ -31,26 +42,90 @@ func (root *Root) draw() {
 	}
 
 	// Body
-	lX, lY = root.drawBody(lX, lY)
+	lX, lY = root.drawBody(hy, lX, lY)
 
 	root.bottomLN = m.topLN + max(lY, 0)
 	root.bottomLX = lX
 
+	if m.ColumnMode && m.ColumnBand && !m.WrapMode {
+		root.columnBand()
+	}
+
 	if root.mouseSelect {
 		root.drawSelect(root.x1, root.y1, root.x2, root.y2, true)
 	}
 
+	root.drawScrollBar()
 	root.statusDraw()
+	root.convertColors(0, root.statusPos)
 	root.Show()
+	root.readAhead()
+}
+
+// eventRedraw represents the redraw that was delayed by MaxFPS.
+type eventRedraw struct {
+	tcell.EventTime
+}
+
+// drawInterval returns the minimum interval of the redraw by MaxFPS, or 0 if unlimited.
+func (root *Root) drawInterval() time.Duration {
+	if root.MaxFPS <= 0 {
+		return 0
+	}
+	return time.Second / time.Duration(root.MaxFPS)
+}
+
+// drawLimited draws the screen at most MaxFPS times per second.
+// The draw within the interval of the last draw is delayed,
+// so that the draws of a burst of events are coalesced and the last state is drawn.
+func (root *Root) drawLimited() {
+	interval := root.drawInterval()
+	if interval <= 0 {
+		root.draw()
+		return
+	}
+	now := root.clock.Now()
+	if wait := root.lastDraw.Add(interval).Sub(now); wait > 0 {
+		if !root.drawPending {
+			root.drawPending = true
+			time.AfterFunc(wait, func() {
+				ev := &eventRedraw{}
+				ev.SetEventNow()
+				if err := root.Screen.PostEvent(ev); err != nil {
+					log.Println(err)
+				}
+			})
+		}
+		return
+	}
+	root.lastDraw = now
+	root.draw()
 }
 
+// drawHeader draws the header and the section header,
+// and returns the first line of the body.
+// drawHeader draws the header, the header line and the section header,
+// and returns the number of the rows drawn.
 func (root *Root) drawHeader() int {
+	hy := root.drawHeaderLines(0, 0, root.headerNum)
+	if root.headerLineStart >= 0 {
+		hy = root.drawHeaderLines(hy, root.headerLineStart, 1)
+	}
+	if root.sectionHeaderStart >= 0 {
+		hy = root.drawHeaderLines(hy, root.sectionHeaderStart, root.sectionHeaderNum)
+	}
+	return hy
+}
+
+// drawHeaderLines draws num lines from lN as the header from the row hy,
+// and returns the next row.
+func (root *Root) drawHeaderLines(hy int, lN int, num int) int {
 	m := root.Doc
 
-	lY := 0
+	lY := lN
 	lX := 0
 	wrap := 0
-	for hy := 0; lY < m.Header; hy++ {
+	for ; lY < lN+num; hy++ {
 		if hy > root.vHight {
 			break
 		}
@@ -59,7 +134,10 @@ func (root *Root) drawHeader() int {
 
 		// column highlight
 		if m.ColumnMode {
-			str, byteMap := contentsToStr(lc)
+			str, byteMap := root.getContentsStr(lY, lc)
+			if m.ColumnRainbow {
+				root.columnRainbow(lc, str, byteMap)
+			}
 			start, end := rangePosition(str, m.ColumnDelimiter, m.columnNum)
 			root.columnHighlight(lc, byteMap[start], byteMap[end])
 		}
@@ -86,10 +164,10 @@ func (root *Root) drawHeader() int {
 		}
 	}
 
-	return lY
+	return hy
 }
 
-func (root *Root) drawBody(lX int, lY int) (int, int) {
+func (root *Root) drawBody(startY int, lX int, lY int) (int, int) {
 	m := root.Doc
 
 	listX, err := root.leftMostX(m.topLN + lY)
@@ -103,7 +181,7 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 	var lineStr string
 	var byteMap map[int]int
 
-	for y := root.headerLen(); y < root.vHight-1; y++ {
+	for y := startY; y < root.vHight-1; y++ {
 		if lastLY != lY {
 			lc = root.getLineContents(m.topLN+lY, m.TabWidth)
 			root.lineStyle(lc, root.StyleBody)
@@ -112,6 +190,36 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 				wrap: 0,
 			}
 			lineStr, byteMap = root.getContentsStr(m.topLN+lY, lc)
+			if m.concatFiles != nil && m.isConcatSeparator(m.topLN+lY) {
+				root.lineStyle(lc, root.StyleFileSeparator)
+			}
+			if m.diffOps != nil {
+				root.diffStyle(lc, m.topLN+lY)
+			}
+			if m.mergeSource != nil {
+				root.mergeSourceStyle(lc, m.topLN+lY)
+			}
+			if m.LogLevel {
+				root.logLevelHighlight(lc, lineStr, byteMap)
+			}
+			if m.UnusualSpace {
+				root.unusualSpaceStyle(lc)
+			}
+			if m.ColumnMode && m.ColumnRainbow {
+				root.columnRainbow(lc, lineStr, byteMap)
+			}
+			if m.watchChanged != nil {
+				root.watchStyle(lc, m.topLN+lY)
+			}
+			if m.selected[m.topLN+lY] {
+				root.lineStyle(lc, root.StyleSelectedLine)
+			}
+			if root.FileLinks {
+				root.fileLinkStyle(lc, lineStr, byteMap)
+			}
+			if root.pinned != nil {
+				root.pinnedStyle(lc, lineStr, byteMap)
+			}
 			lastLY = lY
 		}
 
@@ -123,19 +231,28 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 
 		// search highlight
 		if root.input.reg != nil {
-			poss := searchPosition(lineStr, root.input.reg)
+			poss := root.searchPositions(lineStr)
+			if m.DimUnmatched && len(poss) == 0 {
+				root.lineStyle(lc, root.StyleUnmatched)
+			}
 			for _, r := range poss {
 				root.searchHighlight(lc, byteMap[r[0]], byteMap[r[1]])
 			}
 		}
 
+		// timestamp of the followed lines
+		tsWidth := root.timestampWidth()
+		if tsWidth > 0 && wrap == 0 {
+			root.drawTimestamp(y, m.topLN+lY)
+		}
+
 		// line number mode
 		if m.LineNumMode {
-			lc := strToContents(fmt.Sprintf("%*d", root.startX-1, m.topLN+lY-m.Header+1), m.TabWidth)
+			lc := strToContents(fmt.Sprintf("%*d", root.startX-tsWidth-1, m.lineNumber(m.topLN+lY)), m.TabWidth)
 			for i := 0; i < len(lc); i++ {
 				lc[i].style = applyStyle(tcell.StyleDefault, root.StyleLineNumber)
 			}
-			root.setContentString(0, y, lc)
+			root.setContentString(tsWidth, y, lc)
 		}
 
 		root.lnumber[y] = lineNumber{
@@ -156,50 +273,68 @@ func (root *Root) drawBody(lX int, lY int) (int, int) {
 		}
 
 		// alternate style applies from beginning to end of line, not content.
+		// Wrapped rows have the same style as the logical line.
 		if m.AlternateRows {
-			if (m.topLN+lY)%2 == 1 {
+			if isAlternateLine(m.topLN+lY, m.AlternateEvery) {
 				for x := 0; x < root.vWidth; x++ {
 					r, c, style, _ := root.GetContent(x, y)
 					root.SetContent(x, y, r, c, applyStyle(style, root.StyleAlternate))
 				}
 			}
 		}
+
+		// cursor line style applies to the line moved to by a search or goto,
+		// or the first line of the body if it has been moved since.
+		if m.CursorLine && m.topLN+lY == m.currentLN() {
+			for x := 0; x < root.vWidth; x++ {
+				r, c, style, _ := root.GetContent(x, y)
+				root.SetContent(x, y, r, c, applyStyle(style, root.StyleCursorLine))
+			}
+		}
 		lY = nextY
 	}
 
 	return lX, lY
 }
 
-func (root *Root) getContentsStr(lN int, lc lineContents) (string, map[int]int) {
-	if root.Doc.lastContentsNum != lN {
-		root.Doc.lastContentsStr, root.Doc.lastContentsMap = contentsToStr(lc)
-		root.Doc.lastContentsNum = lN
+// isAlternateLine returns true if the line is colored by the alternate style.
+// Every n-th line is colored, and less than 2 is treated as 2.
+func isAlternateLine(lN int, n int) bool {
+	if n < 2 {
+		n = 2
 	}
-	return root.Doc.lastContentsStr, root.Doc.lastContentsMap
+	return (lN+1)%n == 0
+}
+
+// getContentsStr returns the string of the contents of the line lN and the map of its byte positions,
+// from the line cache of the frame.
+func (root *Root) getContentsStr(lN int, lc lineContents) (string, map[int]int) {
+	return root.Doc.contentsStr(lN, lc)
 }
 
+// getLineContents returns the contents of the line to be drawn.
+// The contents are copied to the buffer reused across the lines and the frames,
+// so they are valid until the next call.
 func (root *Root) getLineContents(lN int, tabWidth int) lineContents {
 	org, err := root.Doc.lineToContents(lN, tabWidth)
 	if err == nil {
-		lc := make(lineContents, len(org))
-		copy(lc, org)
-		return lc
+		root.lineBuf = append(root.lineBuf[:0], org...)
+		return root.lineBuf
 	}
 
 	// EOF
-	width := root.vWidth - root.startX
-	lc := make(lineContents, width)
-	eof := content{
+	width := max(root.vWidth-root.startX, 1)
+	lc := root.lineBuf[:0]
+	lc = append(lc, content{
 		mainc: '~',
 		combc: nil,
 		width: 1,
 		style: tcell.StyleDefault.Foreground(tcell.ColorGray),
-	}
-	lc[0] = eof
-
+	})
 	for x := 1; x < width; x++ {
-		lc[x] = DefaultContent
+		lc = append(lc, DefaultContent)
 	}
+	root.lineBuf = lc
 	return lc
 }
 
@@ -217,27 +352,98 @@ func (root *Root) wrapContents(y int, lX int, lY int, lc lineContents) (int, int
 		return 0, 0
 	}
 
+	startX := root.startX
+	if lX > 0 {
+		startX += root.drawWrapMarker(y)
+	}
 	for x := 0; ; x++ {
 		if lX+x >= len(lc) {
 			// EOL
-			root.drawEOL(root.startX+x, y)
+			root.drawEOL(startX+x, y)
 			lX = 0
 			lY++
 			break
 		}
 		content := lc[lX+x]
-		if x+content.width+root.startX > root.vWidth {
+		if x+content.width+startX > root.vWidth {
 			// EOL
-			root.drawEOL(root.startX+x, y)
+			root.drawEOL(startX+x, y)
 			lX += x
 			break
 		}
-		root.Screen.SetContent(root.startX+x, y, content.mainc, content.combc, content.style)
+		root.Screen.SetContent(startX+x, y, content.mainc, content.combc, content.style)
 	}
 
 	return lX, lY
 }
 
+// drawTruncateMarker draws the truncate marker at the end of the row.
+func (root *Root) drawTruncateMarker(y int) {
+	lc := strToContents(root.TruncateMarker, -1)
+	x := root.vWidth - len(lc)
+	if x <= root.startX {
+		return
+	}
+	// Do not leave the left half of a wide character overwritten by the marker.
+	if _, _, style, w := root.GetContent(x-1, y); w == 2 {
+		root.Screen.SetContent(x-1, y, ' ', nil, style)
+	}
+	for _, c := range lc {
+		root.Screen.SetContent(x, y, c.mainc, c.combc, applyStyle(c.style, root.StyleWrapMarker))
+		x++
+	}
+}
+
+// wrapMarkerWidth returns the width of the wrap marker and the indent.
+// It returns 0 if the continuation rows have no room for them.
+func (root *Root) wrapMarkerWidth() int {
+	w := runewidth.StringWidth(root.WrapMarker) + max(root.WrapIndent, 0)
+	if w >= root.vWidth-root.startX-1 {
+		return 0
+	}
+	return w
+}
+
+// timestampWidth returns the width of the arrival time and the following space.
+func (root *Root) timestampWidth() int {
+	if !root.Doc.Timestamp {
+		return 0
+	}
+	return runewidth.StringWidth(time.Time{}.Format(root.TimestampFormat)) + 1
+}
+
+// drawTimestamp draws the arrival time of the line at the beginning of the row.
+// Nothing is drawn for the lines that were not appended in follow mode.
+func (root *Root) drawTimestamp(y int, lN int) {
+	t, ok := root.Doc.arrivalTime(lN)
+	if !ok {
+		return
+	}
+	lc := strToContents(t.Format(root.TimestampFormat), -1)
+	for i := 0; i < len(lc); i++ {
+		lc[i].style = applyStyle(tcell.StyleDefault, root.StyleTimestamp)
+	}
+	root.setContentString(0, y, lc)
+}
+
+// drawWrapMarker draws the wrap marker and the indent at the beginning of
+// the continuation row, and returns the width.
+func (root *Root) drawWrapMarker(y int) int {
+	width := root.wrapMarkerWidth()
+	if width == 0 {
+		return 0
+	}
+	x := root.startX
+	for _, c := range strToContents(root.WrapMarker, -1) {
+		root.Screen.SetContent(x, y, c.mainc, c.combc, applyStyle(c.style, root.StyleWrapMarker))
+		x++
+	}
+	for ; x < root.startX+width; x++ {
+		root.Screen.SetContent(x, y, ' ', nil, tcell.StyleDefault)
+	}
+	return width
+}
+
 // noWrapContents draws contents without wrapping and returns the next drawing position.
 func (root *Root) noWrapContents(y int, lX int, lY int, lc lineContents) (int, int) {
 	if lX < root.minStartX {
@@ -256,6 +462,9 @@ func (root *Root) noWrapContents(y int, lX int, lY int, lc lineContents) (int, i
 		}
 		root.Screen.SetContent(root.startX+x, y, content.mainc, content.combc, content.style)
 	}
+	if root.Doc.TruncateMode && lX+root.vWidth-root.startX < len(lc) {
+		root.drawTruncateMarker(y)
+	}
 	lY++
 
 	return lX, lY
@@ -276,6 +485,76 @@ func (root *Root) columnHighlight(lc lineContents, start int, end int) {
 	RangeStyle(lc, start, end, root.StyleColumnHighlight)
 }
 
+// columnRainbow applies the styles of StyleColumnRainbow to the columns in order.
+func (root *Root) columnRainbow(lc lineContents, str string, byteMap map[int]int) {
+	styles := root.StyleColumnRainbow
+	delimiter := root.Doc.ColumnDelimiter
+	if len(styles) == 0 || delimiter == "" {
+		return
+	}
+
+	start := 0
+	for n := 0; ; n++ {
+		end := len(str)
+		if i := strings.Index(str[start:], delimiter); i >= 0 {
+			end = start + i
+		}
+		RangeStyle(lc, byteMap[start], byteMap[end], styles[n%len(styles)])
+		if end == len(str) {
+			return
+		}
+		start = end + len(delimiter)
+	}
+}
+
+// columnBand applies the style of the column band to the range
+// of the selected column in all lines on the screen, as a vertical band.
+func (root *Root) columnBand() {
+	m := root.Doc
+	start, end := -1, -1
+	for y := 0; y < root.vHight-1; y++ {
+		if !root.isLineRow(y) {
+			continue
+		}
+		lc, err := m.lineToContents(root.lnumber[y].line, m.TabWidth)
+		if err != nil {
+			continue
+		}
+		str, byteMap := root.getContentsStr(root.lnumber[y].line, lc)
+		s, e := rangePosition(str, m.ColumnDelimiter, m.columnNum)
+		if s < 0 || e < 0 {
+			continue
+		}
+		if start < 0 || byteMap[s] < start {
+			start = byteMap[s]
+		}
+		if byteMap[e] > end {
+			end = byteMap[e]
+		}
+	}
+	if start < 0 {
+		return
+	}
+
+	lX := max(m.x, root.minStartX)
+	for y := 0; y < root.vHight-1; y++ {
+		if !root.isLineRow(y) {
+			continue
+		}
+		for x := max(root.startX+start-lX, root.startX); x < root.startX+end-lX && x < root.vWidth; x++ {
+			r, c, style, _ := root.GetContent(x, y)
+			root.SetContent(x, y, r, c, applyStyle(style, root.StyleColumnBand))
+		}
+	}
+}
+
+// isLineRow returns true if the row y displays a line of the document,
+// not a row after EOF.
+func (root *Root) isLineRow(y int) bool {
+	lN := root.lnumber[y].line
+	return lN >= 0 && lN < root.Doc.BufEndNum()
+}
+
 // RangeStyle applies the style to the specified range.
 func RangeStyle(lc lineContents, start int, end int, style ovStyle) {
 	for x := start; x < end; x++ {
@@ -298,19 +577,35 @@ func (root *Root) statusDraw() {
 	}
 	follow := ""
 	if root.Doc.FollowMode {
-		follow = "(Follow Mode)"
+		follow = fmt.Sprintf("(Follow Mode %s)", humanSize(root.Doc.bufferSize()))
 	}
 	if root.General.FollowAll {
-		follow = "(Follow All)"
+		follow = fmt.Sprintf("(Follow All %s)", humanSize(root.Doc.bufferSize()))
+	}
+	if change := root.Doc.changedOnDisk(); change != "" {
+		follow = fmt.Sprintf("(File %s: %s to reload)", change, root.reloadKey)
+	}
+	follow += root.scrollBindStatus()
+	fileName := root.Doc.Title()
+	if name := root.Doc.concatFileName(root.Doc.topLN + root.Doc.Header); name != "" {
+		fileName = name
 	}
-	leftStatus := fmt.Sprintf("%s%s%s:%s", number, follow, root.Doc.FileName, root.message)
+	leftStatus := fmt.Sprintf("%s%s%s:%s", number, follow, fileName, root.message)
 	leftContents := strToContents(leftStatus, -1)
 	input := root.input
 	caseSensitive := ""
-	if root.CaseSensitive && (input.mode == Search || input.mode == Backsearch) {
+	if root.CaseSensitive && (input.mode == Search || input.mode == Backsearch || input.mode == Filter) {
 		caseSensitive = "(Aa)"
 	}
+	if root.isColumnSearch() && (input.mode == Search || input.mode == Backsearch || input.mode == Filter) {
+		caseSensitive += "(Col)"
+	}
+	if root.sectionSearch && root.Doc.SectionDelimiter != "" && (input.mode == Search || input.mode == Backsearch) {
+		caseSensitive += "(Sec)"
+	}
 
+	// minRightX is the leftmost position of the right status, not to hide the input cursor.
+	minRightX := 0
 	switch input.mode {
 	case Normal:
 		color := tcell.ColorWhite
@@ -323,10 +618,20 @@ func (root *Root) statusDraw() {
 		}
 		root.Screen.ShowCursor(len(leftContents), root.statusPos)
 	default:
-		p := caseSensitive + input.EventInput.Prompt()
+		p := caseSensitive + root.prompt()
 		leftStatus = p + input.value
-		root.Screen.ShowCursor(len(p)+input.cursorX, root.statusPos)
 		leftContents = strToContents(leftStatus, -1)
+		promptLen := len(strToContents(p, -1))
+		RangeStyle(leftContents, 0, promptLen, root.StylePrompt)
+		if input.regexpErr != nil {
+			RangeStyle(leftContents, 0, promptLen, root.StylePromptError)
+		}
+		cursor := promptLen + input.cursorX
+		skip := inputScroll(leftContents, cursor, root.vWidth)
+		leftContents = leftContents[skip:]
+		// The terminal displays the composition of the input method at the cursor.
+		root.Screen.ShowCursor(cursor-skip, root.statusPos)
+		minRightX = cursor - skip + 1
 	}
 	root.setContentString(0, root.statusPos, leftContents)
 
@@ -334,9 +639,50 @@ func (root *Root) statusDraw() {
 	if !root.Doc.BufEOF() {
 		next = "..."
 	}
-	rightStatus := fmt.Sprintf("(%d/%d%s)", root.Doc.topLN, root.Doc.BufEndNum(), next)
-	rightContents := strToContents(rightStatus, -1)
-	root.setContentString(root.vWidth-len(rightStatus), root.statusPos, rightContents)
+	rightStatus := fmt.Sprintf("%s(%d/%d%s)", root.Doc.encodingStatus(), root.Doc.topLN, root.Doc.BufEndNum(), next)
+	if input.mode != Normal && input.hint != "" {
+		rightStatus = input.hint
+	}
+	rightX, rightContents := rightAlign(strToContents(rightStatus, -1), root.vWidth, minRightX)
+	root.setContentString(rightX, root.statusPos, rightContents)
+	root.convertColors(root.statusPos, root.statusPos+1)
+}
+
+// rightAlign returns the position to draw the contents at the right end of the width,
+// and the contents clipped on the left so that they start at minX or later.
+func rightAlign(lc lineContents, width int, minX int) (int, lineContents) {
+	x := width - len(lc)
+	if x >= minX {
+		return x, lc
+	}
+	skip := minX - x
+	// Do not split a wide character.
+	for skip < len(lc) && lc[skip].width == 0 {
+		skip++
+	}
+	skip = min(skip, len(lc))
+	return x + skip, lc[skip:]
+}
+
+// inputCursorMargin is the number of the cells kept on the right of the input cursor
+// for the composition of the input method.
+const inputCursorMargin = 8
+
+// inputScroll returns the number of the cells to skip from the beginning of the input line,
+// so that the cursor and the margin on the right of it are on the screen.
+func inputScroll(lc lineContents, cursor int, width int) int {
+	if width <= inputCursorMargin*2 {
+		return 0
+	}
+	skip := cursor - (width - inputCursorMargin)
+	if skip <= 0 {
+		return 0
+	}
+	// Do not split a wide character.
+	for skip < len(lc) && lc[skip].width == 0 {
+		skip++
+	}
+	return min(skip, len(lc))
 }
 
 // setContentString is a helper function that draws a string with setContent.
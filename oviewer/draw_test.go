@@ -0,0 +1,460 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_isAlternateLine(t *testing.T) {
+	type args struct {
+		lN int
+		n  int
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{name: "every2-0", args: args{lN: 0, n: 2}, want: false},
+		{name: "every2-1", args: args{lN: 1, n: 2}, want: true},
+		{name: "every3-1", args: args{lN: 1, n: 3}, want: false},
+		{name: "every3-2", args: args{lN: 2, n: 3}, want: true},
+		{name: "every3-5", args: args{lN: 5, n: 3}, want: true},
+		{name: "every0", args: args{lN: 1, n: 0}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAlternateLine(tt.args.lN, tt.args.n); got != tt.want {
+				t.Errorf("isAlternateLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rightAlign(t *testing.T) {
+	tests := []struct {
+		name  string
+		str   string
+		width int
+		minX  int
+		wantX int
+		want  string
+	}{
+		{
+			name:  "ascii",
+			str:   "(1/1)",
+			width: 20,
+			wantX: 15,
+			want:  "(1/1)",
+		},
+		{
+			name:  "wide",
+			str:   "ファイル(1/1)",
+			width: 20,
+			wantX: 7,
+			want:  "ファイル(1/1)",
+		},
+		{
+			name:  "clip",
+			str:   "abcdefghij",
+			width: 20,
+			minX:  15,
+			wantX: 15,
+			want:  "fghij",
+		},
+		{
+			name:  "clip wide",
+			str:   "あいうえお",
+			width: 20,
+			minX:  13,
+			wantX: 14,
+			want:  "うえお",
+		},
+		{
+			name:  "no room",
+			str:   "abc",
+			width: 20,
+			minX:  20,
+			wantX: 20,
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotX, got := rightAlign(strToContents(tt.str, -1), tt.width, tt.minX)
+			if gotX != tt.wantX {
+				t.Errorf("rightAlign() x = %v, want %v", gotX, tt.wantX)
+			}
+			if s, _ := contentsToStr(got); s != tt.want {
+				t.Errorf("rightAlign() = %q, want %q", s, tt.want)
+			}
+		})
+	}
+}
+
+func Test_inputScroll(t *testing.T) {
+	tests := []struct {
+		name   string
+		str    string
+		cursor int
+		width  int
+		want   int
+	}{
+		{
+			name:   "fit",
+			str:    "Search:abc",
+			cursor: 10,
+			width:  80,
+			want:   0,
+		},
+		{
+			name:   "scroll",
+			str:    "Search:" + strings.Repeat("a", 30),
+			cursor: 37,
+			width:  20,
+			want:   25,
+		},
+		{
+			name:   "wide",
+			str:    "Search:" + strings.Repeat("あ", 15),
+			cursor: 37,
+			width:  21,
+			want:   25,
+		},
+		{
+			name:   "narrow screen",
+			str:    "Search:" + strings.Repeat("a", 30),
+			cursor: 37,
+			width:  10,
+			want:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := strToContents(tt.str, -1)
+			if got := inputScroll(lc, tt.cursor, tt.width); got != tt.want {
+				t.Errorf("inputScroll() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_getLineContents(t *testing.T) {
+	m := testLineDocument(t, 0, "abc", "de")
+	root := &Root{Doc: m, vWidth: 10}
+	tests := []struct {
+		name string
+		lN   int
+		want string
+	}{
+		{name: "line", lN: 0, want: "abc"},
+		{name: "shorter line", lN: 1, want: "de"},
+		{name: "EOF", lN: 2, want: "~"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := root.getLineContents(tt.lN, 8)
+			if tt.lN >= m.BufEndNum() && len(lc) != root.vWidth {
+				t.Errorf("Root.getLineContents() width = %v, want %v", len(lc), root.vWidth)
+			}
+			if got, _ := contentsToStr(lc); got != tt.want {
+				t.Errorf("Root.getLineContents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The buffer is reused for the next lines.
+	allocs := testing.AllocsPerRun(10, func() {
+		root.getLineContents(2, 8)
+	})
+	if allocs != 0 {
+		t.Errorf("Root.getLineContents() allocs = %v, want 0", allocs)
+	}
+}
+
+func TestRoot_drawLimited(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	root, err := NewOviewer(testLineDocument(t, 0, "a", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := newFakeClock()
+	root.SetClock(clock)
+	root.MaxFPS = 50
+	root.prepareView()
+
+	root.drawLimited()
+	if root.lastDraw != clock.now || root.drawPending {
+		t.Fatalf("the first draw is not drawn: %v %v", root.lastDraw, root.drawPending)
+	}
+	first := clock.now
+
+	// Within the interval, the draw is delayed.
+	clock.now = clock.now.Add(5 * time.Millisecond)
+	root.drawLimited()
+	root.drawLimited()
+	if root.lastDraw != first || !root.drawPending {
+		t.Fatalf("the draw is not delayed: %v %v", root.lastDraw, root.drawPending)
+	}
+	if _, ok := root.Screen.PollEvent().(*eventRedraw); !ok {
+		t.Fatal("the delayed draw is not posted")
+	}
+	root.drawPending = false
+
+	// After the interval, it is drawn.
+	clock.now = clock.now.Add(20 * time.Millisecond)
+	root.drawLimited()
+	if root.lastDraw != clock.now {
+		t.Errorf("the draw after the interval is not drawn: %v", root.lastDraw)
+	}
+}
+
+func TestRoot_drawCursorLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		scrollOff int
+		target    string
+	}{
+		{name: "top", scrollOff: 0, target: ""},
+		{name: "scrollOff", scrollOff: 3, target: ""},
+		{name: "center", scrollOff: 0, target: "center"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testSearchRoot(t)
+			root.Doc.CursorLine = true
+			root.ScrollOff = tt.scrollOff
+			root.JumpTarget = tt.target
+			root.jumpLine(30)
+			root.draw()
+			found := false
+			for y := 0; y < root.statusPos; y++ {
+				_, _, style, _ := root.GetContent(0, y)
+				_, _, attr := style.Decompose()
+				cursor := root.lnumber[y].line == 30
+				if (attr&tcell.AttrUnderline != 0) != cursor {
+					t.Errorf("line %d underline = %v, want %v", root.lnumber[y].line, !cursor, cursor)
+				}
+				found = found || cursor
+			}
+			if !found {
+				t.Fatal("the line moved to is not drawn")
+			}
+		})
+	}
+}
+
+func TestRoot_columnBand(t *testing.T) {
+	m := testLineDocument(t, 0, "a,b", "c,d")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Screen.(tcell.SimulationScreen).SetSize(80, 25)
+	root.prepareView()
+	m.ColumnMode = true
+	m.ColumnBand = true
+	m.WrapMode = false
+	m.columnNum = 0
+	root.StyleColumnBand = ovStyle{Background: "red"}
+	root.draw()
+	for y := 0; y < root.statusPos; y++ {
+		_, _, style, _ := root.GetContent(root.startX, y)
+		_, bg, _ := style.Decompose()
+		want := y < m.BufEndNum()
+		if (bg == tcell.ColorRed) != want {
+			t.Errorf("row %d band = %v, want %v", y, bg == tcell.ColorRed, want)
+		}
+	}
+}
+
+func TestRoot_columnRainbow(t *testing.T) {
+	m := testLineDocument(t, 0)
+	root := &Root{Doc: m}
+	root.StyleColumnRainbow = []ovStyle{
+		{Foreground: "red"},
+		{Foreground: "green"},
+		{Foreground: "blue"},
+	}
+	lc := strToContents("a,b,c,d,e", 8)
+	str, byteMap := contentsToStr(lc)
+	root.columnRainbow(lc, str, byteMap)
+	want := []tcell.Color{tcell.ColorRed, tcell.ColorGreen, tcell.ColorBlue, tcell.ColorRed, tcell.ColorGreen}
+	for i, color := range want {
+		fg, _, _ := lc[i*2].style.Decompose()
+		if fg != color {
+			t.Errorf("column %d foreground = %v, want %v", i, fg, color)
+		}
+	}
+}
+
+func TestConfig_columnRainbowPalette(t *testing.T) {
+	dark := NewConfig()
+	light := NewConfig()
+	light.setLightStyles()
+	for _, tt := range []struct {
+		name      string
+		styles    []ovStyle
+		invisible string
+	}{
+		{name: "dark", styles: dark.StyleColumnRainbow, invisible: "white"},
+		{name: "light", styles: light.StyleColumnRainbow, invisible: "black"},
+	} {
+		for _, s := range tt.styles {
+			if s.Foreground == tt.invisible {
+				t.Errorf("%s palette includes %s", tt.name, tt.invisible)
+			}
+		}
+	}
+}
+
+// testDrawRoot returns the root drawn on the screen of the size.
+func testDrawRoot(t *testing.T, width int, height int, m *Document, setup func(root *Root)) *Root {
+	t.Helper()
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Screen.(tcell.SimulationScreen).SetSize(width, height)
+	setup(root)
+	root.prepareView()
+	root.draw()
+	return root
+}
+
+// screenRow returns the string of the row y of the screen.
+func screenRow(root *Root, y int) string {
+	var b strings.Builder
+	for x := 0; x < root.vWidth; x++ {
+		r, _, _, w := root.GetContent(x, y)
+		b.WriteRune(r)
+		x += max(w, 1) - 1
+	}
+	return b.String()
+}
+
+func TestRoot_wrapContents(t *testing.T) {
+	tests := []struct {
+		name     string
+		indent   int
+		wantRows []string
+	}{
+		{
+			name:   "marker and indent",
+			indent: 2,
+			wantRows: []string{
+				"abcdefghij",
+				">  klmnopq",
+				">  rstuvwx",
+				">  yz     ",
+			},
+		},
+		{
+			name:   "indent wider than the screen",
+			indent: 20,
+			wantRows: []string{
+				"abcdefghij",
+				"klmnopqrst",
+				"uvwxyz    ",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, 0, "abcdefghijklmnopqrstuvwxyz")
+			root := testDrawRoot(t, 10, 6, m, func(root *Root) {
+				m.WrapMode = true
+				root.WrapMarker = ">"
+				root.WrapIndent = tt.indent
+			})
+			for y, want := range tt.wantRows {
+				if got := screenRow(root, y); got != want {
+					t.Errorf("row %d = %q, want %q", y, got, want)
+				}
+				if root.lnumber[y].wrap != y {
+					t.Errorf("row %d wrap = %d, want %d", y, root.lnumber[y].wrap, y)
+				}
+			}
+			listX, err := root.leftMostX(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(listX) != len(tt.wantRows) {
+				t.Errorf("leftMostX() = %v, want %d rows", listX, len(tt.wantRows))
+			}
+		})
+	}
+}
+
+func TestRoot_drawTruncateMarker(t *testing.T) {
+	m := testLineDocument(t, 0, "abcdefghijklmno", "abcdefghij", "あいうえおかき")
+	root := testDrawRoot(t, 10, 6, m, func(root *Root) {
+		m.WrapMode = false
+		m.TruncateMode = true
+		root.TruncateMarker = ">"
+	})
+	wantRows := []string{
+		"abcdefghi>",
+		"abcdefghij",
+		"あいうえ >",
+	}
+	for y, want := range wantRows {
+		if got := screenRow(root, y); got != want {
+			t.Errorf("row %d = %q, want %q", y, got, want)
+		}
+	}
+}
+
+func TestRoot_drawHeader(t *testing.T) {
+	m := testLineDocument(t, 1, "abcdefghijklmnopqrstuvwxyz", "body")
+	root := testDrawRoot(t, 10, 10, m, func(root *Root) {
+		m.WrapMode = true
+		root.WrapMarker = ""
+		root.WrapIndent = 0
+	})
+	// The header line is wrapped into 3 rows.
+	if got := root.drawHeader(); got != 3 {
+		t.Errorf("drawHeader() = %d, want 3", got)
+	}
+	if got := screenRow(root, 3); got != "body      " {
+		t.Errorf("row 3 = %q, want the body", got)
+	}
+}
+
+func TestRoot_setWrapHeaderLen(t *testing.T) {
+	m := testLineDocument(t, 1, "abcdefghijklmnopqrstuvwxyz", "body")
+	root := testDrawRoot(t, 10, 10, m, func(root *Root) {
+		m.WrapMode = true
+		root.WrapMarker = ""
+		root.WrapIndent = 0
+	})
+	if root.wrapHeaderLen != 3 {
+		t.Fatalf("wrapHeaderLen = %d, want 3", root.wrapHeaderLen)
+	}
+
+	// The result is reused while the width, wrap and header are the same.
+	root.wrapHeaderLen = 99
+	root.setWrapHeaderLen()
+	if root.wrapHeaderLen != 99 {
+		t.Errorf("wrapHeaderLen = %d, want the cached 99", root.wrapHeaderLen)
+	}
+
+	root.Screen.(tcell.SimulationScreen).SetSize(20, 10)
+	root.prepareView()
+	root.setWrapHeaderLen()
+	if root.wrapHeaderLen != 2 {
+		t.Errorf("wrapHeaderLen after resize = %d, want 2", root.wrapHeaderLen)
+	}
+
+	m.Header = 2
+	root.setWrapHeaderLen()
+	if root.wrapHeaderLen != 3 {
+		t.Errorf("wrapHeaderLen after the header change = %d, want 3", root.wrapHeaderLen)
+	}
+}
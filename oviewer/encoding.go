@@ -0,0 +1,117 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"unicode/utf16"
+)
+
+// The encodings detected by the BOM.
+const (
+	encodingUTF8    = "UTF-8"
+	encodingUTF8BOM = "UTF-8 BOM"
+	encodingUTF16LE = "UTF-16LE"
+	encodingUTF16BE = "UTF-16BE"
+)
+
+// newlinePeekSize is the maximum size of the buffer peeked to detect the newline style.
+const newlinePeekSize = 4096
+
+// detectEncoding strips the BOM at the beginning of the reader,
+// and returns the encoding and the reader decoded to UTF-8.
+func detectEncoding(reader *bufio.Reader) (string, *bufio.Reader) {
+	bom, _ := reader.Peek(3)
+	switch {
+	case bytes.HasPrefix(bom, []byte{0xef, 0xbb, 0xbf}):
+		_, _ = reader.Discard(3)
+		return encodingUTF8BOM, reader
+	case bytes.HasPrefix(bom, []byte{0xff, 0xfe}):
+		_, _ = reader.Discard(2)
+		return encodingUTF16LE, bufio.NewReader(newUTF16Reader(reader, binary.LittleEndian))
+	case bytes.HasPrefix(bom, []byte{0xfe, 0xff}):
+		_, _ = reader.Discard(2)
+		return encodingUTF16BE, bufio.NewReader(newUTF16Reader(reader, binary.BigEndian))
+	}
+	return encodingUTF8, reader
+}
+
+// detectNewline returns the newline style ("LF" or "CRLF") of the first line
+// in the buffered data of the reader, or "" if it is unknown.
+func detectNewline(reader *bufio.Reader) string {
+	if _, err := reader.Peek(1); err != nil {
+		return ""
+	}
+	buf, _ := reader.Peek(min(reader.Buffered(), newlinePeekSize))
+	i := bytes.IndexByte(buf, '\n')
+	switch {
+	case i < 0:
+		return ""
+	case i > 0 && buf[i-1] == '\r':
+		return "CRLF"
+	}
+	return "LF"
+}
+
+// utf16Reader decodes UTF-16 into UTF-8.
+type utf16Reader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	// in is the bytes not decoded yet.
+	in []byte
+	// out is the decoded UTF-8 bytes not read yet.
+	out []byte
+	err error
+}
+
+// newUTF16Reader returns a reader that decodes UTF-16 of the byte order into UTF-8.
+func newUTF16Reader(r io.Reader, order binary.ByteOrder) *utf16Reader {
+	return &utf16Reader{r: r, order: order}
+}
+
+// Read reads the decoded UTF-8 bytes.
+func (u *utf16Reader) Read(p []byte) (int, error) {
+	buf := make([]byte, 4096)
+	for len(u.out) == 0 && u.err == nil {
+		n, err := u.r.Read(buf)
+		u.in = append(u.in, buf[:n]...)
+		u.err = err
+		u.decode(err != nil)
+	}
+	if len(u.out) == 0 {
+		return 0, u.err
+	}
+	n := copy(p, u.out)
+	u.out = u.out[n:]
+	return n, nil
+}
+
+// decode decodes the complete code units of in to out.
+// The last high surrogate is kept until the next read unless final.
+func (u *utf16Reader) decode(final bool) {
+	units := make([]uint16, 0, len(u.in)/2)
+	i := 0
+	for ; i+1 < len(u.in); i += 2 {
+		units = append(units, u.order.Uint16(u.in[i:]))
+	}
+	if n := len(units); !final && n > 0 && units[n-1] >= 0xd800 && units[n-1] < 0xdc00 {
+		units = units[:n-1]
+		i -= 2
+	}
+	u.in = append(u.in[:0], u.in[i:]...)
+	u.out = append(u.out, string(utf16.Decode(units))...)
+}
+
+// encodingStatus returns the encoding and the newline style of the document for the status line.
+func (m *Document) encodingStatus() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.encoding == "" {
+		return ""
+	}
+	if m.newline == "" {
+		return "(" + m.encoding + ")"
+	}
+	return "(" + m.encoding + " " + m.newline + ")"
+}
@@ -0,0 +1,94 @@
+package oviewer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+)
+
+func Test_detectEncoding(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       []byte
+		want        string
+		wantNewline string
+		wantStr     string
+	}{
+		{
+			name:        "utf8",
+			input:       []byte("a,b\nc,d\n"),
+			want:        encodingUTF8,
+			wantNewline: "LF",
+			wantStr:     "a,b\nc,d\n",
+		},
+		{
+			name:        "utf8BOM",
+			input:       []byte("\xef\xbb\xbfa,b\r\nc,d\r\n"),
+			want:        encodingUTF8BOM,
+			wantNewline: "CRLF",
+			wantStr:     "a,b\r\nc,d\r\n",
+		},
+		{
+			name:        "utf16LE",
+			input:       []byte{0xff, 0xfe, 'a', 0, 0x42, 0x30, 0x3d, 0xd8, 0x00, 0xde, '\n', 0},
+			want:        encodingUTF16LE,
+			wantNewline: "LF",
+			wantStr:     "aあ😀\n",
+		},
+		{
+			name:        "utf16BE",
+			input:       []byte{0xfe, 0xff, 0, 'a', 0, '\r', 0, '\n'},
+			want:        encodingUTF16BE,
+			wantNewline: "CRLF",
+			wantStr:     "a\r\n",
+		},
+		{
+			name:        "noNewline",
+			input:       []byte("abc"),
+			want:        encodingUTF8,
+			wantNewline: "",
+			wantStr:     "abc",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reader := detectEncoding(bufio.NewReader(bytes.NewReader(tt.input)))
+			if got != tt.want {
+				t.Errorf("detectEncoding() = %v, want %v", got, tt.want)
+			}
+			if newline := detectNewline(reader); newline != tt.wantNewline {
+				t.Errorf("detectNewline() = %v, want %v", newline, tt.wantNewline)
+			}
+			b, err := io.ReadAll(reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != tt.wantStr {
+				t.Errorf("detectEncoding() reader = %q, want %q", b, tt.wantStr)
+			}
+		})
+	}
+}
+
+// oneByteReader reads one byte at a time.
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	return o.r.Read(p[:1])
+}
+
+func Test_utf16ReaderSplit(t *testing.T) {
+	input := []byte{0x3d, 0xd8, 0x00, 0xde, 'b', 0}
+	r := newUTF16Reader(oneByteReader{bytes.NewReader(input)}, binary.LittleEndian)
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), "😀b"; got != want {
+		t.Errorf("utf16Reader = %q, want %q", got, want)
+	}
+}
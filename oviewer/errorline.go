@@ -0,0 +1,220 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// defaultErrorPatterns is the default patterns of ErrorPatterns,
+// the file:line:col of compilers and linters and the traceback of Python.
+var defaultErrorPatterns = []string{
+	`^\s*(?P<file>[^\s:]+\.[A-Za-z0-9]+):(?P<line>\d+)(?::(?P<col>\d+))?`,
+	`File "(?P<file>[^"]+)", line (?P<line>\d+)`,
+}
+
+// errorLocation represents the location referenced by an error line.
+type errorLocation struct {
+	file string
+	line int
+	col  int
+}
+
+// String returns the location as file:line:col.
+func (e errorLocation) String() string {
+	if e.col > 0 {
+		return fmt.Sprintf("%s:%d:%d", e.file, e.line, e.col)
+	}
+	return fmt.Sprintf("%s:%d", e.file, e.line)
+}
+
+// pendingLocation is the location of the opened document
+// that is moved to when the document is read to the line.
+type pendingLocation struct {
+	m   *Document
+	loc errorLocation
+}
+
+// errorRegexps returns the compiled ErrorPatterns.
+// The invalid patterns are ignored.
+func (root *Root) errorRegexps() []*regexp.Regexp {
+	if root.errorRegs != nil {
+		return root.errorRegs
+	}
+	regs := make([]*regexp.Regexp, 0, len(root.ErrorPatterns))
+	for _, pattern := range root.ErrorPatterns {
+		reg, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Printf("error pattern %s: %s", pattern, err)
+			continue
+		}
+		regs = append(regs, reg)
+	}
+	root.errorRegs = regs
+	return regs
+}
+
+// parseErrorLine returns the location of the error line.
+// The patterns have the named groups file, line and optionally col.
+func parseErrorLine(regs []*regexp.Regexp, str string) (errorLocation, bool) {
+	if strings.ContainsAny(str, "\x1b\b") {
+		str = stripEscapeSequence.ReplaceAllString(str, "")
+	}
+	for _, reg := range regs {
+		match := reg.FindStringSubmatch(str)
+		if match == nil {
+			continue
+		}
+		var loc errorLocation
+		for i, name := range reg.SubexpNames() {
+			switch name {
+			case "file":
+				loc.file = match[i]
+			case "line":
+				loc.line, _ = strconv.Atoi(match[i])
+			case "col":
+				loc.col, _ = strconv.Atoi(match[i])
+			}
+		}
+		if loc.file != "" && loc.line > 0 {
+			return loc, true
+		}
+	}
+	return errorLocation{}, false
+}
+
+// nextError moves to the next error line.
+func (root *Root) nextError() {
+	m := root.Doc
+	root.moveError(m.topLN+m.Header+1, m.BufEndNum(), 1)
+}
+
+// previousError moves to the previous error line.
+func (root *Root) previousError() {
+	m := root.Doc
+	root.moveError(m.topLN+m.Header-1, m.Header-1, -1)
+}
+
+// moveError moves to the first error line from start to end (exclusive) by step.
+func (root *Root) moveError(start int, end int, step int) {
+	regs := root.errorRegexps()
+	m := root.Doc
+	for n := start; n != end; n += step {
+		if loc, ok := parseErrorLine(regs, m.GetLine(n)); ok {
+			root.jumpLine(n)
+			root.setMessage(loc.String())
+			return
+		}
+	}
+	root.setMessage("no more errors")
+}
+
+// openError opens the file referenced by the current error line.
+// With FileLinks, the first file:line reference of the line is opened
+// if the line does not match ErrorPatterns.
+func (root *Root) openError() {
+	src := root.Doc
+	line := src.GetLine(src.topLN + src.Header)
+	loc, ok := parseErrorLine(root.errorRegexps(), line)
+	if !ok && root.FileLinks {
+		if links := fileLinks(stripEscapeSequence.ReplaceAllString(line, "")); len(links) > 0 {
+			loc, ok = links[0].loc, true
+		}
+	}
+	if !ok {
+		root.setMessage("no error location")
+		return
+	}
+	root.openLocation(loc, src.FileName)
+}
+
+// openLocation opens the file of the location as a new document and moves to the line.
+// If the file is already open, it switches to the document.
+func (root *Root) openLocation(loc errorLocation, docName string) {
+	if root.restricted() {
+		return
+	}
+	fileName := errorFileName(loc.file, docName)
+
+	if num := root.documentNum(fileName); num >= 0 {
+		root.setDocumentNum(num)
+	} else {
+		m, err := NewDocument()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+		if err := m.ReadFile(fileName); err != nil {
+			root.setMessage(err.Error())
+			return
+		}
+		root.addDocument(m)
+		if !lineRead(m, loc.line) {
+			// It moves when the lines are read (see movePendingLocation),
+			// without blocking the event loop.
+			root.pendingLocation = &pendingLocation{m: m, loc: loc}
+			root.setMessage(fmt.Sprintf("reading %s", loc))
+			return
+		}
+	}
+	root.moveLocation(loc)
+}
+
+// moveLocation moves to the line and column of the location in the current document.
+func (root *Root) moveLocation(loc errorLocation) {
+	root.ViewSync()
+	root.jumpLine(loc.line - 1)
+	root.jumpColumn(loc.line-1, loc.col)
+	root.setMessage(loc.String())
+}
+
+// movePendingLocation moves to the pending location if its document has been read to the line or the end.
+// It is called when the lines are read. The location is discarded if the document is no longer current.
+func (root *Root) movePendingLocation() {
+	p := root.pendingLocation
+	if p == nil || !lineRead(p.m, p.loc.line) {
+		return
+	}
+	root.pendingLocation = nil
+	if root.Doc != p.m {
+		return
+	}
+	root.moveLocation(p.loc)
+}
+
+// lineRead returns true if the document has been read to the line (from 1) or the end.
+func lineRead(m *Document, line int) bool {
+	return m.BufEndNum() >= line || m.BufEOF()
+}
+
+// errorFileName returns the name of the file of the error.
+// A relative name that does not exist is looked up in the directory of the document.
+func errorFileName(name string, docName string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if _, err := os.Stat(name); err == nil {
+		return name
+	}
+	alt := filepath.Join(filepath.Dir(docName), name)
+	if _, err := os.Stat(alt); err == nil {
+		return alt
+	}
+	return name
+}
+
+// documentNum returns the index of the document of the file, or -1 if it is not open.
+func (root *Root) documentNum(fileName string) int {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for n, doc := range root.DocList {
+		if doc.FileName == fileName {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,88 @@
+package oviewer
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_parseErrorLine(t *testing.T) {
+	regs := make([]*regexp.Regexp, 0, len(defaultErrorPatterns))
+	for _, pattern := range defaultErrorPatterns {
+		regs = append(regs, regexp.MustCompile(pattern))
+	}
+	tests := []struct {
+		name   string
+		str    string
+		want   errorLocation
+		wantOk bool
+	}{
+		{
+			name:   "go",
+			str:    "oviewer/draw.go:12:5: undefined: foo",
+			want:   errorLocation{file: "oviewer/draw.go", line: 12, col: 5},
+			wantOk: true,
+		},
+		{
+			name:   "indent",
+			str:    "    search_test.go:81: got 1, want 2",
+			want:   errorLocation{file: "search_test.go", line: 81},
+			wantOk: true,
+		},
+		{
+			name:   "color",
+			str:    "\x1b[1mmain.c:3:1:\x1b[0m error: expected ';'",
+			want:   errorLocation{file: "main.c", line: 3, col: 1},
+			wantOk: true,
+		},
+		{
+			name:   "python",
+			str:    `  File "app/main.py", line 42, in run`,
+			want:   errorLocation{file: "app/main.py", line: 42},
+			wantOk: true,
+		},
+		{
+			name:   "time",
+			str:    "12:30:45 started",
+			wantOk: false,
+		},
+		{
+			name:   "url",
+			str:    "see https://example.com:8080/",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseErrorLine(regs, tt.str)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("parseErrorLine() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRoot_movePendingLocation(t *testing.T) {
+	m := testLineDocument(t, 0, "1", "2")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.prepareView()
+	root.pendingLocation = &pendingLocation{m: m, loc: errorLocation{file: "a.txt", line: 5}}
+
+	// Not read to the line yet.
+	root.movePendingLocation()
+	if root.pendingLocation == nil || m.jumpLN >= 0 {
+		t.Fatalf("movePendingLocation() moved before the line is read: %d", m.jumpLN)
+	}
+
+	for _, line := range []string{"3", "4", "5", "6"} {
+		m.append(line)
+	}
+	root.movePendingLocation()
+	if root.pendingLocation != nil || m.jumpLN != 4 {
+		t.Errorf("movePendingLocation() jumpLN = %d, want 4", m.jumpLN)
+	}
+}
@@ -20,37 +20,56 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 		if root.General.FollowAll || root.Doc.FollowMode {
 			root.follow()
 		}
+		root.syncScrollBind()
+		if root.Modeline {
+			root.modeline()
+		}
+		if root.AutoTable {
+			root.autoTable()
+		}
+		root.autoDelimiter()
 
 		if !root.skipDraw {
-			root.draw()
+			root.drawLimited()
 		}
 		root.skipDraw = false
 
 		ev := root.Screen.PollEvent()
 		switch ev := ev.(type) {
+		case *eventReloadConfig:
+			root.reloadConfig()
 		case *eventAppQuit:
-			if root.screenMode != Docs {
+			if root.screenMode != Docs && !ev.shutdown {
 				root.toNormal()
 				continue
 			}
 			close(quitChan)
 			return
 		case *eventUpdateEndNum:
-			root.updateEndNum()
+			atomic.StoreInt32(&root.updatePending, 0)
+			root.updateEndNum(ev.lines)
+		case *eventFilterHint:
+			root.setFilterHint(ev)
 		case *eventDocument:
 			root.switchDocument(ev.docNum)
 		case *eventAddDocument:
 			root.addDocument(ev.m)
+		case *eventWatch:
+			root.watchReplace(ev.old, ev.m)
 		case *eventCloseDocument:
-			root.closeDocument()
+			if ev.closing == nil {
+				root.closeDocument()
+			} else {
+				root.closeDocuments(ev.closing)
+			}
 		case *eventCopySelect:
 			root.putClipboard(ctx)
 		case *eventPaste:
 			root.getClipboard(ctx)
 		case *eventSearch:
-			root.search(ctx, root.Doc.topLN+root.Doc.Header+1, root.searchLine)
+			root.nextSearch(ctx, ev.count)
 		case *eventBackSearch:
-			root.search(ctx, root.Doc.topLN+root.Doc.Header-1, root.backSearchLine)
+			root.nextBackSearch(ctx, ev.count)
 		case *viewModeInput:
 			root.setViewMode(ev.value)
 		case *searchInput:
@@ -65,18 +84,56 @@ func (root *Root) main(ctx context.Context, quitChan chan<- struct{}) {
 			root.setDelimiter(ev.value)
 		case *tabWidthInput:
 			root.setTabWidth(ev.value)
+		case *saveBufferInput:
+			root.saveBuffer(ev.value)
+		case *settingInput:
+			root.setOption(ev.value)
+		case *filterInput:
+			root.filter(ev.value)
+		case *jsonFieldsInput:
+			root.jsonFields(ev.value)
+		case *pipeInput:
+			root.pipe(ev)
+		case *replaceInput:
+			root.replace(ev.value)
+		case *renameInput:
+			root.rename(ev.value)
+		case *docSwitchInput:
+			root.docSwitch(ev.value)
+		case *shellInput:
+			root.shellCommand(ev.value)
+		case *openFileInput:
+			root.openFile(ev.value)
+		case *eventChordTimeout:
+			root.chordExpired(ev)
+		case *eventRedraw:
+			root.drawPending = false
+		case *eventScrollFrame:
+			root.scrollFrame(ev.scroll)
 		case *tcell.EventResize:
 			root.resize()
 		case *tcell.EventMouse:
 			root.mouseEvent(ev)
+		case *tcell.EventPaste:
+			root.pasteEvent(ev)
 		case *tcell.EventKey:
+			if root.pasteKey(ev) {
+				continue
+			}
+			root.finishScroll()
 			root.setMessage("")
+			root.captureMouse()
 			switch root.input.mode {
 			case Normal:
 				root.keyCapture(ev)
 			default:
 				root.inputEvent(ev)
 			}
+		case *tcell.EventError:
+			// The terminal can no longer be read (e.g. the session was disconnected).
+			log.Printf("terminal: %s", ev.Error())
+			close(quitChan)
+			return
 		case nil:
 			close(quitChan)
 			return
@@ -96,6 +153,8 @@ func (root *Root) checkScreen() bool {
 
 // eventAppQuit represents a quit event.
 type eventAppQuit struct {
+	// shutdown quits even on the help and the other screens.
+	shutdown bool
 	tcell.EventTime
 }
 
@@ -115,6 +174,10 @@ func (root *Root) Quit() {
 func (root *Root) Cancel() {
 	root.General.FollowAll = false
 	root.Doc.FollowMode = false
+	if atomic.LoadInt32(&root.Doc.filtering) == 1 {
+		root.Doc.stopReading()
+		root.setMessage("filter canceled")
+	}
 }
 
 // WriteQuit sets the write flag and executes a quit event.
@@ -124,7 +187,10 @@ func (root *Root) WriteQuit() {
 }
 
 // eventUpdateEndNum represents a timer event.
+// It is the batch of the lines appended since the last event.
 type eventUpdateEndNum struct {
+	// lines is the number of the lines appended to all documents.
+	lines int
 	tcell.EventTime
 }
 
@@ -134,6 +200,7 @@ func (root *Root) follow() {
 		root.followAll()
 	}
 
+	root.recordArrival(root.Doc)
 	root.onceFollowMode(root.Doc)
 	num := root.Doc.BufEndNum()
 	if root.Doc.latestNum != num {
@@ -154,6 +221,7 @@ func (root *Root) followAll() {
 
 	root.mu.RLock()
 	for n, doc := range root.DocList {
+		root.recordArrival(doc)
 		root.onceFollowMode(doc)
 		if doc.latestNum != doc.BufEndNum() {
 			current = n
@@ -168,6 +236,14 @@ func (root *Root) followAll() {
 	}
 }
 
+// recordArrival starts recording the arrival time of the lines
+// appended after this, if the document has the timestamp enabled.
+func (root *Root) recordArrival(doc *Document) {
+	if doc.Timestamp {
+		atomic.StoreInt32(&doc.recordArrival, 1)
+	}
+}
+
 // onceFollowMode is executed only once as follow mode.
 func (root *Root) onceFollowMode(doc *Document) {
 	doc.reOpened.Do(func() {
@@ -175,12 +251,26 @@ func (root *Root) onceFollowMode(doc *Document) {
 	})
 }
 
+// defaultUpdateInterval is the interval of eventUpdate when UpdateInterval is not set.
+const defaultUpdateInterval = 100 * time.Millisecond
+
 // updateInterval calls eventUpdate at regular intervals.
 func (root *Root) updateInterval(ctx context.Context) {
-	timer := time.NewTicker(time.Millisecond * 100)
+	interval := time.Duration(root.UpdateInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = defaultUpdateInterval
+	}
+	checkTicks := max(1, int(fileCheckInterval/interval))
+	timer := root.clock.NewTicker(interval)
+	defer timer.Stop()
+	ticks := 0
 	for {
 		select {
-		case <-timer.C:
+		case <-timer.C():
+			ticks++
+			if ticks%checkTicks == 0 {
+				root.checkFiles()
+			}
 			root.eventUpdate()
 		case <-ctx.Done():
 			return
@@ -188,12 +278,44 @@ func (root *Root) updateInterval(ctx context.Context) {
 	}
 }
 
+// pollFollow notifies the followed documents to read the appended lines
+// at FollowPollInterval, for the files whose changes are not notified
+// (such as on network file systems).
+func (root *Root) pollFollow(ctx context.Context) {
+	timer := root.clock.NewTicker(time.Duration(root.FollowPollInterval) * time.Millisecond)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C():
+			root.mu.RLock()
+			for _, doc := range root.DocList {
+				if !doc.FollowMode && !root.General.FollowAll {
+					continue
+				}
+				select {
+				case doc.changCh <- struct{}{}:
+				default:
+				}
+			}
+			root.mu.RUnlock()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
 // eventUpdate fires the event if it needs to be updated.
 func (root *Root) eventUpdate() {
 	if !root.checkScreen() {
 		return
 	}
+	// The changes are carried over to the next event
+	// while the last event has not been handled yet.
+	if atomic.LoadInt32(&root.updatePending) == 1 {
+		return
+	}
 	eventFlag := false
+	lines := 0
 
 	root.mu.RLock()
 	for _, doc := range root.DocList {
@@ -201,6 +323,7 @@ func (root *Root) eventUpdate() {
 			eventFlag = true
 			atomic.StoreInt32(&doc.changed, 0)
 		}
+		lines += doc.takeAppended()
 	}
 	root.mu.RUnlock()
 
@@ -208,11 +331,14 @@ func (root *Root) eventUpdate() {
 		return
 	}
 
-	ev := &eventUpdateEndNum{}
+	ev := &eventUpdateEndNum{lines: lines}
 	ev.SetEventNow()
+	atomic.StoreInt32(&root.updatePending, 1)
 	err := root.Screen.PostEvent(ev)
 	if err != nil {
 		log.Println(err)
+		atomic.StoreInt32(&root.updatePending, 0)
+		root.observeCount(MetricDroppedEvents, 1)
 	}
 }
 
@@ -242,11 +368,14 @@ func (root *Root) MoveBottom() {
 
 // eventSearch represents search event.
 type eventSearch struct {
+	// count is the number of times to repeat the search.
+	count int
 	tcell.EventTime
 }
 
 func (root *Root) eventNextSearch() {
 	ev := &eventSearch{}
+	ev.count = root.takeCount()
 	ev.SetEventNow()
 	err := root.Screen.PostEvent(ev)
 	if err != nil {
@@ -256,11 +385,14 @@ func (root *Root) eventNextSearch() {
 
 // eventBackSearch represents backward search event.
 type eventBackSearch struct {
+	// count is the number of times to repeat the search.
+	count int
 	tcell.EventTime
 }
 
 func (root *Root) eventNextBackSearch() {
 	ev := &eventBackSearch{}
+	ev.count = root.takeCount()
 	ev.SetEventNow()
 	err := root.Screen.PostEvent(ev)
 	if err != nil {
@@ -280,7 +412,7 @@ func (root *Root) Search(str string) {
 		return
 	}
 	root.input.value = str
-	root.input.reg = regexpComple(str, root.CaseSensitive)
+	root.input.reg = root.newSearcher(str)
 	ev := &eventSearch{}
 	ev.SetEventNow()
 	go func() {
@@ -300,7 +432,7 @@ func (root *Root) BackSearch(str string) {
 		return
 	}
 	root.input.value = str
-	root.input.reg = regexpComple(str, root.CaseSensitive)
+	root.input.reg = root.newSearcher(str)
 	ev := &eventBackSearch{}
 	ev.SetEventNow()
 	go func() {
@@ -350,15 +482,50 @@ func (root *Root) AddDocument(m *Document) {
 
 // eventCloseDocument represents a close document event.
 type eventCloseDocument struct {
+	// closing selects the documents to close.
+	// The current document is closed if it is nil.
+	closing func(*Document) bool
 	tcell.EventTime
 }
 
 // CloseDocument fires a del document event.
+// The current document is closed if m is nil.
 func (root *Root) CloseDocument(m *Document) {
+	if m == nil {
+		root.postCloseDocument(nil)
+		return
+	}
+	root.postCloseDocument(func(doc *Document) bool {
+		return doc == m
+	})
+}
+
+// CloseOtherDocuments fires an event that closes all documents except the current document.
+func (root *Root) CloseOtherDocuments() {
+	if !root.checkScreen() {
+		return
+	}
+	current := root.Doc
+	root.postCloseDocument(func(doc *Document) bool {
+		return doc != current
+	})
+}
+
+// CloseDerivedDocuments fires an event that closes all documents
+// made from other documents (filter, sort, pipe, etc.).
+func (root *Root) CloseDerivedDocuments() {
+	root.postCloseDocument(func(doc *Document) bool {
+		return doc.derived
+	})
+}
+
+// postCloseDocument fires a close document event.
+func (root *Root) postCloseDocument(closing func(*Document) bool) {
 	if !root.checkScreen() {
 		return
 	}
 	ev := &eventCloseDocument{}
+	ev.closing = closing
 	ev.SetEventNow()
 	go func() {
 		root.Screen.PostEventWait(ev)
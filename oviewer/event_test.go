@@ -0,0 +1,43 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_eventUpdate(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	m := testLineDocument(t, 0, "a", "b", "c")
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root.eventUpdate()
+	// The lines appended before the event is handled are carried over.
+	m.append("d")
+	root.eventUpdate()
+	ev, ok := root.Screen.PollEvent().(*eventUpdateEndNum)
+	if !ok {
+		t.Fatal("eventUpdateEndNum is not posted")
+	}
+	if ev.lines != 3 {
+		t.Errorf("eventUpdateEndNum.lines = %v, want 3", ev.lines)
+	}
+
+	// Handled.
+	root.updatePending = 0
+	m.append("e")
+	root.eventUpdate()
+	ev, ok = root.Screen.PollEvent().(*eventUpdateEndNum)
+	if !ok {
+		t.Fatal("eventUpdateEndNum is not posted")
+	}
+	if ev.lines != 2 {
+		t.Errorf("eventUpdateEndNum.lines = %v, want 2", ev.lines)
+	}
+}
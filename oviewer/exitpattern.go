@@ -0,0 +1,113 @@
+package oviewer
+
+import (
+	"context"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// exitPatternRegexp returns the compiled ExitPattern, or nil if it is not set.
+func (root *Root) exitPatternRegexp() *regexp.Regexp {
+	if root.ExitPattern == "" {
+		return nil
+	}
+	return regexpComple(root.ExitPattern, root.CaseSensitive)
+}
+
+// PatternMatched returns true if ExitPattern matches a line of the source documents,
+// which are read from the files and the streams, not derived from the other documents
+// (filter, pipe, diff and so on).
+// It returns true without reading the lines if the viewer quit on the match (QuitOnMatch).
+// Otherwise, it waits for the documents to be read to the end,
+// so that the result does not depend on when the viewer quit.
+// The documents in follow mode, which have no end, are checked for the lines read so far.
+func (root *Root) PatternMatched() bool {
+	reg := root.exitPatternRegexp()
+	if reg == nil {
+		return false
+	}
+	if atomic.LoadInt32(&root.exitMatched) == 1 {
+		return true
+	}
+	for _, m := range root.sourceDocuments() {
+		if !m.FollowMode && !root.General.FollowAll {
+			m.waitEOF()
+		}
+		if _, found := matchDocument(m, reg, 0); found {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceDocuments returns the documents that are not derived from the other documents.
+func (root *Root) sourceDocuments() []*Document {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	docs := make([]*Document, 0, len(root.DocList))
+	for _, m := range root.DocList {
+		if !m.derived {
+			docs = append(docs, m)
+		}
+	}
+	return docs
+}
+
+// waitEOF waits until the document is read to the end or its reading is stopped.
+func (m *Document) waitEOF() {
+	if m.BufEOF() {
+		return
+	}
+	select {
+	case <-m.eofCh:
+	case <-m.closeCh:
+	}
+}
+
+// matchDocument returns true if a line from start matches reg.
+// next is the line number to continue.
+func matchDocument(m *Document, reg *regexp.Regexp, start int) (next int, found bool) {
+	end := m.BufEndNum()
+	for n := start; n < end; n++ {
+		if matchLine(reg, m.GetLine(n), "", -1) {
+			return n, true
+		}
+	}
+	return end, false
+}
+
+// quitOnMatch quits as soon as ExitPattern matches a line of the source documents.
+// It stops when all the documents are read to the end without a match.
+func (root *Root) quitOnMatch(ctx context.Context) {
+	reg := root.exitPatternRegexp()
+	if reg == nil {
+		return
+	}
+	next := make(map[*Document]int)
+	ticker := root.clock.NewTicker(time.Millisecond * 100)
+	defer ticker.Stop()
+	for {
+		eof := true
+		for _, m := range root.sourceDocuments() {
+			// Check EOF before reading so that the lines appended in between are read next time.
+			eof = eof && m.BufEOF()
+			n, found := matchDocument(m, reg, next[m])
+			if found {
+				atomic.StoreInt32(&root.exitMatched, 1)
+				root.Quit()
+				return
+			}
+			next[m] = n
+		}
+		if eof {
+			return
+		}
+
+		select {
+		case <-ticker.C():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package oviewer
+
+import (
+	"regexp"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_matchDocument(t *testing.T) {
+	m := testLineDocument(t, 0, "ok", "\x1b[31merror\x1b[0m: failed", "ok")
+	tests := []struct {
+		name      string
+		reg       string
+		start     int
+		wantNext  int
+		wantFound bool
+	}{
+		{name: "found", reg: "error:", start: 0, wantNext: 1, wantFound: true},
+		{name: "after", reg: "error:", start: 2, wantNext: 3, wantFound: false},
+		{name: "notFound", reg: "panic", start: 0, wantNext: 3, wantFound: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, found := matchDocument(m, regexp.MustCompile(tt.reg), tt.start)
+			if next != tt.wantNext || found != tt.wantFound {
+				t.Errorf("matchDocument() = %v, %v, want %v, %v", next, found, tt.wantNext, tt.wantFound)
+			}
+		})
+	}
+}
+
+func TestRoot_PatternMatched(t *testing.T) {
+	source := testLineDocument(t, 0, "ok")
+	derived := testLineDocument(t, 0, "error: failed")
+	derived.derived = true
+	root := &Root{DocList: []*Document{source, derived}}
+	root.ExitPattern = "error:"
+
+	// The lines appended until EOF are checked.
+	done := make(chan bool)
+	go func() {
+		done <- root.PatternMatched()
+	}()
+	select {
+	case <-done:
+		t.Fatal("PatternMatched() returned before EOF")
+	case <-time.After(10 * time.Millisecond):
+	}
+	source.append("ok")
+	close(source.eofCh)
+	atomic.StoreInt32(&source.eof, 1)
+	// The derived document is not checked.
+	if <-done {
+		t.Error("PatternMatched() = true, want false")
+	}
+
+	source.append("error: late")
+	if !root.PatternMatched() {
+		t.Error("PatternMatched() = false, want true")
+	}
+
+	// Quit on the match.
+	root = &Root{DocList: []*Document{testLineDocument(t, 0, "ok")}}
+	root.ExitPattern = "error:"
+	root.exitMatched = 1
+	if !root.PatternMatched() {
+		t.Error("PatternMatched() after the quit on the match = false, want true")
+	}
+}
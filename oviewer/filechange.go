@@ -0,0 +1,109 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// fileCheckInterval is the interval between the checks of the files.
+const fileCheckInterval = time.Second
+
+// diskChange returns how the file of the document has changed on disk since it was opened:
+// "removed", "replaced", "truncated" or an empty string if it has not.
+// The appended lines are not a change, they are read in follow mode.
+func (m *Document) diskChange() string {
+	m.mu.Lock()
+	opened := m.fileInfo
+	m.mu.Unlock()
+	if opened == nil {
+		return ""
+	}
+	fi, err := os.Stat(m.FileName)
+	if err != nil {
+		return "removed"
+	}
+	if !os.SameFile(opened, fi) {
+		return "replaced"
+	}
+	if fi.Size() < opened.Size() {
+		return "truncated"
+	}
+	return ""
+}
+
+// checkFiles checks the files of the documents that are not followed,
+// and notifies the changes on disk.
+func (root *Root) checkFiles() {
+	if root.General.FollowAll {
+		return
+	}
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for _, doc := range root.DocList {
+		if doc.FollowMode || !doc.BufEOF() || doc.changedOnDisk() != "" {
+			continue
+		}
+		change := doc.diskChange()
+		if change == "" {
+			continue
+		}
+		log.Printf("%s %s", doc.FileName, change)
+		doc.mu.Lock()
+		doc.diskChanged = change
+		doc.mu.Unlock()
+		atomic.StoreInt32(&doc.changed, 1)
+	}
+}
+
+// changedOnDisk returns the change of the file detected by checkFiles.
+func (m *Document) changedOnDisk() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.diskChanged
+}
+
+// reload reads the file of the current document again,
+// keeping the position and the modes.
+func (root *Root) reload() {
+	m := root.Doc
+	if m.fileInfo == nil {
+		root.setMessage("cannot reload")
+		return
+	}
+	n, err := NewDocument()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := n.ReadFile(m.FileName); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	root.replaceDocument(m, n)
+	m.release()
+	root.setMessage(fmt.Sprintf("reloaded %s", n.FileName))
+}
+
+// replaceDocument replaces the document old in the document list with m,
+// keeping the position and the modes.
+func (root *Root) replaceDocument(old *Document, m *Document) {
+	m.general = old.general
+	m.topLN = old.topLN
+	m.topLX = old.topLX
+	m.x = old.x
+	m.columnNum = old.columnNum
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	for i, doc := range root.DocList {
+		if doc == old {
+			root.DocList[i] = m
+		}
+	}
+	if root.Doc == old {
+		root.setDocument(m)
+	}
+}
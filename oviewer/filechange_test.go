@@ -0,0 +1,80 @@
+package oviewer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocument_diskChange(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name   string
+		change func(fileName string) error
+		want   string
+	}{
+		{
+			name:   "noChange",
+			change: func(string) error { return nil },
+			want:   "",
+		},
+		{
+			name: "appended",
+			change: func(fileName string) error {
+				f, err := os.OpenFile(fileName, os.O_APPEND|os.O_WRONLY, 0o600)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				_, err = f.WriteString("c\n")
+				return err
+			},
+			want: "",
+		},
+		{
+			name: "truncated",
+			change: func(fileName string) error {
+				return os.Truncate(fileName, 0)
+			},
+			want: "truncated",
+		},
+		{
+			name: "replaced",
+			change: func(fileName string) error {
+				tmp := fileName + ".tmp"
+				if err := os.WriteFile(tmp, []byte("x\n"), 0o600); err != nil {
+					return err
+				}
+				return os.Rename(tmp, fileName)
+			},
+			want: "replaced",
+		},
+		{
+			name:   "removed",
+			change: os.Remove,
+			want:   "removed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fileName := filepath.Join(dir, tt.name+".txt")
+			if err := os.WriteFile(fileName, []byte("a\nb\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ReadFile(fileName); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			if err := tt.change(fileName); err != nil {
+				t.Fatal(err)
+			}
+			if got := m.diskChange(); got != tt.want {
+				t.Errorf("Document.diskChange() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package oviewer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// filterPreviewMax is the maximum number of lines to count in the filter preview.
+const filterPreviewMax = 100000
+
+// filterInput represents the filter input mode.
+type filterInput struct {
+	value string
+	clist *candidate
+	// preview applies the filter while typing and returns the result.
+	preview func(string) string
+	// cancel restores the state changed by the preview when the input is canceled.
+	cancel func()
+	tcell.EventTime
+}
+
+// newFilterInput returns filterInput.
+func newFilterInput(clist *candidate, preview func(string) string, cancel func()) *filterInput {
+	return &filterInput{clist: clist, preview: preview, cancel: cancel}
+}
+
+// Prompt returns the prompt string in the input field.
+func (f *filterInput) Prompt() string {
+	return "Filter:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (f *filterInput) Confirm(str string) tcell.Event {
+	f.value = str
+	f.clist.list = toLast(f.clist.list, str)
+	f.clist.p = 0
+	f.SetEventNow()
+	return f
+}
+
+// Up returns strings when the up key is pressed during input.
+func (f *filterInput) Up(str string) string {
+	return f.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (f *filterInput) Down(str string) string {
+	return f.clist.match(str).down()
+}
+
+// Hint returns the number of matching lines of the filter being typed.
+func (f *filterInput) Hint(str string) string {
+	return f.preview(str)
+}
+
+// Cancel restores the highlight of the search before the filter being typed.
+func (f *filterInput) Cancel() {
+	f.cancel()
+}
+
+func (root *Root) setFilterMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Filter
+	reg := input.reg
+	input.EventInput = newFilterInput(input.SearchCandidate, root.filterPreview, func() {
+		root.stopFilterPreview()
+		input.reg = reg
+	})
+}
+
+// eventFilterHint represents the result of the filter preview.
+type eventFilterHint struct {
+	value string
+	hint  string
+	tcell.EventTime
+}
+
+// filterPreview highlights the matches of the filter being typed,
+// and counts the matching lines in the background.
+// The number of the lines is displayed by eventFilterHint.
+func (root *Root) filterPreview(str string) string {
+	root.stopFilterPreview()
+	if str == "" {
+		root.input.reg = nil
+		return ""
+	}
+	reg := root.newSearcher(str)
+	root.input.reg = reg
+	if reg == nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	root.filterCancel = cancel
+	m := root.Doc
+	column := root.filterColumn()
+	go func() {
+		num, all, err := countMatchLines(ctx, m, reg, column, filterPreviewMax)
+		if err != nil {
+			return
+		}
+		hint := fmt.Sprintf("%d lines", num)
+		if !all {
+			hint = fmt.Sprintf("%d+ lines", num)
+		}
+		ev := &eventFilterHint{value: str, hint: hint}
+		ev.SetEventNow()
+		root.Screen.PostEventWait(ev)
+	}()
+	return ""
+}
+
+// stopFilterPreview stops counting the lines of the last filter preview.
+func (root *Root) stopFilterPreview() {
+	if root.filterCancel != nil {
+		root.filterCancel()
+		root.filterCancel = nil
+	}
+}
+
+// setFilterHint displays the result of the filter preview if the filter is still being typed.
+func (root *Root) setFilterHint(ev *eventFilterHint) {
+	if root.input.mode != Filter || root.input.value != ev.value {
+		return
+	}
+	root.input.hint = ev.hint
+}
+
+// filter adds a document of the lines that match the filter.
+// If DimUnmatched is set, it keeps all the lines and dims the unmatched lines instead.
+func (root *Root) filter(str string) {
+	root.stopFilterPreview()
+	if str == "" {
+		root.input.reg = nil
+		return
+	}
+	reg := root.newSearcher(str)
+	if reg == nil {
+		return
+	}
+	root.input.reg = reg
+	if root.Doc.DimUnmatched {
+		root.setMessage(fmt.Sprintf("filter:%v (dim unmatched)", str))
+		return
+	}
+	root.addFilterDocument("filter", str, reg)
+}
+
+// addFilterDocument adds a document of the lines of the current document that match reg.
+// The lines are filtered in the background, and the cancel key stops it (see Cancel).
+func (root *Root) addFilterDocument(name string, str string, reg Searcher) {
+	src := root.Doc
+	m, err := filterDocument(src, reg, root.filterColumn())
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	m.FileName = fmt.Sprintf("%s:%s:%s", name, str, src.FileName)
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("%s:%v (%s)Cancel", name, str, strings.Join(root.cancelKeys, ",")))
+}
+
+// filterColumn returns the column number to filter, or -1 for the whole line.
+func (root *Root) filterColumn() int {
+	if !root.isColumnSearch() {
+		return -1
+	}
+	return root.Doc.columnNum
+}
+
+// filterDocument returns a new document of the lines of src that match reg.
+// If column is not negative, only the column is matched.
+// The header lines are always included.
+// The lines read so far are filtered in the background,
+// until the document is closed or the filter is stopped by stopReading.
+func filterDocument(src *Document, reg Searcher, column int) (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	atomic.StoreInt32(&m.filtering, 1)
+	go m.filterLines(src, reg, column, src.BufEndNum())
+	return m, nil
+}
+
+// filterLines appends the lines of src up to end that match reg.
+// It stops if the document is closed, and the document is EOF when it returns.
+func (m *Document) filterLines(src *Document, reg Searcher, column int, end int) {
+	defer func() {
+		atomic.StoreInt32(&m.filtering, 0)
+		close(m.eofCh)
+		atomic.StoreInt32(&m.eof, 1)
+	}()
+	for n := 0; n < end; n++ {
+		if n%literalBatch == 0 && m.checkClose() {
+			return
+		}
+		line := src.GetLine(n)
+		if n < src.Header || matchLine(reg, line, src.ColumnDelimiter, column) {
+			m.append(line)
+		}
+	}
+}
+
+// countMatchLines returns the number of lines that match reg, up to the limit.
+// all is false if it stopped counting at the limit.
+// It returns ErrCancel if ctx is canceled.
+func countMatchLines(ctx context.Context, m *Document, reg Searcher, column int, limit int) (num int, all bool, err error) {
+	end := m.BufEndNum()
+	for n := m.Header; n < end; n++ {
+		if n-m.Header >= limit {
+			return num, false, nil
+		}
+		if (n-m.Header)%literalBatch == 0 {
+			select {
+			case <-ctx.Done():
+				return num, false, ErrCancel
+			default:
+			}
+		}
+		if matchLine(reg, m.GetLine(n), m.ColumnDelimiter, column) {
+			num++
+		}
+	}
+	return num, true, nil
+}
+
+// matchLine returns true if the line excluding escape sequences matches reg.
+// If column is not negative, only the column is matched.
+func matchLine(reg Searcher, line string, delimiter string, column int) bool {
+	if strings.ContainsAny(line, "\x1b\b") {
+		line = stripEscapeSequence.ReplaceAllString(line, "")
+	}
+	if column >= 0 {
+		line = columnString(line, delimiter, column)
+	}
+	return reg.MatchString(line)
+}
+
+// lastSearchDocument adds a document of the lines that match the last search or filter.
+func (root *Root) lastSearchDocument() {
+	root.searchDocument(root.input.reg)
+}
+
+// searchDocument adds a document of the lines that match reg.
+// The document is a copy of the lines, independent of the source document.
+func (root *Root) searchDocument(reg Searcher) {
+	if reg == nil {
+		root.setMessage("no search")
+		return
+	}
+	str := strings.TrimPrefix(reg.String(), "(?i)")
+	root.addFilterDocument("search", str, reg)
+}
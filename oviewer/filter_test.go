@@ -0,0 +1,234 @@
+package oviewer
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func testLineDocument(t *testing.T, header int, lines ...string) *Document {
+	t.Helper()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range lines {
+		m.append(line)
+	}
+	m.Header = header
+	m.ColumnDelimiter = ","
+	return m
+}
+
+func Test_filterDocument(t *testing.T) {
+	type args struct {
+		header int
+		lines  []string
+		reg    *regexp.Regexp
+		column int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "testMatch",
+			args: args{lines: []string{"ok 1", "failed 2", "ok 3"}, reg: regexp.MustCompile("ok"), column: -1},
+			want: []string{"ok 1", "ok 3"},
+		},
+		{
+			name: "testHeader",
+			args: args{header: 1, lines: []string{"status", "failed 1", "ok 2"}, reg: regexp.MustCompile("failed"), column: -1},
+			want: []string{"status", "failed 1"},
+		},
+		{
+			name: "testEscapeSequence",
+			args: args{lines: []string{"\x1b[31mred\x1b[0m", "blue"}, reg: regexp.MustCompile("^red$"), column: -1},
+			want: []string{"\x1b[31mred\x1b[0m"},
+		},
+		{
+			name: "testColumn",
+			args: args{lines: []string{"1,failed,ok", "2,ok,failed", "3,failed,failed"}, reg: regexp.MustCompile("failed"), column: 1},
+			want: []string{"1,failed,ok", "3,failed,failed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := testLineDocument(t, tt.args.header, tt.args.lines...)
+			m, err := filterDocument(src, tt.args.reg, tt.args.column)
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			got := make([]string, 0)
+			for n := 0; n < m.BufEndNum(); n++ {
+				got = append(got, m.GetLine(n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterDocument() = %v, want %v", got, tt.want)
+			}
+			if !m.BufEOF() {
+				t.Errorf("filterDocument() not EOF")
+			}
+		})
+	}
+}
+
+func Test_countMatchLines(t *testing.T) {
+	type args struct {
+		lines []string
+		limit int
+	}
+	tests := []struct {
+		name    string
+		args    args
+		wantNum int
+		wantAll bool
+	}{
+		{
+			name:    "testAll",
+			args:    args{lines: []string{"a", "b", "a"}, limit: 10},
+			wantNum: 2,
+			wantAll: true,
+		},
+		{
+			name:    "testLimit",
+			args:    args{lines: []string{"a", "b", "a"}, limit: 2},
+			wantNum: 1,
+			wantAll: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, 0, tt.args.lines...)
+			gotNum, gotAll, err := countMatchLines(context.Background(), m, regexp.MustCompile("a"), -1, tt.args.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotNum != tt.wantNum || gotAll != tt.wantAll {
+				t.Errorf("countMatchLines() = %v, %v, want %v, %v", gotNum, gotAll, tt.wantNum, tt.wantAll)
+			}
+		})
+	}
+}
+
+func Test_countMatchLinesCancel(t *testing.T) {
+	m := testLineDocument(t, 0, "a", "b", "a")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := countMatchLines(ctx, m, regexp.MustCompile("a"), -1, 10); err != ErrCancel {
+		t.Errorf("countMatchLines() error = %v, want %v", err, ErrCancel)
+	}
+}
+
+func TestDocument_filterLinesStop(t *testing.T) {
+	src := testLineDocument(t, 0, "a", "b", "a")
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	atomic.StoreInt32(&m.filtering, 1)
+	m.stopReading()
+	m.filterLines(src, regexp.MustCompile("a"), -1, src.BufEndNum())
+	if got := m.BufEndNum(); got != 0 {
+		t.Errorf("filterLines() = %d lines, want 0", got)
+	}
+	if !m.BufEOF() {
+		t.Errorf("filterLines() not EOF")
+	}
+	if atomic.LoadInt32(&m.filtering) != 0 {
+		t.Errorf("filterLines() still filtering")
+	}
+}
+
+func TestRoot_filterCancel(t *testing.T) {
+	root := testSearchRoot(t, 10)
+	before := regexp.MustCompile("line")
+	root.input.reg = before
+	root.setFilterMode()
+	root.input.value = "match"
+	root.filterPreview(root.input.value)
+	if root.input.reg == nil || root.input.reg == Searcher(before) {
+		t.Fatalf("filterPreview() reg = %v, want match", root.input.reg)
+	}
+	root.inputKeyEvent(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if root.input.reg != Searcher(before) {
+		t.Errorf("cancel reg = %v, want %v", root.input.reg, before)
+	}
+	if root.filterCancel != nil {
+		t.Errorf("cancel did not stop the preview")
+	}
+
+	root.input.reg = nil
+	root.setFilterMode()
+	root.filterPreview("match")
+	root.inputKeyEvent(tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	if root.input.reg != nil {
+		t.Errorf("cancel reg = %v, want nil", root.input.reg)
+	}
+}
+
+func TestRoot_searchDocument(t *testing.T) {
+	src := testLineDocument(t, 0, "id,status", "1,ok", "2,failed", "3,ok", "4,failed")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src.Header = 1
+	root.Screen.(tcell.SimulationScreen).SetSize(80, 25)
+	root.prepareView()
+
+	root.searchDocument(nil)
+	if root.DocumentLen() != 1 {
+		t.Fatalf("searchDocument(nil) added a document")
+	}
+
+	root.searchDocument(regexp.MustCompile("(?i)FAILED"))
+	if root.DocumentLen() != 2 {
+		t.Fatalf("searchDocument() documents = %d, want 2", root.DocumentLen())
+	}
+	m := root.Doc
+	if m == src {
+		t.Fatalf("searchDocument() did not switch to the new document")
+	}
+	<-m.eofCh
+	if want := "search:FAILED:" + src.FileName; m.FileName != want {
+		t.Errorf("searchDocument() FileName = %q, want %q", m.FileName, want)
+	}
+	if m.Header != src.Header {
+		t.Errorf("searchDocument() Header = %d, want %d", m.Header, src.Header)
+	}
+	got := make([]string, 0)
+	for n := 0; n < m.BufEndNum(); n++ {
+		got = append(got, m.GetLine(n))
+	}
+	want := []string{"id,status", "2,failed", "4,failed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("searchDocument() = %v, want %v", got, want)
+	}
+}
+
+func TestRoot_filterDimUnmatched(t *testing.T) {
+	root := testSearchRoot(t, 3, 5)
+	root.Doc.DimUnmatched = true
+	root.StyleUnmatched = ovStyle{Background: "red"}
+	root.filter("match")
+	if root.DocumentLen() != 1 {
+		t.Fatalf("filter() added a document in DimUnmatched")
+	}
+	root.draw()
+	for y := 0; y < root.statusPos; y++ {
+		lN := root.lnumber[y].line
+		_, _, style, _ := root.GetContent(root.startX, y)
+		_, bg, _ := style.Decompose()
+		want := lN != 3 && lN != 5
+		if (bg == tcell.ColorRed) != want {
+			t.Errorf("line %d unmatched = %v, want %v", lN, bg == tcell.ColorRed, want)
+		}
+	}
+}
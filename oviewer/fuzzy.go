@@ -0,0 +1,69 @@
+package oviewer
+
+import (
+	"sort"
+	"strings"
+)
+
+// fuzzyScore returns the score of how well str matches the pattern.
+// The pattern matches if its characters appear in str in order (case-insensitive).
+// A prefix match scores higher than a substring match,
+// and a substring match scores higher than a scattered match.
+func fuzzyScore(pattern string, str string) (int, bool) {
+	if pattern == "" {
+		return 0, true
+	}
+	p := strings.ToLower(pattern)
+	s := strings.ToLower(str)
+
+	switch i := strings.Index(s, p); {
+	case i == 0:
+		return 3000 - len(s), true
+	case i > 0:
+		return 2000 - i - len(s), true
+	}
+
+	pr := []rune(p)
+	n := 0
+	gap := 0
+	for _, r := range s {
+		if n == len(pr) {
+			break
+		}
+		if r == pr[n] {
+			n++
+			continue
+		}
+		if n > 0 {
+			gap++
+		}
+	}
+	if n < len(pr) {
+		return 0, false
+	}
+	return 1000 - gap - len(s), true
+}
+
+// fuzzyFilter returns the strings in list that match the pattern,
+// in order of the score.
+func fuzzyFilter(list []string, pattern string) []string {
+	type scored struct {
+		str   string
+		score int
+	}
+	matches := make([]scored, 0, len(list))
+	for _, s := range list {
+		if score, ok := fuzzyScore(pattern, s); ok {
+			matches = append(matches, scored{str: s, score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.str
+	}
+	return result
+}
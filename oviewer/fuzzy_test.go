@@ -0,0 +1,109 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_fuzzyFilter(t *testing.T) {
+	type args struct {
+		list    []string
+		pattern string
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "testEmpty",
+			args: args{list: []string{"general", "psql", "mysql"}, pattern: ""},
+			want: []string{"general", "psql", "mysql"},
+		},
+		{
+			name: "testSubstring",
+			args: args{list: []string{"general", "psql", "mysql"}, pattern: "sql"},
+			want: []string{"psql", "mysql"},
+		},
+		{
+			name: "testPrefix",
+			args: args{list: []string{"mysql", "markdown", "my"}, pattern: "my"},
+			want: []string{"my", "mysql"},
+		},
+		{
+			name: "testScattered",
+			args: args{list: []string{"general", "csvheader", "tsv"}, pattern: "chd"},
+			want: []string{"csvheader"},
+		},
+		{
+			name: "testCaseInsensitive",
+			args: args{list: []string{"Psql"}, pattern: "pS"},
+			want: []string{"Psql"},
+		},
+		{
+			name: "testNoMatch",
+			args: args{list: []string{"general"}, pattern: "xyz"},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fuzzyFilter(tt.args.list, tt.args.pattern); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fuzzyFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_candidate_match(t *testing.T) {
+	list := []string{"error", "warn", "timeout error", "info", "err"}
+	tests := []struct {
+		name string
+		str  string
+		ups  int
+		want string
+	}{
+		{
+			name: "empty",
+			str:  "",
+			ups:  1,
+			want: "err",
+		},
+		{
+			name: "most recent",
+			str:  "err",
+			ups:  1,
+			want: "err",
+		},
+		{
+			name: "cycle",
+			str:  "err",
+			ups:  2,
+			want: "timeout error",
+		},
+		{
+			name: "fuzzy",
+			str:  "tmo",
+			ups:  1,
+			want: "timeout error",
+		},
+		{
+			name: "no match",
+			str:  "xyz",
+			ups:  1,
+			want: "xyz",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &candidate{list: list, p: len(list)}
+			got := tt.str
+			for i := 0; i < tt.ups; i++ {
+				got = c.match(got).up()
+			}
+			if got != tt.want {
+				t.Errorf("candidate.match().up() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
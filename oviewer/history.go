@@ -0,0 +1,96 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// historyCandidates returns the input candidates that are saved as history.
+func (input *Input) historyCandidates() map[string]*candidate {
+	return map[string]*candidate{
+		"search":     input.SearchCandidate,
+		"goto":       input.GoCandidate,
+		"delimiter":  input.DelimiterCandidate,
+		"tabwidth":   input.TabWidthCandidate,
+		"jsonfields": input.JSONFieldsCandidate,
+		"pipe":       input.PipeCandidate,
+		"replace":    input.ReplaceCandidate,
+		"shell":      input.ShellCandidate,
+		"openfile":   input.OpenFileCandidate,
+	}
+}
+
+// loadHistory reads the input history from HistoryFile.
+func (root *Root) loadHistory() error {
+	if root.HistoryFile == "" || root.HistoryMax <= 0 {
+		return nil
+	}
+
+	buf, err := os.ReadFile(root.HistoryFile)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	history := make(map[string][]string)
+	if err := json.Unmarshal(buf, &history); err != nil {
+		return err
+	}
+
+	for name, c := range root.input.historyCandidates() {
+		for _, s := range history[name] {
+			c.list = toLast(c.list, s)
+		}
+		c.list = limitHistory(c.list, root.HistoryMax)
+		c.p = 0
+	}
+	return nil
+}
+
+// saveHistory writes the input history to HistoryFile.
+func (root *Root) saveHistory() error {
+	if root.HistoryFile == "" || root.HistoryMax <= 0 {
+		return nil
+	}
+
+	history := make(map[string][]string)
+	for name, c := range root.input.historyCandidates() {
+		history[name] = limitHistory(c.list, root.HistoryMax)
+	}
+
+	buf, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(root.HistoryFile), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(root.HistoryFile, buf, 0o600)
+}
+
+// limitHistory removes duplicates and empty entries from the list
+// and keeps only the last limit entries.
+// The newest entry is at the end of the list.
+func limitHistory(list []string, limit int) []string {
+	seen := make(map[string]bool, len(list))
+	rev := make([]string, 0, len(list))
+	for i := len(list) - 1; i >= 0 && len(rev) < limit; i-- {
+		s := list[i]
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		rev = append(rev, s)
+	}
+
+	result := make([]string, len(rev))
+	for i, s := range rev {
+		result[len(rev)-1-i] = s
+	}
+	return result
+}
@@ -0,0 +1,46 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_limitHistory(t *testing.T) {
+	type args struct {
+		list  []string
+		limit int
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "testNoChange",
+			args: args{list: []string{"a", "b", "c"}, limit: 10},
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name: "testDuplicate",
+			args: args{list: []string{"a", "b", "a", "c"}, limit: 10},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name: "testLimit",
+			args: args{list: []string{"a", "b", "c", "d"}, limit: 2},
+			want: []string{"c", "d"},
+		},
+		{
+			name: "testEmpty",
+			args: args{list: []string{"", "a", ""}, limit: 2},
+			want: []string{"a"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := limitHistory(tt.args.list, tt.args.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("limitHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package oviewer
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/mattn/go-runewidth"
@@ -15,14 +17,29 @@ type Input struct {
 
 	mode    InputMode
 	value   string
-	reg     *regexp.Regexp
+	reg     Searcher
 	cursorX int
-
-	ModeCandidate      *candidate
-	SearchCandidate    *candidate
-	GoCandidate        *candidate
-	DelimiterCandidate *candidate
-	TabWidthCandidate  *candidate
+	// regexpErr is the error of the regular expression being typed.
+	regexpErr error
+	// pasting is true while the text is pasted by the bracketed paste.
+	pasting bool
+	// pasted is the text pasted so far.
+	pasted []rune
+
+	ModeCandidate       *candidate
+	SearchCandidate     *candidate
+	GoCandidate         *candidate
+	DelimiterCandidate  *candidate
+	TabWidthCandidate   *candidate
+	JSONFieldsCandidate *candidate
+	PipeCandidate       *candidate
+	ReplaceCandidate    *candidate
+	RenameCandidate     *candidate
+	ShellCandidate      *candidate
+	OpenFileCandidate   *candidate
+
+	// hint is displayed on the right side of the input.
+	hint string
 }
 
 // InputMode represents the state of the input.
@@ -45,6 +62,26 @@ const (
 	Delimiter
 	// TabWidth is the tab number input mode.
 	TabWidth
+	// SaveBuffer is the save buffer input mode.
+	SaveBuffer
+	// Setting is the setting input mode.
+	Setting
+	// Filter is the filter input mode.
+	Filter
+	// JSONFields is the JSON fields input mode.
+	JSONFields
+	// Pipe is the pipe command input mode.
+	Pipe
+	// Replace is the replace input mode.
+	Replace
+	// Rename is the rename document input mode.
+	Rename
+	// DocSwitch is the document switch input mode.
+	DocSwitch
+	// Shell is the shell command input mode.
+	Shell
+	// OpenFile is the open file input mode.
+	OpenFile
 )
 
 // InputEvent input key events.
@@ -52,12 +89,18 @@ func (root *Root) inputEvent(ev *tcell.EventKey) {
 	// inputEvent returns input confirmed or not confirmed.
 	ok := root.inputKeyEvent(ev)
 
+	input := root.input
+	if h, isHinter := input.EventInput.(hinter); isHinter && input.mode != Normal && ev.Key() != tcell.KeyTAB {
+		input.hint = h.Hint(input.value)
+	}
+	root.checkRegexp()
+
 	// Not confirmed or canceled.
 	if !ok {
 		return
 	}
-
-	input := root.input
+	input.hint = ""
+	input.regexpErr = nil
 	// confirmed.
 	nev := input.EventInput.Confirm(input.value)
 	go func() {
@@ -68,13 +111,37 @@ func (root *Root) inputEvent(ev *tcell.EventKey) {
 	input.EventInput = newNormalInput()
 }
 
+// checkRegexp compiles the regular expression being typed in the search and filter modes,
+// and displays the error if it is invalid.
+func (root *Root) checkRegexp() {
+	input := root.input
+	input.regexpErr = nil
+	if !root.RegexpSearch {
+		return
+	}
+	if !isSearchMode(input.mode) {
+		return
+	}
+	if _, err := regexp.Compile(input.value); err != nil {
+		input.regexpErr = err
+		input.hint = err.Error()
+	}
+}
+
 // inputKeyEvent handles the keystrokes of the input.
 func (root *Root) inputKeyEvent(ev *tcell.EventKey) bool {
 	input := root.input
+	if ev.Key() != tcell.KeyTAB {
+		input.hint = ""
+	}
 
 	switch ev.Key() {
 	case tcell.KeyEscape:
+		if c, ok := input.EventInput.(canceler); ok {
+			c.Cancel()
+		}
 		input.mode = Normal
+		input.hint = ""
 		return false
 	case tcell.KeyEnter:
 		return true
@@ -133,6 +200,11 @@ func (root *Root) inputKeyEvent(ev *tcell.EventKey) bool {
 		runes := []rune(input.value)
 		input.cursorX = runeWidth(string(runes))
 	case tcell.KeyTAB:
+		if c, ok := input.EventInput.(completer); ok {
+			input.value, input.hint = c.Complete(input.value)
+			input.cursorX = runeWidth(input.value)
+			return false
+		}
 		pos := stringWidth(input.value, input.cursorX+1)
 		runes := []rune(input.value)
 		input.value = string(runes[:pos])
@@ -186,6 +258,8 @@ func runeWidth(str string) int {
 type candidate struct {
 	list []string
 	p    int
+	// matches is the candidates that match the input.
+	matches *candidate
 }
 
 // NewInput returns all the various inputs.
@@ -218,6 +292,24 @@ func NewInput() *Input {
 	i.SearchCandidate = &candidate{
 		list: []string{},
 	}
+	i.JSONFieldsCandidate = &candidate{
+		list: []string{},
+	}
+	i.PipeCandidate = &candidate{
+		list: []string{},
+	}
+	i.ReplaceCandidate = &candidate{
+		list: []string{},
+	}
+	i.RenameCandidate = &candidate{
+		list: []string{},
+	}
+	i.ShellCandidate = &candidate{
+		list: []string{},
+	}
+	i.OpenFileCandidate = &candidate{
+		list: []string{},
+	}
 	i.EventInput = &normalInput{}
 	return &i
 }
@@ -270,12 +362,37 @@ func (root *Root) setTabWidthMode() {
 	input.EventInput = newTabWidthInput(input.TabWidthCandidate)
 }
 
+func (root *Root) setSaveBufferMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = SaveBuffer
+	input.EventInput = newSaveBufferInput()
+}
+
+func (root *Root) setSettingMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Setting
+	input.EventInput = newSettingInput(&candidate{list: root.settingCandidates()})
+}
+
 func (root *Root) setGoLineMode() {
+	// The count prefix moves directly to that line.
+	if root.count > 0 {
+		root.goLine(strconv.Itoa(root.takeCount()))
+		return
+	}
 	input := root.input
 	input.value = ""
 	input.cursorX = 0
 	input.mode = Goline
-	input.EventInput = newGotoInput(input.GoCandidate)
+	g := newGotoInput(input.GoCandidate)
+	if root.Doc.SectionDelimiter != "" {
+		g.sections = root.Doc.sectionNames
+	}
+	input.EventInput = g
 }
 
 // EventInput is a generic interface for inputs.
@@ -349,12 +466,12 @@ func (s *searchInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (s *searchInput) Up(str string) string {
-	return s.clist.up()
+	return s.clist.match(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (s *searchInput) Down(str string) string {
-	return s.clist.down()
+	return s.clist.match(str).down()
 }
 
 // backSearchInput represents the back search input mode.
@@ -385,18 +502,21 @@ func (b *backSearchInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (b *backSearchInput) Up(str string) string {
-	return b.clist.up()
+	return b.clist.match(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (b *backSearchInput) Down(str string) string {
-	return b.clist.down()
+	return b.clist.match(str).down()
 }
 
 // gotoInput represents the goto input mode.
 type gotoInput struct {
 	value string
 	clist *candidate
+	// sections returns the names of the sections for the completion.
+	sections func() []string
+	cycleCompletion
 	tcell.EventTime
 }
 
@@ -405,8 +525,21 @@ func newGotoInput(clist *candidate) *gotoInput {
 	return &gotoInput{clist: clist}
 }
 
+// Complete completes the name of the section.
+func (g *gotoInput) Complete(str string) (string, string) {
+	if g.sections == nil {
+		return str, ""
+	}
+	return g.complete(str, func(s string) []string {
+		return matchSectionNames(g.sections(), s)
+	})
+}
+
 // Prompt returns the prompt string in the input field.
 func (g *gotoInput) Prompt() string {
+	if g.sections != nil {
+		return "Goto line/section:"
+	}
 	return "Goto line:"
 }
 
@@ -421,12 +554,12 @@ func (g *gotoInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (g *gotoInput) Up(str string) string {
-	return g.clist.up()
+	return g.clist.match(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (g *gotoInput) Down(str string) string {
-	return g.clist.down()
+	return g.clist.match(str).down()
 }
 
 // headerInput represents the goto input mode.
@@ -474,6 +607,8 @@ func (h *headerInput) Down(str string) string {
 type viewModeInput struct {
 	value string
 	clist *candidate
+	// matches is the candidates filtered by the input.
+	matches *candidate
 	tcell.EventTime
 }
 
@@ -495,12 +630,60 @@ func (d *viewModeInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (d *viewModeInput) Up(str string) string {
-	return d.clist.up()
+	return d.filter(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (d *viewModeInput) Down(str string) string {
-	return d.clist.down()
+	return d.filter(str).down()
+}
+
+// Complete returns the next candidate that matches the input.
+func (d *viewModeInput) Complete(str string) (string, string) {
+	c := d.filter(str)
+	if len(c.list) == 0 {
+		return str, "no match"
+	}
+	s := c.down()
+	return s, fmt.Sprintf("[%d/%d]", c.p+1, len(c.list))
+}
+
+// Hint returns the candidates that match the input.
+func (d *viewModeInput) Hint(str string) string {
+	_, query := splitModePrefix(str)
+	matches := fuzzyFilter(d.clist.list, query)
+	if len(matches) == 0 {
+		return "no match"
+	}
+	return strings.Join(matches, " ")
+}
+
+// filter returns the candidates filtered by the input with fuzzy matching.
+// If the input is one of the filtered candidates, it keeps cycling through them.
+func (d *viewModeInput) filter(str string) *candidate {
+	if d.matches != nil {
+		for _, s := range d.matches.list {
+			if s == str {
+				return d.matches
+			}
+		}
+	}
+
+	prefix, query := splitModePrefix(str)
+	matches := fuzzyFilter(d.clist.list, query)
+	for i := range matches {
+		matches[i] = prefix + matches[i]
+	}
+	d.matches = &candidate{list: matches, p: -1}
+	return d.matches
+}
+
+// splitModePrefix splits the "+" prefix of the view mode input.
+func splitModePrefix(str string) (string, string) {
+	if strings.HasPrefix(str, "+") {
+		return "+", str[1:]
+	}
+	return "", str
 }
 
 // delimiterInput represents the delimiter input mode.
@@ -531,12 +714,12 @@ func (d *delimiterInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (d *delimiterInput) Up(str string) string {
-	return d.clist.up()
+	return d.clist.match(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (d *delimiterInput) Down(str string) string {
-	return d.clist.down()
+	return d.clist.match(str).down()
 }
 
 // tabWidthInput represents the TABWidth input mode.
@@ -567,12 +750,80 @@ func (t *tabWidthInput) Confirm(str string) tcell.Event {
 
 // Up returns strings when the up key is pressed during input.
 func (t *tabWidthInput) Up(str string) string {
-	return t.clist.up()
+	return t.clist.match(str).up()
 }
 
 // Down returns strings when the down key is pressed during input.
 func (t *tabWidthInput) Down(str string) string {
-	return t.clist.down()
+	return t.clist.match(str).down()
+}
+
+// saveBufferInput represents the save buffer input mode.
+type saveBufferInput struct {
+	value string
+	pathCompletion
+	tcell.EventTime
+}
+
+// newSaveBufferInput returns SaveBufferInput.
+func newSaveBufferInput() *saveBufferInput {
+	return &saveBufferInput{}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *saveBufferInput) Prompt() string {
+	return "(Save)file:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *saveBufferInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *saveBufferInput) Up(str string) string {
+	return str
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *saveBufferInput) Down(str string) string {
+	return str
+}
+
+// settingInput represents the setting input mode.
+type settingInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newSettingInput returns SettingInput.
+func newSettingInput(clist *candidate) *settingInput {
+	return &settingInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *settingInput) Prompt() string {
+	return "Set:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *settingInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *settingInput) Up(str string) string {
+	return s.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *settingInput) Down(str string) string {
+	return s.clist.match(str).down()
 }
 
 func (c *candidate) up() string {
@@ -603,6 +854,35 @@ func (c *candidate) down() string {
 	return c.list[c.p]
 }
 
+// match returns the candidates that match str with fuzzy matching, in the order of the list.
+// If str is empty, it returns all candidates.
+// If str is one of the matched candidates, it keeps cycling through them.
+func (c *candidate) match(str string) *candidate {
+	if str == "" {
+		return c
+	}
+	if c.matches != nil {
+		for _, s := range c.matches.list {
+			if s == str {
+				return c.matches
+			}
+		}
+	}
+
+	list := make([]string, 0, len(c.list))
+	for _, s := range c.list {
+		if _, ok := fuzzyScore(str, s); ok {
+			list = append(list, s)
+		}
+	}
+	if len(list) == 0 {
+		// Keep the input if nothing matches.
+		list = append(list, str)
+	}
+	c.matches = &candidate{list: list, p: len(list)}
+	return c.matches
+}
+
 func toLast(list []string, s string) []string {
 	if len(s) == 0 {
 		return list
@@ -0,0 +1,262 @@
+package oviewer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// jsonFieldsDelimiter is the column delimiter of the JSON fields document.
+const jsonFieldsDelimiter = "|"
+
+// jsonFieldsDelimiterReplacer replaces the delimiter in the values,
+// so that a value is not split into columns.
+var jsonFieldsDelimiterReplacer = strings.NewReplacer(jsonFieldsDelimiter, "¦")
+
+// jsonFieldsInput represents the JSON fields input mode.
+type jsonFieldsInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newJSONFieldsInput returns jsonFieldsInput.
+func newJSONFieldsInput(clist *candidate) *jsonFieldsInput {
+	return &jsonFieldsInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (j *jsonFieldsInput) Prompt() string {
+	return "JSON fields:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (j *jsonFieldsInput) Confirm(str string) tcell.Event {
+	j.value = str
+	j.clist.list = toLast(j.clist.list, str)
+	j.clist.p = 0
+	j.SetEventNow()
+	return j
+}
+
+// Up returns strings when the up key is pressed during input.
+func (j *jsonFieldsInput) Up(str string) string {
+	return j.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (j *jsonFieldsInput) Down(str string) string {
+	return j.clist.match(str).down()
+}
+
+// setJSONFieldsMode sets the JSON fields input mode.
+// In the JSON fields document, it moves to the raw line instead.
+func (root *Root) setJSONFieldsMode() {
+	if root.Doc.jsonSource != nil {
+		root.jsonRawLine()
+		return
+	}
+
+	input := root.input
+	if keys := jsonKeys(root.Doc.GetLine(root.Doc.topLN + root.Doc.Header)); keys != "" {
+		input.JSONFieldsCandidate.list = toLast(input.JSONFieldsCandidate.list, keys)
+	}
+	input.value = ""
+	input.cursorX = 0
+	input.mode = JSONFields
+	input.EventInput = newJSONFieldsInput(input.JSONFieldsCandidate)
+}
+
+// jsonFields adds a document of the fields extracted from the JSON lines.
+// The document is a snapshot of the lines read so far,
+// and does not follow the lines appended to the source document.
+func (root *Root) jsonFields(str string) {
+	fields := splitFields(str)
+	if len(fields) == 0 {
+		return
+	}
+
+	src := root.Doc
+	m, err := jsonDocument(src, fields)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	m.FileName = fmt.Sprintf("json:%s:%s", strings.Join(fields, ","), src.FileName)
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	m.Header = 1
+	m.ColumnMode = true
+	m.ColumnDelimiter = jsonFieldsDelimiter
+	m.WrapMode = false
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("JSON fields:%s %d lines", strings.Join(fields, ","), m.BufEndNum()-m.Header))
+}
+
+// jsonRawLine moves to the raw line of the current line of the JSON fields document.
+func (root *Root) jsonRawLine() {
+	m := root.Doc
+	n := m.topLN + m.Header
+	if n < 0 || n >= len(m.jsonSourceLN) || m.jsonSourceLN[n] < 0 {
+		return
+	}
+	lN := m.jsonSourceLN[n]
+
+	root.mu.RLock()
+	docNum := -1
+	for i, doc := range root.DocList {
+		if doc == m.jsonSource {
+			docNum = i
+		}
+	}
+	root.mu.RUnlock()
+	if docNum < 0 {
+		root.setMessage("The source document is closed")
+		return
+	}
+	root.setDocumentNum(docNum)
+	root.jumpLine(lN)
+	root.setMessage(fmt.Sprintf("Moved to the raw line %d", lN+1))
+}
+
+// splitFields returns the comma-separated field names.
+func splitFields(str string) []string {
+	fields := make([]string, 0)
+	for _, f := range strings.Split(str, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// jsonKeys returns the comma-separated top-level keys of the JSON line.
+func jsonKeys(line string) string {
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+// jsonProject returns the values of the fields of the JSON line.
+// A field can be a dot-separated path to a nested object.
+// ok is false if the line is not a JSON object.
+func jsonProject(line string, fields []string) ([]string, bool) {
+	var obj map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.UseNumber()
+	if err := dec.Decode(&obj); err != nil || obj == nil {
+		return nil, false
+	}
+
+	values := make([]string, len(fields))
+	for i, f := range fields {
+		var v interface{} = obj
+		for _, key := range strings.Split(f, ".") {
+			o, ok := v.(map[string]interface{})
+			if !ok {
+				v = nil
+				break
+			}
+			v = o[key]
+		}
+		values[i] = jsonValue(v)
+	}
+	return values, true
+}
+
+// jsonValue returns the string to display the JSON value.
+func jsonValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.NewReplacer("\n", "\\n", "\t", "\\t").Replace(v)
+	case json.Number:
+		return v.String()
+	default:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Sprint(v)
+		}
+		return strings.TrimRight(buf.String(), "\n")
+	}
+}
+
+// jsonDocument returns a new document of the fields of the JSON lines of src,
+// aligned in columns with the field names as the header.
+// The lines that are not JSON objects are left as they are.
+// The delimiter in the values is replaced by jsonFieldsDelimiterReplacer.
+// Only the lines of src read so far are included.
+func jsonDocument(src *Document, fields []string) (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+
+	end := src.BufEndNum()
+	rows := make([][]string, 0, end+1)
+	rows = append(rows, jsonEscapeValues(fields))
+	sourceLN := make([]int, 0, end+1)
+	sourceLN = append(sourceLN, -1)
+	raw := make(map[int]string)
+	widths := make([]int, len(fields))
+	for i, f := range fields {
+		widths[i] = runewidth.StringWidth(f)
+	}
+	for n := 0; n < end; n++ {
+		line := src.GetLine(n)
+		values, ok := jsonProject(line, fields)
+		if !ok {
+			raw[len(rows)] = line
+		}
+		values = jsonEscapeValues(values)
+		for i, v := range values {
+			widths[i] = max(widths[i], runewidth.StringWidth(v))
+		}
+		rows = append(rows, values)
+		sourceLN = append(sourceLN, n)
+	}
+
+	for n, values := range rows {
+		if line, ok := raw[n]; ok {
+			m.append(line)
+			continue
+		}
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = v + strings.Repeat(" ", widths[i]-runewidth.StringWidth(v))
+		}
+		m.append(strings.TrimRight(strings.Join(cells, " "+jsonFieldsDelimiter+" "), " "))
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	m.jsonSource = src
+	m.jsonSourceLN = sourceLN
+	return m, nil
+}
+
+// jsonEscapeValues returns the values with the delimiter replaced.
+func jsonEscapeValues(values []string) []string {
+	escaped := make([]string, len(values))
+	for i, v := range values {
+		escaped[i] = jsonFieldsDelimiterReplacer.Replace(v)
+	}
+	return escaped
+}
@@ -0,0 +1,97 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_jsonProject(t *testing.T) {
+	type args struct {
+		line   string
+		fields []string
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   []string
+		wantOk bool
+	}{
+		{
+			name:   "fields",
+			args:   args{line: `{"level":"info","msg":"started","pid":123}`, fields: []string{"msg", "level"}},
+			want:   []string{"started", "info"},
+			wantOk: true,
+		},
+		{
+			name:   "missing",
+			args:   args{line: `{"level":"info"}`, fields: []string{"level", "msg"}},
+			want:   []string{"info", ""},
+			wantOk: true,
+		},
+		{
+			name:   "nested",
+			args:   args{line: `{"http":{"status":404,"path":"/a"}}`, fields: []string{"http.status", "http.path.x"}},
+			want:   []string{"404", ""},
+			wantOk: true,
+		},
+		{
+			name:   "number",
+			args:   args{line: `{"id":12345678901234567890,"f":1.50}`, fields: []string{"id", "f"}},
+			want:   []string{"12345678901234567890", "1.50"},
+			wantOk: true,
+		},
+		{
+			name:   "object",
+			args:   args{line: `{"tags":["a","<b>"],"ok":true}`, fields: []string{"tags", "ok"}},
+			want:   []string{`["a","<b>"]`, "true"},
+			wantOk: true,
+		},
+		{
+			name:   "notJSON",
+			args:   args{line: "plain text", fields: []string{"msg"}},
+			want:   nil,
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := jsonProject(tt.args.line, tt.args.fields)
+			if gotOk != tt.wantOk {
+				t.Errorf("jsonProject() ok = %v, want %v", gotOk, tt.wantOk)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("jsonProject() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jsonDocument(t *testing.T) {
+	src := testLineDocument(t, 0,
+		`{"level":"info","msg":"started"}`,
+		"not json",
+		`{"level":"error","msg":"x"}`,
+		`{"level":"warn","msg":"a|b"}`,
+	)
+	m, err := jsonDocument(src, []string{"level", "msg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]string, 0)
+	for n := 0; n < m.BufEndNum(); n++ {
+		got = append(got, m.GetLine(n))
+	}
+	want := []string{
+		"level | msg",
+		"info  | started",
+		"not json",
+		"error | x",
+		"warn  | a¦b",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("jsonDocument() = %q, want %q", got, want)
+	}
+	if wantLN := []int{-1, 0, 1, 2, 3}; !reflect.DeepEqual(m.jsonSourceLN, wantLN) {
+		t.Errorf("jsonDocument() jsonSourceLN = %v, want %v", m.jsonSourceLN, wantLN)
+	}
+}
@@ -11,90 +11,182 @@ import (
 )
 
 const (
-	actionExit           = "exit"
-	actionCancel         = "cancel"
-	actionWriteExit      = "write_exit"
-	actionSync           = "sync"
-	actionFollow         = "follow_mode"
-	actionFollowAll      = "follow_all"
-	actionHelp           = "help"
-	actionLogDoc         = "logdoc"
-	actionMoveDown       = "down"
-	actionMoveUp         = "up"
-	actionMoveTop        = "top"
-	actionMoveLeft       = "left"
-	actionMoveRight      = "right"
-	actionMoveHfLeft     = "half_left"
-	actionMoveHfRight    = "half_right"
-	actionMoveBottom     = "bottom"
-	actionMovePgUp       = "page_up"
-	actionMovePgDn       = "page_down"
-	actionMoveHfUp       = "page_half_up"
-	actionMoveHfDn       = "page_half_down"
-	actionMark           = "mark"
-	actionMoveMark       = "next_mark"
-	actionMovePrevMark   = "previous_mark"
-	actionViewMode       = "set_view_mode"
-	actionAlternate      = "alter_rows_mode"
-	actionLineNumMode    = "line_number_mode"
-	actionSearch         = "search"
-	actionWrap           = "wrap_mode"
-	actionColumnMode     = "column_mode"
-	actionBackSearch     = "backsearch"
-	actionDelimiter      = "delimiter"
-	actionHeader         = "header"
-	actionTabWidth       = "tabwidth"
-	actionGoLine         = "goto"
-	actionNextSearch     = "next_search"
-	actionNextBackSearch = "next_backsearch"
-	actionNextDoc        = "next_doc"
-	actionPreviousDoc    = "previous_doc"
-	actionCloseDoc       = "close_doc"
-	actionToggleMouse    = "toggle_mouse"
+	actionExit             = "exit"
+	actionCancel           = "cancel"
+	actionWriteExit        = "write_exit"
+	actionSync             = "sync"
+	actionFollow           = "follow_mode"
+	actionFollowAll        = "follow_all"
+	actionHelp             = "help"
+	actionLogDoc           = "logdoc"
+	actionMoveDown         = "down"
+	actionMoveUp           = "up"
+	actionMoveTop          = "top"
+	actionMoveLeft         = "left"
+	actionMoveRight        = "right"
+	actionMoveHfLeft       = "half_left"
+	actionMoveHfRight      = "half_right"
+	actionMoveBottom       = "bottom"
+	actionMovePgUp         = "page_up"
+	actionMovePgDn         = "page_down"
+	actionMoveHfUp         = "page_half_up"
+	actionMoveHfDn         = "page_half_down"
+	actionMark             = "mark"
+	actionMoveMark         = "next_mark"
+	actionMovePrevMark     = "previous_mark"
+	actionViewMode         = "set_view_mode"
+	actionAlternate        = "alter_rows_mode"
+	actionLineNumMode      = "line_number_mode"
+	actionSearch           = "search"
+	actionWrap             = "wrap_mode"
+	actionColumnMode       = "column_mode"
+	actionBackSearch       = "backsearch"
+	actionDelimiter        = "delimiter"
+	actionHeader           = "header"
+	actionTabWidth         = "tabwidth"
+	actionGoLine           = "goto"
+	actionNextSearch       = "next_search"
+	actionNextBackSearch   = "next_backsearch"
+	actionNextDoc          = "next_doc"
+	actionPreviousDoc      = "previous_doc"
+	actionCloseDoc         = "close_doc"
+	actionToggleMouse      = "toggle_mouse"
+	actionSaveBuffer       = "save_buffer"
+	actionSettings         = "settings"
+	actionSetOption        = "set_option"
+	actionWriteConfig      = "write_config"
+	actionReloadConfig     = "reload_config"
+	actionDimUnmatched     = "dim_unmatched"
+	actionSearchWrap       = "search_wrap"
+	actionFilter           = "filter"
+	actionColumnSearch     = "column_search"
+	actionSort             = "sort"
+	actionSortType         = "sort_type"
+	actionTruncate         = "truncate_mode"
+	actionJSONFields       = "json_fields"
+	actionMerge            = "merge"
+	actionDiff             = "diff"
+	actionNextFile         = "next_file"
+	actionPreviousFile     = "previous_file"
+	actionPipe             = "pipe"
+	actionSearchDoc        = "search_document"
+	actionSelectLine       = "select_line"
+	actionNextError        = "next_error"
+	actionPreviousError    = "previous_error"
+	actionOpenError        = "open_error"
+	actionReplace          = "replace"
+	actionYankLine         = "yank_line"
+	actionYankCell         = "yank_cell"
+	actionReload           = "reload"
+	actionRenameDoc        = "rename_doc"
+	actionMoveDocLeft      = "move_doc_left"
+	actionMoveDocRight     = "move_doc_right"
+	actionSortDocs         = "sort_docs"
+	actionDocSwitch        = "doc_switch"
+	actionCloseOtherDocs   = "close_other_docs"
+	actionCloseDerivedDocs = "close_derived_docs"
+	actionScrollBind       = "toggle_scroll_bind"
+	actionTrimBuffer       = "trim_buffer"
+	actionSectionSearch    = "section_search"
+	actionPinHighlight     = "pin_highlight"
+	actionClearPinned      = "clear_pinned"
+	actionShell            = "shell"
+	actionSuspend          = "suspend"
+	actionMonochrome       = "monochrome"
+	actionDebug            = "debug"
+	actionRecord           = "record"
+	actionSectionReport    = "section_report"
+	actionOpenFile         = "open_file"
 )
 
 func (root *Root) setHandler() map[string]func() {
 	return map[string]func(){
-		actionExit:           root.Quit,
-		actionCancel:         root.Cancel,
-		actionWriteExit:      root.WriteQuit,
-		actionSync:           root.ViewSync,
-		actionFollow:         root.toggleFollowMode,
-		actionFollowAll:      root.toggleFollowAll,
-		actionHelp:           root.Help,
-		actionLogDoc:         root.logDisplay,
-		actionMoveDown:       root.moveDown,
-		actionMoveUp:         root.moveUp,
-		actionMoveTop:        root.moveTop,
-		actionMoveBottom:     root.moveBottom,
-		actionMovePgUp:       root.movePgUp,
-		actionMovePgDn:       root.movePgDn,
-		actionMoveHfUp:       root.moveHfUp,
-		actionMoveHfDn:       root.moveHfDn,
-		actionMoveLeft:       root.moveLeft,
-		actionMoveRight:      root.moveRight,
-		actionMoveHfLeft:     root.moveHfLeft,
-		actionMoveHfRight:    root.moveHfRight,
-		actionMoveMark:       root.markNext,
-		actionMovePrevMark:   root.markPrev,
-		actionViewMode:       root.setViewInputMode,
-		actionWrap:           root.toggleWrapMode,
-		actionColumnMode:     root.toggleColumnMode,
-		actionAlternate:      root.toggleAlternateRows,
-		actionLineNumMode:    root.toggleLineNumMode,
-		actionMark:           root.markLineNum,
-		actionSearch:         root.setSearchMode,
-		actionBackSearch:     root.setBackSearchMode,
-		actionDelimiter:      root.setDelimiterMode,
-		actionHeader:         root.setHeaderMode,
-		actionTabWidth:       root.setTabWidthMode,
-		actionGoLine:         root.setGoLineMode,
-		actionNextSearch:     root.eventNextSearch,
-		actionNextBackSearch: root.eventNextBackSearch,
-		actionNextDoc:        root.nextDoc,
-		actionPreviousDoc:    root.previousDoc,
-		actionCloseDoc:       root.closeDocument,
-		actionToggleMouse:    root.toggleMouse,
+		actionExit:             root.Quit,
+		actionCancel:           root.Cancel,
+		actionWriteExit:        root.WriteQuit,
+		actionSync:             root.ViewSync,
+		actionFollow:           root.toggleFollowMode,
+		actionFollowAll:        root.toggleFollowAll,
+		actionHelp:             root.Help,
+		actionLogDoc:           root.logDisplay,
+		actionMoveDown:         root.moveDown,
+		actionMoveUp:           root.moveUp,
+		actionMoveTop:          root.moveTop,
+		actionMoveBottom:       root.moveBottom,
+		actionMovePgUp:         root.movePgUp,
+		actionMovePgDn:         root.movePgDn,
+		actionMoveHfUp:         root.moveHfUp,
+		actionMoveHfDn:         root.moveHfDn,
+		actionMoveLeft:         root.moveLeft,
+		actionMoveRight:        root.moveRight,
+		actionMoveHfLeft:       root.moveHfLeft,
+		actionMoveHfRight:      root.moveHfRight,
+		actionMoveMark:         root.markNext,
+		actionMovePrevMark:     root.markPrev,
+		actionViewMode:         root.setViewInputMode,
+		actionWrap:             root.toggleWrapMode,
+		actionColumnMode:       root.toggleColumnMode,
+		actionAlternate:        root.toggleAlternateRows,
+		actionLineNumMode:      root.toggleLineNumMode,
+		actionMark:             root.markLineNum,
+		actionSearch:           root.setSearchMode,
+		actionBackSearch:       root.setBackSearchMode,
+		actionDelimiter:        root.setDelimiterMode,
+		actionHeader:           root.setHeaderMode,
+		actionTabWidth:         root.setTabWidthMode,
+		actionGoLine:           root.setGoLineMode,
+		actionNextSearch:       root.eventNextSearch,
+		actionNextBackSearch:   root.eventNextBackSearch,
+		actionNextDoc:          root.nextDoc,
+		actionPreviousDoc:      root.previousDoc,
+		actionCloseDoc:         root.closeDocument,
+		actionToggleMouse:      root.toggleMouse,
+		actionSaveBuffer:       root.setSaveBufferMode,
+		actionSettings:         root.settings,
+		actionSetOption:        root.setSettingMode,
+		actionWriteConfig:      root.writeConfig,
+		actionReloadConfig:     root.ReloadConfig,
+		actionDimUnmatched:     root.toggleDimUnmatched,
+		actionSearchWrap:       root.toggleSearchWrap,
+		actionFilter:           root.setFilterMode,
+		actionColumnSearch:     root.toggleColumnSearch,
+		actionSort:             root.sortDocument,
+		actionSortType:         root.cycleSortType,
+		actionTruncate:         root.toggleTruncateMode,
+		actionJSONFields:       root.setJSONFieldsMode,
+		actionMerge:            root.mergeDocuments,
+		actionDiff:             root.diffDocuments,
+		actionNextFile:         root.nextFile,
+		actionPreviousFile:     root.previousFile,
+		actionPipe:             root.setPipeMode,
+		actionSearchDoc:        root.lastSearchDocument,
+		actionSelectLine:       root.toggleSelectLine,
+		actionNextError:        root.nextError,
+		actionPreviousError:    root.previousError,
+		actionOpenError:        root.openError,
+		actionReplace:          root.setReplaceMode,
+		actionYankLine:         root.yankLine,
+		actionYankCell:         root.yankCell,
+		actionReload:           root.reload,
+		actionRenameDoc:        root.setRenameMode,
+		actionMoveDocLeft:      root.moveDocLeft,
+		actionMoveDocRight:     root.moveDocRight,
+		actionSortDocs:         root.sortDocuments,
+		actionDocSwitch:        root.setDocSwitchMode,
+		actionCloseOtherDocs:   root.closeOtherDocuments,
+		actionCloseDerivedDocs: root.closeDerivedDocuments,
+		actionScrollBind:       root.toggleScrollBind,
+		actionTrimBuffer:       root.trimBuffer,
+		actionSectionSearch:    root.toggleSectionSearch,
+		actionPinHighlight:     root.pinHighlight,
+		actionClearPinned:      root.clearPinned,
+		actionShell:            root.setShellMode,
+		actionSuspend:          root.suspend,
+		actionMonochrome:       root.toggleMonochrome,
+		actionDebug:            root.toggleDebug,
+		actionRecord:           root.record,
+		actionSectionReport:    root.sectionReport,
+		actionOpenFile:         root.setOpenFileMode,
 	}
 }
 
@@ -103,48 +195,7 @@ type KeyBind map[string][]string
 
 // GetKeyBinds returns the current key mapping.
 func GetKeyBinds(bind map[string][]string) map[string][]string {
-	keyBind := map[string][]string{
-		actionExit:           {"Escape", "q"},
-		actionCancel:         {"ctrl+c"},
-		actionWriteExit:      {"Q"},
-		actionSync:           {"ctrl+l"},
-		actionFollow:         {"ctrl+f"},
-		actionFollowAll:      {"ctrl+a"},
-		actionHelp:           {"h"},
-		actionLogDoc:         {"ctrl+alt+e"},
-		actionMoveDown:       {"Enter", "Down", "ctrl+N"},
-		actionMoveUp:         {"Up", "ctrl+p"},
-		actionMoveTop:        {"Home"},
-		actionMoveBottom:     {"End"},
-		actionMovePgUp:       {"PageUp", "ctrl+b"},
-		actionMovePgDn:       {"PageDown", "ctrl+v"},
-		actionMoveHfUp:       {"ctrl+u"},
-		actionMoveHfDn:       {"ctrl+d"},
-		actionMoveLeft:       {"left"},
-		actionMoveRight:      {"right"},
-		actionMoveHfLeft:     {"ctrl+left"},
-		actionMoveHfRight:    {"ctrl+right"},
-		actionMoveMark:       {">"},
-		actionMovePrevMark:   {"<"},
-		actionViewMode:       {"p", "P"},
-		actionWrap:           {"w", "W"},
-		actionColumnMode:     {"c"},
-		actionAlternate:      {"C"},
-		actionLineNumMode:    {"G"},
-		actionMark:           {"m"},
-		actionSearch:         {"/"},
-		actionBackSearch:     {"?"},
-		actionDelimiter:      {"d"},
-		actionHeader:         {"H"},
-		actionTabWidth:       {"t"},
-		actionGoLine:         {"g"},
-		actionNextSearch:     {"n"},
-		actionNextBackSearch: {"N"},
-		actionNextDoc:        {"]"},
-		actionPreviousDoc:    {"["},
-		actionCloseDoc:       {"ctrl+k"},
-		actionToggleMouse:    {"ctrl+alt+r"},
-	}
+	keyBind := defaultKeyBinds()
 
 	for k, v := range bind {
 		keyBind[k] = v
@@ -153,8 +204,145 @@ func GetKeyBinds(bind map[string][]string) map[string][]string {
 	return keyBind
 }
 
+// defaultKeyBinds returns the default key mapping.
+func defaultKeyBinds() map[string][]string {
+	return map[string][]string{
+		actionExit:             {"Escape", "q"},
+		actionCancel:           {"ctrl+c"},
+		actionWriteExit:        {"Q"},
+		actionSync:             {"ctrl+l"},
+		actionFollow:           {"ctrl+f"},
+		actionFollowAll:        {"ctrl+a"},
+		actionHelp:             {"h"},
+		actionLogDoc:           {"ctrl+alt+e"},
+		actionMoveDown:         {"Enter", "Down", "ctrl+N"},
+		actionMoveUp:           {"Up", "ctrl+p"},
+		actionMoveTop:          {"Home"},
+		actionMoveBottom:       {"End"},
+		actionMovePgUp:         {"PageUp", "ctrl+b"},
+		actionMovePgDn:         {"PageDown", "ctrl+v"},
+		actionMoveHfUp:         {"ctrl+u"},
+		actionMoveHfDn:         {"ctrl+d"},
+		actionMoveLeft:         {"left"},
+		actionMoveRight:        {"right"},
+		actionMoveHfLeft:       {"ctrl+left"},
+		actionMoveHfRight:      {"ctrl+right"},
+		actionMoveMark:         {">"},
+		actionMovePrevMark:     {"<"},
+		actionViewMode:         {"p", "P"},
+		actionWrap:             {"w", "W"},
+		actionColumnMode:       {"c"},
+		actionAlternate:        {"C"},
+		actionLineNumMode:      {"G"},
+		actionMark:             {"m"},
+		actionSearch:           {"/"},
+		actionBackSearch:       {"?"},
+		actionDelimiter:        {"d"},
+		actionHeader:           {"H"},
+		actionTabWidth:         {"t"},
+		actionGoLine:           {"g"},
+		actionNextSearch:       {"n"},
+		actionNextBackSearch:   {"N"},
+		actionNextDoc:          {"]"},
+		actionPreviousDoc:      {"["},
+		actionCloseDoc:         {"ctrl+k"},
+		actionToggleMouse:      {"ctrl+alt+r"},
+		actionSaveBuffer:       {"S"},
+		actionSettings:         {"O"},
+		actionSetOption:        {"="},
+		actionWriteConfig:      {"ctrl+alt+w"},
+		actionReloadConfig:     {"ctrl+alt+l"},
+		actionDimUnmatched:     {"&"},
+		actionSearchWrap:       {"ctrl+alt+s"},
+		actionFilter:           {"F"},
+		actionColumnSearch:     {"alt+/"},
+		actionSort:             {"alt+s"},
+		actionSortType:         {"alt+t"},
+		actionTruncate:         {"T"},
+		actionJSONFields:       {"alt+j"},
+		actionMerge:            {"alt+m"},
+		actionDiff:             {"alt+d"},
+		actionNextFile:         {"}"},
+		actionPreviousFile:     {"{"},
+		actionPipe:             {"|"},
+		actionSearchDoc:        {"alt+o"},
+		actionSelectLine:       {"x"},
+		actionNextError:        {"alt+n"},
+		actionPreviousError:    {"alt+p"},
+		actionOpenError:        {"alt+e"},
+		actionReplace:          {"alt+r"},
+		actionYankLine:         {"y"},
+		actionYankCell:         {"Y"},
+		actionReload:           {"R"},
+		actionRenameDoc:        {"alt+c"},
+		actionMoveDocLeft:      {"alt+Left"},
+		actionMoveDocRight:     {"alt+Right"},
+		actionSortDocs:         {"ctrl+alt+o"},
+		actionDocSwitch:        {"alt+l"},
+		actionCloseOtherDocs:   {"ctrl+alt+k"},
+		actionCloseDerivedDocs: {"alt+k"},
+		actionScrollBind:       {"ctrl+alt+b"},
+		actionTrimBuffer:       {"alt+x"},
+		actionSectionSearch:    {"alt+h"},
+		actionPinHighlight:     {"alt+i"},
+		actionClearPinned:      {"alt+u"},
+		actionShell:            {"!"},
+		actionSuspend:          {"ctrl+z"},
+		actionMonochrome:       {"alt+z"},
+		actionDebug:            {"ctrl+alt+d"},
+		actionRecord:           {"alt+T"},
+		actionSectionReport:    {"alt+C"},
+		actionOpenFile:         {"alt+O"},
+	}
+}
+
+// restrictedActions is a list of actions disabled in the restricted mode.
+// They run commands, read or write files, or stop the process on the host.
+var restrictedActions = map[string]bool{
+	actionPipe:         true,
+	actionShell:        true,
+	actionSuspend:      true,
+	actionOpenFile:     true,
+	actionOpenError:    true,
+	actionSaveBuffer:   true,
+	actionWriteConfig:  true,
+	actionReloadConfig: true,
+}
+
+// restrictKeyBinds removes the restricted actions from the key bindings.
+func restrictKeyBinds(keyBind map[string][]string) {
+	for a := range restrictedActions {
+		delete(keyBind, a)
+	}
+}
+
+// restricted returns true with the message if the restricted mode disallows the action.
+// The restricted actions are not bound to the keys, and refused here in case they are reached otherwise.
+func (root *Root) restricted() bool {
+	if !root.Restricted {
+		return false
+	}
+	root.setMessage("not allowed in restricted mode")
+	return true
+}
+
+// repeatActions is a list of actions repeated by the count prefix.
+var repeatActions = map[string]bool{
+	actionMoveDown:    true,
+	actionMoveUp:      true,
+	actionMoveLeft:    true,
+	actionMoveRight:   true,
+	actionMoveHfLeft:  true,
+	actionMoveHfRight: true,
+	actionMovePgUp:    true,
+	actionMovePgDn:    true,
+	actionMoveHfUp:    true,
+	actionMoveHfDn:    true,
+}
+
 func (root *Root) setKeyBind(keyBind map[string][]string) error {
 	c := root.keyConfig
+	chords := newChordBinds()
 
 	actionHandlers := root.setHandler()
 
@@ -163,7 +351,19 @@ func (root *Root) setKeyBind(keyBind map[string][]string) error {
 		if handler == nil {
 			return fmt.Errorf("%w for [%s] unknown action", ErrFailedKeyBind, a)
 		}
+		if repeatActions[a] {
+			handler = root.repeat(handler)
+		}
+		if smoothActions[a] {
+			handler = root.smoothScroll(handler)
+		}
 		for _, k := range keys {
+			if isChord(k) {
+				if err := chords.set(k, handler); err != nil {
+					return fmt.Errorf("%w [%s] for %s: %s", ErrFailedKeyBind, k, a, err)
+				}
+				continue
+			}
 			mod, key, ch, err := cbind.Decode(k)
 			if err != nil {
 				return fmt.Errorf("%w [%s] for %s: %s", ErrFailedKeyBind, k, a, err)
@@ -179,6 +379,7 @@ func (root *Root) setKeyBind(keyBind map[string][]string) error {
 			}
 		}
 	}
+	root.chords = chords
 	return nil
 }
 
@@ -189,69 +390,207 @@ func wrapEventHandler(f func()) func(_ *tcell.EventKey) *tcell.EventKey {
 	}
 }
 
-func (root *Root) keyCapture(ev *tcell.EventKey) bool {
-	root.keyConfig.Capture(ev)
-	return true
+// repeat returns a function that calls f the number of times of the count prefix.
+func (root *Root) repeat(f func()) func() {
+	return func() {
+		n := root.takeCount()
+		for i := 0; i < n; i++ {
+			f()
+		}
+	}
 }
 
-// KeyBindString returns keybind as a string for help.
-func KeyBindString(k KeyBind) string {
-	var b bytes.Buffer
-	fmt.Fprintf(&b, "\n\tKey binding\n\n")
-	k.writeKeyBind(&b, actionExit, "quit")
-	k.writeKeyBind(&b, actionCancel, "cancel")
-	k.writeKeyBind(&b, actionWriteExit, "output screen and quit")
-	k.writeKeyBind(&b, actionHelp, "display help screen")
-	k.writeKeyBind(&b, actionLogDoc, "display log screen")
-	k.writeKeyBind(&b, actionSync, "screen sync")
-	k.writeKeyBind(&b, actionFollow, "follow mode toggle")
-	k.writeKeyBind(&b, actionFollowAll, "follow all mode toggle")
-	k.writeKeyBind(&b, actionToggleMouse, "enable/disable mouse")
-	k.writeKeyBind(&b, actionCloseDoc, "close current document")
+// maxCount is the maximum value of the count prefix.
+const maxCount = 1000000
 
-	fmt.Fprintf(&b, "\n\tMoving\n\n")
-	k.writeKeyBind(&b, actionMoveDown, "forward by one line")
-	k.writeKeyBind(&b, actionMoveUp, "backward by one line")
-	k.writeKeyBind(&b, actionMoveTop, "go to begin of line")
-	k.writeKeyBind(&b, actionMoveBottom, "go to end of line")
-	k.writeKeyBind(&b, actionMovePgDn, "forward by page")
-	k.writeKeyBind(&b, actionMovePgUp, "backward by page")
-	k.writeKeyBind(&b, actionMoveHfDn, "forward a half page")
-	k.writeKeyBind(&b, actionMoveHfUp, "backward a half page")
-	k.writeKeyBind(&b, actionMoveLeft, "scroll to left")
-	k.writeKeyBind(&b, actionMoveRight, "scroll to right")
-	k.writeKeyBind(&b, actionMoveHfLeft, "scroll left half screen")
-	k.writeKeyBind(&b, actionMoveHfRight, "scroll right half screen")
-	k.writeKeyBind(&b, actionGoLine, "number of go to line")
-	k.writeKeyBind(&b, actionNextDoc, "next document")
-	k.writeKeyBind(&b, actionPreviousDoc, "previous document")
+// countPrefix accumulates the number entered before the command.
+// countPrefix returns true if the key is part of the count prefix.
+func (root *Root) countPrefix(ev *tcell.EventKey) bool {
+	if ev.Key() != tcell.KeyRune || ev.Modifiers() != tcell.ModNone {
+		return false
+	}
+	r := ev.Rune()
+	if r < '0' || r > '9' || (r == '0' && root.count == 0) {
+		return false
+	}
 
-	fmt.Fprintf(&b, "\n\tMark position\n\n")
-	k.writeKeyBind(&b, actionMark, "mark current position")
-	k.writeKeyBind(&b, actionMoveMark, "move to next marked position")
-	k.writeKeyBind(&b, actionMovePrevMark, "move to previous marked position")
+	root.count = min(root.count*10+int(r-'0'), maxCount)
+	root.setMessage(fmt.Sprintf("count:%d", root.count))
+	return true
+}
 
-	fmt.Fprintf(&b, "\n\tSearch\n\n")
-	k.writeKeyBind(&b, actionSearch, "forward search mode")
-	k.writeKeyBind(&b, actionBackSearch, "backward search mode")
-	k.writeKeyBind(&b, actionNextSearch, "repeat forward search")
-	k.writeKeyBind(&b, actionNextBackSearch, "repeat backward search")
+// takeCount returns the count prefix (1 if not entered) and resets it.
+func (root *Root) takeCount() int {
+	n := max(root.count, 1)
+	root.count = 0
+	return n
+}
 
-	fmt.Fprintf(&b, "\n\tChange display\n\n")
-	k.writeKeyBind(&b, actionWrap, "wrap/nowrap toggle")
-	k.writeKeyBind(&b, actionColumnMode, "column mode toggle")
-	k.writeKeyBind(&b, actionAlternate, "color to alternate rows toggle")
-	k.writeKeyBind(&b, actionLineNumMode, "line number toggle")
+func (root *Root) keyCapture(ev *tcell.EventKey) bool {
+	// The keys of a key sequence are not the count prefix.
+	if root.chords != nil && len(root.chords.pending) > 0 && root.chordCapture(ev) {
+		return true
+	}
+	if root.countPrefix(ev) {
+		return true
+	}
+	if root.chordCapture(ev) {
+		return true
+	}
+	root.keyConfig.Capture(ev)
+	root.count = 0
+	return true
+}
+
+// keyBindAction is an action and its description displayed in the help.
+type keyBindAction struct {
+	action string
+	detail string
+}
 
-	fmt.Fprintf(&b, "\n\tChange Display with Input\n\n")
-	k.writeKeyBind(&b, actionViewMode, "view mode selection")
-	k.writeKeyBind(&b, actionDelimiter, "delimiter string")
-	k.writeKeyBind(&b, actionHeader, "number of header lines")
-	k.writeKeyBind(&b, actionTabWidth, "TAB width")
+// keyBindCategory is a group of actions displayed in the help.
+type keyBindCategory struct {
+	name    string
+	actions []keyBindAction
+}
+
+// keyBindCategories is a list of actions by category for the help.
+var keyBindCategories = []keyBindCategory{
+	{
+		name: "Key binding",
+		actions: []keyBindAction{
+			{actionExit, "quit"},
+			{actionCancel, "cancel"},
+			{actionWriteExit, "output screen and quit"},
+			{actionHelp, "display help screen"},
+			{actionLogDoc, "display log screen"},
+			{actionDebug, "debug timing toggle"},
+			{actionSync, "screen sync"},
+			{actionReload, "reload the file"},
+			{actionFollow, "follow mode toggle"},
+			{actionFollowAll, "follow all mode toggle"},
+			{actionToggleMouse, "enable/disable mouse"},
+			{actionCloseDoc, "close current document"},
+			{actionCloseOtherDocs, "close all documents except the current document"},
+			{actionCloseDerivedDocs, "close all filtered and derived documents"},
+			{actionTrimBuffer, "drop the lines above the view (or count lines) from the buffer"},
+			{actionRenameDoc, "rename current document"},
+			{actionSaveBuffer, "save buffer to file"},
+			{actionSettings, "display settings screen"},
+			{actionWriteConfig, "write settings to config file"},
+			{actionReloadConfig, "reload config file"},
+		},
+	},
+	{
+		name: "Moving",
+		actions: []keyBindAction{
+			{actionMoveDown, "forward by one line"},
+			{actionMoveUp, "backward by one line"},
+			{actionMoveTop, "go to begin of line"},
+			{actionMoveBottom, "go to end of line"},
+			{actionMovePgDn, "forward by page"},
+			{actionMovePgUp, "backward by page"},
+			{actionMoveHfDn, "forward a half page"},
+			{actionMoveHfUp, "backward a half page"},
+			{actionMoveLeft, "scroll to left"},
+			{actionMoveRight, "scroll to right"},
+			{actionMoveHfLeft, "scroll left half screen"},
+			{actionMoveHfRight, "scroll right half screen"},
+			{actionGoLine, "number of go to line"},
+			{actionNextDoc, "next document"},
+			{actionPreviousDoc, "previous document"},
+			{actionDocSwitch, "switch document with fuzzy matching"},
+			{actionMoveDocLeft, "move current document to the left"},
+			{actionMoveDocRight, "move current document to the right"},
+			{actionSortDocs, "sort documents by title"},
+			{actionScrollBind, "scroll-bind mode toggle (move all documents together)"},
+			{actionNextFile, "next file of the concatenated files"},
+			{actionPreviousFile, "previous file of the concatenated files"},
+			{actionNextError, "next error line"},
+			{actionPreviousError, "previous error line"},
+			{actionOpenError, "open the file of the error line"},
+		},
+	},
+	{
+		name: "Mark position",
+		actions: []keyBindAction{
+			{actionMark, "mark current position"},
+			{actionMoveMark, "move to next marked position"},
+			{actionMovePrevMark, "move to previous marked position"},
+			{actionSelectLine, "select/deselect the line to output on exit"},
+		},
+	},
+	{
+		name: "Search",
+		actions: []keyBindAction{
+			{actionSearch, "forward search mode"},
+			{actionBackSearch, "backward search mode"},
+			{actionNextSearch, "repeat forward search"},
+			{actionNextBackSearch, "repeat backward search"},
+			{actionFilter, "filter mode"},
+			{actionSearchDoc, "search results to a new document"},
+			{actionColumnSearch, "search in the selected column toggle"},
+			{actionPinHighlight, "pin the search highlight toggle"},
+			{actionClearPinned, "clear the pinned highlights"},
+			{actionSectionSearch, "search in the current section toggle"},
+			{actionSectionReport, "count the matches in each section"},
+			{actionDimUnmatched, "dim unmatched lines toggle"},
+			{actionMonochrome, "monochrome toggle"},
+			{actionSearchWrap, "search wrap around toggle"},
+		},
+	},
+	{
+		name: "Change display",
+		actions: []keyBindAction{
+			{actionWrap, "wrap/nowrap toggle"},
+			{actionTruncate, "truncate long lines toggle"},
+			{actionColumnMode, "column mode toggle"},
+			{actionAlternate, "color to alternate rows toggle"},
+			{actionLineNumMode, "line number toggle"},
+			{actionSort, "sort by the selected column"},
+			{actionSortType, "change the sort type (auto/string/number/size/time)"},
+			{actionJSONFields, "JSON fields to columns / raw line"},
+			{actionRecord, "transposed row toggle"},
+			{actionMerge, "merge documents by timestamp"},
+			{actionDiff, "diff of the previous and current documents"},
+			{actionPipe, "pipe the lines to a command"},
+			{actionShell, "run a shell command"},
+			{actionOpenFile, "open a file"},
+			{actionSuspend, "suspend"},
+			{actionReplace, "replace preview (s/pattern/replacement/)"},
+			{actionYankLine, "yank the line(s) to the clipboard"},
+			{actionYankCell, "yank the cell of the selected column"},
+		},
+	},
+	{
+		name: "Change Display with Input",
+		actions: []keyBindAction{
+			{actionViewMode, "view mode selection"},
+			{actionDelimiter, "delimiter string"},
+			{actionHeader, "number of header lines"},
+			{actionTabWidth, "TAB width"},
+			{actionSetOption, "change setting (name=value)"},
+		},
+	},
+}
 
+// KeyBindString returns keybind as a string for help.
+// The actions are grouped by category and show the current key bindings.
+func KeyBindString(k KeyBind) string {
+	var b bytes.Buffer
+	for _, category := range keyBindCategories {
+		fmt.Fprintf(&b, "\n\t%s\n\n", category.name)
+		for _, a := range category.actions {
+			k.writeKeyBind(&b, a.action, a.detail)
+		}
+	}
 	return b.String()
 }
 
 func (k KeyBind) writeKeyBind(w io.Writer, action string, detail string) {
-	fmt.Fprintf(w, "  %-26s * %s\n", "["+strings.Join(k[action], "], [")+"]", detail)
+	keys := "(unbound)"
+	if len(k[action]) > 0 {
+		keys = "[" + strings.Join(k[action], "], [") + "]"
+	}
+	fmt.Fprintf(w, "  %-26s * %s\n", keys, detail)
 }
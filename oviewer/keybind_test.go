@@ -0,0 +1,38 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_keyBindCategories(t *testing.T) {
+	root := &Root{}
+	handlers := root.setHandler()
+	listed := make(map[string]bool)
+	for _, category := range keyBindCategories {
+		for _, a := range category.actions {
+			if _, ok := handlers[a.action]; !ok {
+				t.Errorf("keyBindCategories has unknown action %s", a.action)
+			}
+			listed[a.action] = true
+		}
+	}
+	for action := range handlers {
+		if !listed[action] {
+			t.Errorf("keyBindCategories does not list %s", action)
+		}
+	}
+}
+
+func TestKeyBindString(t *testing.T) {
+	keyBind := GetKeyBinds(map[string][]string{
+		actionExit: {"ctrl+q"},
+	})
+	str := KeyBindString(keyBind)
+	if !strings.Contains(str, "[ctrl+q]") {
+		t.Errorf("KeyBindString() does not reflect the key binding:\n%s", str)
+	}
+	if strings.Contains(str, "[Escape], [q]") {
+		t.Errorf("KeyBindString() contains the overwritten key binding:\n%s", str)
+	}
+}
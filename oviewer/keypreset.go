@@ -0,0 +1,227 @@
+package oviewer
+
+import (
+	"fmt"
+	"sort"
+)
+
+// keyBindPresets is a list of key mapping presets.
+// A preset replaces the default key mapping entirely.
+var keyBindPresets = map[string]func() map[string][]string{
+	"default": defaultKeyBinds,
+	"vi":      viKeyBinds,
+	"emacs":   emacsKeyBinds,
+}
+
+// KeyBindPresets returns the names of the key mapping presets.
+func KeyBindPresets() []string {
+	names := make([]string, 0, len(keyBindPresets))
+	for name := range keyBindPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetPresetKeyBinds returns the key mapping of the preset overwritten by bind.
+// An empty preset is the default key mapping.
+func GetPresetKeyBinds(preset string, bind map[string][]string) (map[string][]string, error) {
+	if preset == "" {
+		preset = "default"
+	}
+	presetFunc, ok := keyBindPresets[preset]
+	if !ok {
+		return nil, fmt.Errorf("%w unknown preset [%s]", ErrFailedKeyBind, preset)
+	}
+	keyBind := presetFunc()
+
+	for k, v := range bind {
+		keyBind[k] = v
+	}
+
+	return keyBind, nil
+}
+
+// viKeyBinds returns the key mapping of vi (and less) style.
+func viKeyBinds() map[string][]string {
+	return map[string][]string{
+		actionExit:             {"q", "Escape"},
+		actionCancel:           {"ctrl+c"},
+		actionWriteExit:        {"Q"},
+		actionSync:             {"ctrl+l", "r"},
+		actionFollow:           {"F"},
+		actionFollowAll:        {"ctrl+a"},
+		actionHelp:             {"F1"},
+		actionLogDoc:           {"ctrl+alt+e"},
+		actionMoveDown:         {"j", "Enter", "Down", "ctrl+e", "ctrl+n"},
+		actionMoveUp:           {"k", "Up", "ctrl+y", "ctrl+p"},
+		actionMoveTop:          {"g g", "Home"},
+		actionMoveBottom:       {"G", "End"},
+		actionMovePgUp:         {"ctrl+b", "b", "PageUp"},
+		actionMovePgDn:         {"ctrl+f", "f", "PageDown"},
+		actionMoveHfUp:         {"ctrl+u", "u"},
+		actionMoveHfDn:         {"ctrl+d", "d"},
+		actionMoveLeft:         {"h", "left"},
+		actionMoveRight:        {"l", "right"},
+		actionMoveHfLeft:       {"ctrl+left"},
+		actionMoveHfRight:      {"ctrl+right"},
+		actionMoveMark:         {"'"},
+		actionMovePrevMark:     {"`"},
+		actionViewMode:         {"p", "P"},
+		actionWrap:             {"w", "W"},
+		actionColumnMode:       {"c"},
+		actionAlternate:        {"C"},
+		actionLineNumMode:      {"ctrl+alt+n"},
+		actionMark:             {"m"},
+		actionSearch:           {"/"},
+		actionBackSearch:       {"?"},
+		actionDelimiter:        {"D"},
+		actionHeader:           {"H"},
+		actionTabWidth:         {"t"},
+		actionGoLine:           {":"},
+		actionNextSearch:       {"n"},
+		actionNextBackSearch:   {"N"},
+		actionNextDoc:          {"]"},
+		actionPreviousDoc:      {"["},
+		actionCloseDoc:         {"ctrl+k"},
+		actionToggleMouse:      {"ctrl+alt+r"},
+		actionSaveBuffer:       {"s"},
+		actionSettings:         {"O"},
+		actionSetOption:        {"="},
+		actionWriteConfig:      {"ctrl+alt+w"},
+		actionReloadConfig:     {"ctrl+alt+l"},
+		actionDimUnmatched:     {"&"},
+		actionSearchWrap:       {"ctrl+alt+s"},
+		actionFilter:           {"ctrl+alt+f"},
+		actionColumnSearch:     {"alt+/"},
+		actionSort:             {"alt+s"},
+		actionSortType:         {"alt+t"},
+		actionTruncate:         {"T"},
+		actionJSONFields:       {"alt+j"},
+		actionMerge:            {"alt+m"},
+		actionDiff:             {"alt+d"},
+		actionNextFile:         {"}"},
+		actionPreviousFile:     {"{"},
+		actionPipe:             {"|"},
+		actionSearchDoc:        {"alt+o"},
+		actionSelectLine:       {"x"},
+		actionNextError:        {"alt+n"},
+		actionPreviousError:    {"alt+p"},
+		actionOpenError:        {"alt+e"},
+		actionReplace:          {"alt+r"},
+		actionYankLine:         {"y"},
+		actionYankCell:         {"Y"},
+		actionReload:           {"R"},
+		actionRenameDoc:        {"alt+c"},
+		actionMoveDocLeft:      {"alt+Left"},
+		actionMoveDocRight:     {"alt+Right"},
+		actionSortDocs:         {"ctrl+alt+o"},
+		actionDocSwitch:        {"alt+l"},
+		actionCloseOtherDocs:   {"ctrl+alt+k"},
+		actionCloseDerivedDocs: {"alt+k"},
+		actionScrollBind:       {"ctrl+alt+b"},
+		actionTrimBuffer:       {"alt+x"},
+		actionSectionSearch:    {"alt+h"},
+		actionPinHighlight:     {"alt+i"},
+		actionClearPinned:      {"alt+u"},
+		actionShell:            {"!"},
+		actionSuspend:          {"ctrl+z"},
+		actionMonochrome:       {"alt+z"},
+		actionDebug:            {"ctrl+alt+d"},
+		actionRecord:           {"alt+T"},
+		actionSectionReport:    {"alt+C"},
+		actionOpenFile:         {"alt+O"},
+	}
+}
+
+// emacsKeyBinds returns the key mapping of emacs style.
+func emacsKeyBinds() map[string][]string {
+	return map[string][]string{
+		actionExit:             {"q"},
+		actionCancel:           {"ctrl+g", "ctrl+c"},
+		actionWriteExit:        {"Q"},
+		actionSync:             {"ctrl+l"},
+		actionFollow:           {"F"},
+		actionFollowAll:        {"A"},
+		actionHelp:             {"ctrl+h", "F1"},
+		actionLogDoc:           {"ctrl+alt+e"},
+		actionMoveDown:         {"ctrl+n", "Down", "Enter"},
+		actionMoveUp:           {"ctrl+p", "Up"},
+		actionMoveTop:          {"alt+<", "Home"},
+		actionMoveBottom:       {"alt+>", "End"},
+		actionMovePgUp:         {"alt+v", "PageUp"},
+		actionMovePgDn:         {"ctrl+v", "PageDown"},
+		actionMoveHfUp:         {"alt+Up"},
+		actionMoveHfDn:         {"alt+Down"},
+		actionMoveLeft:         {"ctrl+b", "left"},
+		actionMoveRight:        {"ctrl+f", "right"},
+		actionMoveHfLeft:       {"alt+b", "ctrl+left"},
+		actionMoveHfRight:      {"alt+f", "ctrl+right"},
+		actionMoveMark:         {">"},
+		actionMovePrevMark:     {"<"},
+		actionViewMode:         {"p", "P"},
+		actionWrap:             {"w", "W"},
+		actionColumnMode:       {"c"},
+		actionAlternate:        {"C"},
+		actionLineNumMode:      {"G"},
+		actionMark:             {"ctrl+space", "m"},
+		actionSearch:           {"ctrl+s", "/"},
+		actionBackSearch:       {"ctrl+r", "?"},
+		actionDelimiter:        {"d"},
+		actionHeader:           {"H"},
+		actionTabWidth:         {"t"},
+		actionGoLine:           {"alt+g"},
+		actionNextSearch:       {"n"},
+		actionNextBackSearch:   {"N"},
+		actionNextDoc:          {"]"},
+		actionPreviousDoc:      {"["},
+		actionCloseDoc:         {"ctrl+k"},
+		actionToggleMouse:      {"ctrl+alt+r"},
+		actionSaveBuffer:       {"S"},
+		actionSettings:         {"O"},
+		actionSetOption:        {"="},
+		actionWriteConfig:      {"ctrl+alt+w"},
+		actionReloadConfig:     {"ctrl+alt+l"},
+		actionDimUnmatched:     {"&"},
+		actionSearchWrap:       {"ctrl+alt+s"},
+		actionFilter:           {"ctrl+alt+f"},
+		actionColumnSearch:     {"alt+/"},
+		actionSort:             {"alt+s"},
+		actionSortType:         {"alt+t"},
+		actionTruncate:         {"T"},
+		actionJSONFields:       {"alt+j"},
+		actionMerge:            {"alt+m"},
+		actionDiff:             {"alt+d"},
+		actionNextFile:         {"alt+}"},
+		actionPreviousFile:     {"alt+{"},
+		actionPipe:             {"|"},
+		actionSearchDoc:        {"alt+o"},
+		actionSelectLine:       {"x"},
+		actionNextError:        {"alt+n"},
+		actionPreviousError:    {"alt+p"},
+		actionOpenError:        {"alt+e"},
+		actionReplace:          {"alt+r"},
+		actionYankLine:         {"alt+w"},
+		actionYankCell:         {"alt+W"},
+		actionReload:           {"alt+R"},
+		actionRenameDoc:        {"alt+c"},
+		actionMoveDocLeft:      {"alt+Left"},
+		actionMoveDocRight:     {"alt+Right"},
+		actionSortDocs:         {"ctrl+alt+o"},
+		actionDocSwitch:        {"alt+l"},
+		actionCloseOtherDocs:   {"ctrl+alt+k"},
+		actionCloseDerivedDocs: {"alt+k"},
+		actionScrollBind:       {"ctrl+alt+b"},
+		actionTrimBuffer:       {"alt+x"},
+		actionSectionSearch:    {"alt+h"},
+		actionPinHighlight:     {"alt+i"},
+		actionClearPinned:      {"alt+u"},
+		actionShell:            {"!"},
+		actionSuspend:          {"ctrl+z"},
+		actionMonochrome:       {"alt+z"},
+		actionDebug:            {"ctrl+alt+d"},
+		actionRecord:           {"alt+T"},
+		actionSectionReport:    {"alt+C"},
+		actionOpenFile:         {"alt+O"},
+	}
+}
@@ -0,0 +1,52 @@
+package oviewer
+
+import (
+	"testing"
+
+	"code.rocketnine.space/tslocum/cbind"
+)
+
+func TestGetPresetKeyBinds(t *testing.T) {
+	root := &Root{}
+	handlers := root.setHandler()
+	for _, preset := range KeyBindPresets() {
+		t.Run(preset, func(t *testing.T) {
+			keyBind, err := GetPresetKeyBinds(preset, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for action := range handlers {
+				if len(keyBind[action]) == 0 {
+					t.Errorf("GetPresetKeyBinds() %s has no key for %s", preset, action)
+				}
+			}
+			for action := range keyBind {
+				if _, ok := handlers[action]; !ok {
+					t.Errorf("GetPresetKeyBinds() %s has unknown action %s", preset, action)
+				}
+			}
+		})
+	}
+}
+
+func TestGetPresetKeyBindsUnknown(t *testing.T) {
+	if _, err := GetPresetKeyBinds("unknown", nil); err == nil {
+		t.Errorf("GetPresetKeyBinds() error = nil, want error")
+	}
+}
+
+func TestGetPresetKeyBindsVi(t *testing.T) {
+	keyBind, err := GetPresetKeyBinds("vi", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// g is the prefix of gg.
+	if got := keyBind[actionMoveTop]; len(got) == 0 || got[0] != "g g" {
+		t.Errorf("GetPresetKeyBinds() vi %s = %v, want \"g g\"", actionMoveTop, got)
+	}
+	root := &Root{}
+	root.keyConfig = cbind.NewConfiguration()
+	if err := root.setKeyBind(keyBind); err != nil {
+		t.Fatal(err)
+	}
+}
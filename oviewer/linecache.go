@@ -0,0 +1,61 @@
+package oviewer
+
+// lineCache is the string of a displayed line and the map of its byte positions to the contents,
+// reused across the frames while the line is displayed.
+type lineCache struct {
+	str     string
+	byteMap map[int]int
+	// dirty is true if the line must be converted again.
+	dirty bool
+	// used is true if the line is displayed in the current frame.
+	used bool
+}
+
+// beginLines prepares the line cache for drawing a frame.
+// All the lines are marked dirty if the cache of the contents has been cleared
+// (reload, trim, replace, tab width, etc.) since the last frame.
+func (m *Document) beginLines() {
+	m.cacheMu.Lock()
+	gen := m.cacheGen
+	m.cacheMu.Unlock()
+
+	if m.drawLines == nil {
+		m.drawLines = make(map[int]*lineCache)
+	}
+	dirty := gen != m.linesGen
+	m.linesGen = gen
+	for _, l := range m.drawLines {
+		l.used = false
+		if dirty {
+			l.dirty = true
+		}
+	}
+}
+
+// endLines drops the lines that were not displayed in the frame.
+func (m *Document) endLines() {
+	for lN, l := range m.drawLines {
+		if !l.used {
+			delete(m.drawLines, lN)
+		}
+	}
+}
+
+// contentsStr returns the string of the contents of the line lN and the map of its byte positions.
+// The lines after EOF are not cached, because they are displayed as they are read.
+func (m *Document) contentsStr(lN int, lc lineContents) (string, map[int]int) {
+	if m.drawLines == nil || lN < 0 || lN >= m.BufEndNum() {
+		return contentsToStr(lc)
+	}
+	l, ok := m.drawLines[lN]
+	if !ok {
+		l = &lineCache{dirty: true}
+		m.drawLines[lN] = l
+	}
+	if l.dirty {
+		l.str, l.byteMap = contentsToStr(lc)
+		l.dirty = false
+	}
+	l.used = true
+	return l.str, l.byteMap
+}
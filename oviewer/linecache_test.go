@@ -0,0 +1,68 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func TestDocument_contentsStr(t *testing.T) {
+	m := testLineDocument(t, 0, "abc", "de")
+	contentsStr := func(lN int) string {
+		t.Helper()
+		lc, err := m.lineToContents(lN, 8)
+		if err != nil {
+			lc = strToContents("~", 8)
+		}
+		str, _ := m.contentsStr(lN, lc)
+		return str
+	}
+
+	m.beginLines()
+	if got := contentsStr(0); got != "abc" {
+		t.Fatalf("contentsStr(0) = %q, want %q", got, "abc")
+	}
+	if got := contentsStr(2); got != "~" {
+		t.Fatalf("contentsStr(2) = %q, want %q", got, "~")
+	}
+	m.endLines()
+	l := m.drawLines[0]
+	if l == nil || l.dirty {
+		t.Fatalf("the displayed line is not cached: %v", l)
+	}
+	if _, ok := m.drawLines[2]; ok {
+		t.Errorf("the line after EOF is cached")
+	}
+
+	// The unchanged line is reused in the next frame.
+	m.beginLines()
+	if got := contentsStr(0); got != "abc" || m.drawLines[0] != l {
+		t.Errorf("contentsStr(0) = %q, the cache is not reused", got)
+	}
+	lc, err := m.lineToContents(0, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	allocs := testing.AllocsPerRun(10, func() {
+		m.beginLines()
+		m.contentsStr(0, lc)
+		m.endLines()
+	})
+	if allocs != 0 {
+		t.Errorf("contentsStr() allocs = %v, want 0", allocs)
+	}
+	m.endLines()
+
+	// The lines not displayed are dropped.
+	m.beginLines()
+	contentsStr(1)
+	m.endLines()
+	if _, ok := m.drawLines[0]; ok {
+		t.Errorf("the line not displayed is kept")
+	}
+
+	// The lines are dirty after the cache is cleared.
+	m.ClearCache()
+	m.beginLines()
+	if !m.drawLines[1].dirty {
+		t.Errorf("the line is not dirty after ClearCache")
+	}
+}
@@ -0,0 +1,87 @@
+package oviewer
+
+import (
+	"regexp"
+	"strconv"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// fileLinkRegexp matches the file:line(:col) references anywhere in the line,
+// such as the frames of stack traces.
+var fileLinkRegexp = regexp.MustCompile(`(?:^|[\s("'=\[])((?:[\w.~-]*/)*[\w.~-]+\.[A-Za-z][A-Za-z0-9]*):(\d+)(?::(\d+))?`)
+
+// fileLink represents a file:line reference in the line.
+type fileLink struct {
+	// start and end are the byte positions of the reference in the line.
+	start int
+	end   int
+	loc   errorLocation
+}
+
+// fileLinks returns the file:line references in the line.
+func fileLinks(str string) []fileLink {
+	var links []fileLink
+	for _, m := range fileLinkRegexp.FindAllStringSubmatchIndex(str, -1) {
+		link := fileLink{
+			start: m[2],
+			end:   m[5],
+			loc:   errorLocation{file: str[m[2]:m[3]]},
+		}
+		link.loc.line, _ = strconv.Atoi(str[m[4]:m[5]])
+		if m[6] >= 0 {
+			link.end = m[7]
+			link.loc.col, _ = strconv.Atoi(str[m[6]:m[7]])
+		}
+		if link.loc.line > 0 {
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// fileLinkStyle applies StyleFileLink to the file:line references in the line.
+// The references are internal jump targets, not OSC 8 hyperlinks,
+// because the screen library does not output hyperlinks.
+func (root *Root) fileLinkStyle(lc lineContents, lineStr string, byteMap map[int]int) {
+	for _, link := range fileLinks(lineStr) {
+		RangeStyle(lc, byteMap[link.start], byteMap[link.end], root.StyleFileLink)
+	}
+}
+
+// linkAt returns the file:line reference displayed at the position of the screen.
+func (root *Root) linkAt(x int, y int) (errorLocation, bool) {
+	if y < 0 || y >= len(root.lnumber) {
+		return errorLocation{}, false
+	}
+	ln := root.lnumber[y]
+	if ln.line < 0 {
+		return errorLocation{}, false
+	}
+	lc, err := root.Doc.lineToContents(ln.line, root.Doc.TabWidth)
+	if err != nil {
+		return errorLocation{}, false
+	}
+	cx := root.Doc.x + x + root.branchWidth(lc, ln.wrap) - root.startX
+	lineStr, byteMap := contentsToStr(lc)
+	for _, link := range fileLinks(lineStr) {
+		if byteMap[link.start] <= cx && cx < byteMap[link.end] {
+			return link.loc, true
+		}
+	}
+	return errorLocation{}, false
+}
+
+// openLink opens the file:line reference clicked with the alt key.
+// It returns false if there is no reference at the position.
+func (root *Root) openLink(ev *tcell.EventMouse) bool {
+	if !root.FileLinks || ev.Buttons() != tcell.ButtonPrimary || ev.Modifiers()&tcell.ModAlt == 0 {
+		return false
+	}
+	loc, ok := root.linkAt(ev.Position())
+	if !ok {
+		return false
+	}
+	root.openLocation(loc, root.Doc.FileName)
+	return true
+}
@@ -0,0 +1,45 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_fileLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		want []fileLink
+	}{
+		{
+			name: "go stack trace",
+			str:  "\t/home/user/main.go:12 +0x1d",
+			want: []fileLink{{start: 1, end: 22, loc: errorLocation{file: "/home/user/main.go", line: 12}}},
+		},
+		{
+			name: "column",
+			str:  "see oviewer/draw.go:10:5 and x.c:3",
+			want: []fileLink{
+				{start: 4, end: 24, loc: errorLocation{file: "oviewer/draw.go", line: 10, col: 5}},
+				{start: 29, end: 34, loc: errorLocation{file: "x.c", line: 3}},
+			},
+		},
+		{
+			name: "url",
+			str:  "http://example.com:8080/",
+			want: nil,
+		},
+		{
+			name: "time",
+			str:  "12:30:45",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fileLinks(tt.str); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fileLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
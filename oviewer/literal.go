@@ -0,0 +1,213 @@
+package oviewer
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// literalBatch is the number of the lines scanned at a time in the literal search.
+const literalBatch = 4096
+
+// segmentOverlap is the number of bytes of the next segment searched with a segment
+// in the regular expression search. A longer match across the boundary is not found.
+const segmentOverlap = 1024
+
+// searchRegexp returns the regular expression of the search string.
+// The string is searched literally if RegexpSearch is off.
+func (root *Root) searchRegexp(str string) *regexp.Regexp {
+	if !root.RegexpSearch {
+		str = regexp.QuoteMeta(str)
+	}
+	return regexpComple(str, root.CaseSensitive)
+}
+
+// searchType returns the type of the search of the search string.
+// The literal search is used if RegexpSearch is off or the string has no metacharacters.
+// A custom Searcher is always matched by itself.
+func (root *Root) searchType(str string) SearchType {
+	if root.isCustomSearcher() {
+		return searchRegexp
+	}
+	if !root.RegexpSearch {
+		if root.CaseSensitive {
+			return searchSensitive
+		}
+		return searchInsensitive
+	}
+	return getSearchType(str, root.CaseSensitive)
+}
+
+// findLiteral returns the first line that contains str literally,
+// from start to end (not included) in the direction of step.
+// The lines are scanned in batches without locking the document for each line.
+func (m *Document) findLiteral(ctx context.Context, start int, end int, step int, str string, fold bool) (int, error) {
+	n := start
+	for (step > 0 && n < end) || (step < 0 && n > end) {
+		// A match is shorter than the string across the boundary of the segments.
+		lines := m.lineBatch(n, end, step, len(str)-1)
+		if len(lines) == 0 {
+			break
+		}
+		for i, line := range lines {
+			if literalContains(line, str, fold) {
+				return n + i*step, nil
+			}
+		}
+		n += len(lines) * step
+		select {
+		case <-ctx.Done():
+			return 0, ErrCancel
+		default:
+		}
+	}
+	return 0, ErrNotFound
+}
+
+// lineBatch returns up to literalBatch lines from n toward end (not included)
+// in the order of the direction of step.
+// The segments that continue to the next line are followed by overlap bytes of the next segment.
+func (m *Document) lineBatch(n int, end int, step int, overlap int) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if step > 0 {
+		n = max(n, 0)
+		hi := min(min(end, n+literalBatch), len(m.lines))
+		if n >= hi {
+			return nil
+		}
+		lines := make([]string, 0, hi-n)
+		for i := n; i < hi; i++ {
+			lines = append(lines, m.withNextSegment(i, overlap))
+		}
+		return lines
+	}
+	n = min(n, len(m.lines)-1)
+	lo := max(max(end+1, n-literalBatch+1), 0)
+	if n < lo {
+		return nil
+	}
+	lines := make([]string, 0, n-lo+1)
+	for i := n; i >= lo; i-- {
+		lines = append(lines, m.withNextSegment(i, overlap))
+	}
+	return lines
+}
+
+// withNextSegment returns the line lN followed by up to overlap bytes of the next segment
+// if the line continues to the next line, so that a match across the boundary is found.
+// It must be called with the lock held.
+func (m *Document) withNextSegment(lN int, overlap int) string {
+	line := m.lines[lN]
+	if overlap <= 0 || lN+1 >= len(m.lines) || !m.isContinued(lN) {
+		return line
+	}
+	next := m.lines[lN+1]
+	return line + next[:min(overlap, len(next))]
+}
+
+// isContinued returns true if the line is a segment that continues to the next line.
+// It must be called with the lock held.
+func (m *Document) isContinued(lN int) bool {
+	i := sort.SearchInts(m.continued, lN)
+	return i < len(m.continued) && m.continued[i] == lN
+}
+
+// lineForSearch returns the line lN to be searched,
+// followed by the beginning of the next segment if it continues to the next line.
+func (m *Document) lineForSearch(lN int) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if lN < 0 || lN >= len(m.lines) {
+		return ""
+	}
+	return m.withNextSegment(lN, segmentOverlap)
+}
+
+// literalContains returns true if the line without escape sequences contains str.
+// The case is ignored if fold is true.
+func literalContains(line string, str string, fold bool) bool {
+	if strings.ContainsAny(line, "\x1b\b") {
+		line = stripEscapeSequence.ReplaceAllString(line, "")
+	}
+	if fold {
+		return containsFold(line, str)
+	}
+	return strings.Contains(line, str)
+}
+
+// containsFold returns true if s contains substr under Unicode case-folding.
+// It compares the runes in place instead of lowering the strings,
+// so that searching a large buffer does not allocate for each line.
+func containsFold(s string, substr string) bool {
+	if substr == "" {
+		return true
+	}
+	first, _ := utf8.DecodeRuneInString(substr)
+	if first < utf8.RuneSelf && !isASCIILetter(byte(first)) {
+		// The first byte has no other case, jump to its occurrences.
+		for {
+			i := strings.IndexByte(s, byte(first))
+			if i < 0 {
+				return false
+			}
+			if hasPrefixFold(s[i:], substr) {
+				return true
+			}
+			s = s[i+1:]
+		}
+	}
+	for i, r := range s {
+		if equalFoldRune(r, first) && hasPrefixFold(s[i:], substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPrefixFold returns true if s begins with prefix under Unicode case-folding.
+func hasPrefixFold(s string, prefix string) bool {
+	for prefix != "" {
+		if s == "" {
+			return false
+		}
+		r1, n1 := utf8.DecodeRuneInString(s)
+		r2, n2 := utf8.DecodeRuneInString(prefix)
+		if !equalFoldRune(r1, r2) {
+			return false
+		}
+		s = s[n1:]
+		prefix = prefix[n2:]
+	}
+	return true
+}
+
+// equalFoldRune returns true if r1 and r2 are equal under Unicode case-folding.
+func equalFoldRune(r1 rune, r2 rune) bool {
+	if r1 == r2 {
+		return true
+	}
+	if r1 < utf8.RuneSelf && r2 < utf8.RuneSelf {
+		if 'A' <= r1 && r1 <= 'Z' {
+			r1 += 'a' - 'A'
+		}
+		if 'A' <= r2 && r2 <= 'Z' {
+			r2 += 'a' - 'A'
+		}
+		return r1 == r2
+	}
+	for r := unicode.SimpleFold(r1); r != r1; r = unicode.SimpleFold(r) {
+		if r == r2 {
+			return true
+		}
+	}
+	return false
+}
+
+// isASCIILetter returns true if b is an ASCII letter.
+func isASCIILetter(b byte) bool {
+	return ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z')
+}
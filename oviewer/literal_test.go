@@ -0,0 +1,125 @@
+package oviewer
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestDocument_findLiteral(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < literalBatch*2+10; n++ {
+		m.append("line " + strconv.Itoa(n))
+	}
+	m.append("\x1b[1mBold\x1b[0m target")
+	m.append("last")
+	end := m.BufEndNum()
+
+	tests := []struct {
+		name    string
+		start   int
+		end     int
+		step    int
+		str     string
+		fold    bool
+		want    int
+		wantErr bool
+	}{
+		{name: "forward", start: 0, end: end, step: 1, str: "line 5000", want: 5000},
+		{name: "escape", start: 0, end: end, step: 1, str: "Bold target", want: end - 2},
+		{name: "fold", start: 0, end: end, step: 1, str: "BOLD", fold: true, want: end - 2},
+		{name: "sensitive", start: 0, end: end, step: 1, str: "BOLD", wantErr: true},
+		{name: "backward", start: end - 1, end: -1, step: -1, str: "line 10", want: 1099},
+		{name: "backwardRange", start: 5000, end: -1, step: -1, str: "line 2", want: 2999},
+		{name: "notFound", start: 0, end: end, step: 1, str: "none", wantErr: true},
+		{name: "outOfRange", start: end + 10, end: end + 20, step: 1, str: "line", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := m.findLiteral(context.Background(), tt.start, tt.end, tt.step, tt.str, tt.fold)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Document.findLiteral() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Document.findLiteral() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_findLiteralSegment(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.segmentSize = 8
+	if err := m.readAll(bufio.NewReaderSize(strings.NewReader("first\nabcdefghijkl\nlast\n"), 16)); !errors.Is(err, io.EOF) {
+		t.Fatal(err)
+	}
+	// "abcdefgh" continues to "ijkl".
+	ctx := context.Background()
+	if got, err := m.findLiteral(ctx, 0, m.BufEndNum(), 1, "ghij", false); err != nil || got != 1 {
+		t.Errorf("Document.findLiteral() = %v, %v, want 1", got, err)
+	}
+	if got, err := m.findLiteral(ctx, m.BufEndNum()-1, -1, -1, "ghij", false); err != nil || got != 1 {
+		t.Errorf("Document.findLiteral() backward = %v, %v, want 1", got, err)
+	}
+	// The match in the next segment is found in the next segment.
+	if got, err := m.findLiteral(ctx, 0, m.BufEndNum(), 1, "ijk", false); err != nil || got != 2 {
+		t.Errorf("Document.findLiteral() = %v, %v, want 2", got, err)
+	}
+	if got := m.lineForSearch(1); got != "abcdefghijkl" {
+		t.Errorf("Document.lineForSearch() = %q, want %q", got, "abcdefghijkl")
+	}
+	if got := m.lineForSearch(2); got != "ijkl" {
+		t.Errorf("Document.lineForSearch() = %q, want %q", got, "ijkl")
+	}
+}
+
+func TestRoot_searchRegexp(t *testing.T) {
+	root := &Root{}
+	root.Config = NewConfig()
+	if got := root.searchRegexp("a.c"); !got.MatchString("abc") {
+		t.Errorf("Root.searchRegexp() = %v, want regular expression", got)
+	}
+	root.RegexpSearch = false
+	if got := root.searchRegexp("a.c"); got.MatchString("abc") || !got.MatchString("xa.cx") {
+		t.Errorf("Root.searchRegexp() = %v, want literal", got)
+	}
+	if got := root.searchType("a.c"); got != searchInsensitive {
+		t.Errorf("Root.searchType() = %v, want %v", got, searchInsensitive)
+	}
+}
+
+func Test_containsFold(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		substr string
+		want   bool
+	}{
+		{name: "empty", s: "abc", substr: "", want: true},
+		{name: "same", s: "Hello World", substr: "World", want: true},
+		{name: "upper", s: "Hello World", substr: "WORLD", want: true},
+		{name: "lower", s: "HELLO WORLD", substr: "o w", want: true},
+		{name: "symbol", s: "a-b-C", substr: "-c", want: true},
+		{name: "unicode", s: "ΑΒΓ δ", substr: "βγ", want: true},
+		{name: "kelvin", s: "5K", substr: "5k", want: true},
+		{name: "not found", s: "Hello World", substr: "worlds", want: false},
+		{name: "partial", s: "ab", substr: "abc", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := containsFold(tt.s, tt.substr); got != tt.want {
+				t.Errorf("containsFold() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
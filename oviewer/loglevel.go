@@ -0,0 +1,71 @@
+package oviewer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// logLevelRegexp matches the log level token.
+// The uppercase level words, "level=xxx" and the syslog priority "<N>" at the beginning.
+var logLevelRegexp = regexp.MustCompile(`\b(TRACE|DEBUG|INFO|NOTICE|WARN|WARNING|ERR|ERROR|CRIT|CRITICAL|ALERT|EMERG|FATAL|PANIC)\b|\blevel="?([A-Za-z]+)|^<([0-7])>`)
+
+// logLevelNames maps the level words to the log levels.
+var logLevelNames = map[string]string{
+	"trace":    "trace",
+	"debug":    "debug",
+	"info":     "info",
+	"notice":   "info",
+	"warn":     "warn",
+	"warning":  "warn",
+	"err":      "error",
+	"error":    "error",
+	"crit":     "fatal",
+	"critical": "fatal",
+	"alert":    "fatal",
+	"emerg":    "fatal",
+	"fatal":    "fatal",
+	"panic":    "fatal",
+}
+
+// syslogLevels maps the syslog priorities to the log levels.
+var syslogLevels = [8]string{"fatal", "fatal", "fatal", "error", "warn", "info", "info", "debug"}
+
+// logLevel returns the log level of the line and the byte range of the level token.
+// It returns an empty level if the line does not have a log level.
+func logLevel(str string) (string, int, int) {
+	m := logLevelRegexp.FindStringSubmatchIndex(str)
+	if m == nil {
+		return "", 0, 0
+	}
+	switch {
+	case m[2] >= 0:
+		return logLevelNames[strings.ToLower(str[m[2]:m[3]])], m[2], m[3]
+	case m[4] >= 0:
+		return logLevelNames[strings.ToLower(str[m[4]:m[5]])], m[4], m[5]
+	default:
+		p, err := strconv.Atoi(str[m[6]:m[7]])
+		if err != nil {
+			return "", 0, 0
+		}
+		return syslogLevels[p], m[0], m[1]
+	}
+}
+
+// logLevelHighlight applies the style of the log level to the level token,
+// or to the whole line if LogLevelLine is true.
+func (root *Root) logLevelHighlight(lc lineContents, str string, byteMap map[int]int) {
+	level, start, end := logLevel(str)
+	if level == "" {
+		return
+	}
+	style, ok := root.StyleLogLevel[level]
+	if !ok {
+		return
+	}
+	if root.LogLevelLine {
+		root.lineStyle(lc, style)
+		return
+	}
+	RangeStyle(lc, byteMap[start], byteMap[end], style)
+}
@@ -0,0 +1,80 @@
+package oviewer
+
+import "testing"
+
+func Test_logLevel(t *testing.T) {
+	tests := []struct {
+		name      string
+		str       string
+		wantLevel string
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name:      "none",
+			str:       "hello world",
+			wantLevel: "",
+		},
+		{
+			name:      "info",
+			str:       "2021-08-01 12:00:00 INFO started",
+			wantLevel: "info",
+			wantStart: 20,
+			wantEnd:   24,
+		},
+		{
+			name:      "bracket",
+			str:       "[ERROR] failed",
+			wantLevel: "error",
+			wantStart: 1,
+			wantEnd:   6,
+		},
+		{
+			name:      "warning",
+			str:       "WARNING: disk",
+			wantLevel: "warn",
+			wantStart: 0,
+			wantEnd:   7,
+		},
+		{
+			name:      "lowercaseWord",
+			str:       "an error occurred",
+			wantLevel: "",
+		},
+		{
+			name:      "logfmt",
+			str:       `time=1 level=debug msg="x"`,
+			wantLevel: "debug",
+			wantStart: 13,
+			wantEnd:   18,
+		},
+		{
+			name:      "syslogName",
+			str:       "kernel CRIT: panic",
+			wantLevel: "fatal",
+			wantStart: 7,
+			wantEnd:   11,
+		},
+		{
+			name:      "syslogPriority",
+			str:       "<4>low memory",
+			wantLevel: "warn",
+			wantStart: 0,
+			wantEnd:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, start, end := logLevel(tt.str)
+			if level != tt.wantLevel {
+				t.Errorf("logLevel() level = %v, want %v", level, tt.wantLevel)
+			}
+			if level == "" {
+				return
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("logLevel() = %d, %d, want %d, %d", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
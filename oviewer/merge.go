@@ -0,0 +1,150 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// logTimePattern matches the timestamp in a log line.
+var logTimePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?` +
+	`|\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}` +
+	`|\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2} [+-]\d{4}` +
+	`|[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}`)
+
+// logTimeLayouts is a list of the layouts of the timestamps matched by logTimePattern.
+var logTimeLayouts = []string{
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05Z0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05Z0700",
+	"2006-01-02 15:04:05",
+	"2006/01/02 15:04:05",
+	"02/Jan/2006:15:04:05 -0700",
+	time.Stamp,
+}
+
+// lineTime returns the unix nano time of the first timestamp in the line.
+func lineTime(line string) (float64, bool) {
+	if strings.ContainsAny(line, "\x1b\b") {
+		line = stripEscapeSequence.ReplaceAllString(line, "")
+	}
+	s := logTimePattern.FindString(line)
+	if s == "" {
+		return 0, false
+	}
+	s = strings.Replace(s, ",", ".", 1)
+	for _, layout := range logTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return float64(t.UnixNano()), true
+		}
+	}
+	return 0, false
+}
+
+// mergeLines interleaves the lines of the sources in the order of the timestamps.
+// The lines without a timestamp follow the previous line of the same source.
+// The lines of the same time keep the order of the sources.
+// It returns the merged lines and the source index of each line.
+func mergeLines(sources [][]string) ([]string, []int) {
+	times := make([][]float64, len(sources))
+	total := 0
+	for i, lines := range sources {
+		times[i] = make([]float64, len(lines))
+		last := math.Inf(-1)
+		for j, line := range lines {
+			if t, ok := lineTime(line); ok {
+				last = t
+			}
+			times[i][j] = last
+		}
+		total += len(lines)
+	}
+
+	merged := make([]string, 0, total)
+	srcs := make([]int, 0, total)
+	pos := make([]int, len(sources))
+	for len(merged) < total {
+		next := -1
+		for i := range sources {
+			if pos[i] >= len(sources[i]) {
+				continue
+			}
+			if next < 0 || times[i][pos[i]] < times[next][pos[next]] {
+				next = i
+			}
+		}
+		merged = append(merged, sources[next][pos[next]])
+		srcs = append(srcs, next)
+		pos[next]++
+	}
+	return merged, srcs
+}
+
+// mergeDocuments adds a document that merges all documents in the order of the timestamps.
+// The derived documents (filter, sort, earlier merges and so on) are not merged,
+// and the header lines of the documents are not merged as the data.
+func (root *Root) mergeDocuments() {
+	root.mu.RLock()
+	docs := make([]*Document, 0, len(root.DocList))
+	for _, doc := range root.DocList {
+		if !doc.derived {
+			docs = append(docs, doc)
+		}
+	}
+	root.mu.RUnlock()
+	if len(docs) < 2 {
+		root.setMessage("Merge requires multiple documents")
+		return
+	}
+
+	sources := make([][]string, len(docs))
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		end := doc.BufEndNum()
+		lines := make([]string, 0, end)
+		for n := min(doc.Header, end); n < end; n++ {
+			lines = append(lines, doc.GetLine(n))
+		}
+		sources[i] = lines
+		names[i] = doc.Title()
+	}
+	lines, srcs := mergeLines(sources)
+
+	m, err := NewDocument()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for _, line := range lines {
+		m.append(line)
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	m.mergeSource = srcs
+
+	src := root.Doc
+	m.FileName = fmt.Sprintf("merge:%s", strings.Join(names, ","))
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	m.Header = 0
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("Merged %d documents %d lines", len(docs), m.BufEndNum()))
+}
+
+// mergeSourceStyle applies the style of the source document of the merged line.
+// The styles of StyleMergeSource are used in the order of the documents.
+func (root *Root) mergeSourceStyle(lc lineContents, lN int) {
+	m := root.Doc
+	styles := root.StyleMergeSource
+	if len(styles) == 0 || lN < 0 || lN >= len(m.mergeSource) {
+		return
+	}
+	root.lineStyle(lc, styles[m.mergeSource[lN]%len(styles)])
+}
@@ -0,0 +1,103 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_lineTime(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		wantOk bool
+	}{
+		{name: "iso", line: "2021-08-01 12:00:00 INFO start", wantOk: true},
+		{name: "rfc3339", line: `{"time":"2021-08-01T12:00:00.123+09:00"}`, wantOk: true},
+		{name: "comma", line: "2021-08-01 12:00:00,123 WARN x", wantOk: true},
+		{name: "syslog", line: "Aug  1 12:00:00 host sshd[1]: ok", wantOk: true},
+		{name: "clf", line: `127.0.0.1 - - [01/Aug/2021:12:00:00 +0900] "GET / HTTP/1.1"`, wantOk: true},
+		{name: "none", line: "    at main.go:12", wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := lineTime(tt.line); ok != tt.wantOk {
+				t.Errorf("lineTime() ok = %v, want %v", ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_mergeLines(t *testing.T) {
+	sources := [][]string{
+		{
+			"2021-08-01 12:00:01 a1",
+			"2021-08-01 12:00:03 a2",
+			"  trace a2",
+		},
+		{
+			"2021-08-01 12:00:00 b1",
+			"2021-08-01 12:00:03 b2",
+			"2021-08-01 12:00:04 b3",
+		},
+	}
+	gotLines, gotSrcs := mergeLines(sources)
+	wantLines := []string{
+		"2021-08-01 12:00:00 b1",
+		"2021-08-01 12:00:01 a1",
+		"2021-08-01 12:00:03 a2",
+		"  trace a2",
+		"2021-08-01 12:00:03 b2",
+		"2021-08-01 12:00:04 b3",
+	}
+	wantSrcs := []int{1, 0, 0, 0, 1, 1}
+	if !reflect.DeepEqual(gotLines, wantLines) {
+		t.Errorf("mergeLines() lines = %v, want %v", gotLines, wantLines)
+	}
+	if !reflect.DeepEqual(gotSrcs, wantSrcs) {
+		t.Errorf("mergeLines() sources = %v, want %v", gotSrcs, wantSrcs)
+	}
+}
+
+func TestRoot_mergeDocuments(t *testing.T) {
+	a := testLineDocument(t, 1, "time a", "2021-08-01 12:00:01 a1", "2021-08-01 12:00:03 a2")
+	b := testLineDocument(t, 1, "time b", "2021-08-01 12:00:02 b1")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"2021-08-01 12:00:01 a1",
+		"2021-08-01 12:00:02 b1",
+		"2021-08-01 12:00:03 a2",
+	}
+	// Merging again does not merge the merged document.
+	for i := 0; i < 2; i++ {
+		root.mergeDocuments()
+		m := root.DocList[len(root.DocList)-1]
+		got := make([]string, 0)
+		for n := 0; n < m.BufEndNum(); n++ {
+			got = append(got, m.GetLine(n))
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("mergeDocuments() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRoot_mergeSourceStyle(t *testing.T) {
+	m := testLineDocument(t, 0, "a1", "b1", "a2")
+	m.mergeSource = []int{0, 1, 0}
+	root := &Root{Doc: m}
+	root.StyleColumnRainbow = []ovStyle{{Foreground: "blue"}}
+	root.StyleMergeSource = []ovStyle{{Foreground: "red"}, {Foreground: "green"}}
+	want := []tcell.Color{tcell.ColorRed, tcell.ColorGreen, tcell.ColorRed}
+	for lN, color := range want {
+		lc := strToContents(m.GetLine(lN), 8)
+		root.mergeSourceStyle(lc, lN)
+		if fg, _, _ := lc[0].style.Decompose(); fg != color {
+			t.Errorf("mergeSourceStyle(%d) foreground = %v, want %v", lN, fg, color)
+		}
+	}
+}
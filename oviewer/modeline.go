@@ -0,0 +1,135 @@
+package oviewer
+
+import (
+	"log"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// modelineLines is the number of the first and last lines searched for a modeline.
+const modelineLines = 5
+
+// modelineRegexp matches the modeline, "ov:" followed by the settings.
+var modelineRegexp = regexp.MustCompile(`(?:^|\s)ov:\s+(.*)$`)
+
+// modelineAliases is the names of the settings in the modeline that differ from the fields.
+var modelineAliases = map[string]string{
+	"wrap":       "wrapmode",
+	"linenumber": "linenummode",
+	"truncate":   "truncatemode",
+}
+
+// modelineSetting represents one name=value setting of the modeline.
+type modelineSetting struct {
+	name  string
+	value string
+}
+
+// parseModeline returns the settings of the modeline in the line.
+// The line is like "# ov: wrap=off column-delimiter=,".
+func parseModeline(line string) ([]modelineSetting, bool) {
+	match := modelineRegexp.FindStringSubmatch(line)
+	if match == nil {
+		return nil, false
+	}
+	body := strings.TrimSpace(match[1])
+	// The end of the comment of the file format.
+	for _, suffix := range []string{"*/", "-->"} {
+		body = strings.TrimSpace(strings.TrimSuffix(body, suffix))
+	}
+	var settings []modelineSetting
+	for _, field := range strings.Fields(body) {
+		n := strings.IndexByte(field, '=')
+		if n <= 0 {
+			continue
+		}
+		settings = append(settings, modelineSetting{name: field[:n], value: field[n+1:]})
+	}
+	return settings, len(settings) > 0
+}
+
+// modelineName returns the normalized name of the setting,
+// so that "column-delimiter" and "ColumnDelimiter" are the same.
+func modelineName(name string) string {
+	name = strings.ToLower(strings.NewReplacer("-", "", "_", "").Replace(name))
+	if alias, ok := modelineAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+// modelineBool converts the on/off values of the modeline to the bool values.
+func modelineBool(value string) string {
+	switch strings.ToLower(value) {
+	case "on", "yes":
+		return "true"
+	case "off", "no":
+		return "false"
+	}
+	return value
+}
+
+// applyModeline applies the settings of the modeline to the document settings.
+// The unknown or invalid settings are ignored.
+func applyModeline(g *general, settings []modelineSetting) {
+	items := appendSettingItems(nil, "Document", "", reflect.ValueOf(g).Elem())
+	for _, s := range settings {
+		name := modelineName(s.name)
+		found := false
+		for _, item := range items {
+			if strings.ToLower(item.name) != name {
+				continue
+			}
+			found = true
+			value := s.value
+			if item.value.Kind() == reflect.Bool {
+				value = modelineBool(value)
+			}
+			if err := item.set(value); err != nil {
+				log.Printf("modeline %s: %s", s.name, err)
+			}
+		}
+		if !found {
+			log.Printf("modeline %s: unknown setting", s.name)
+		}
+	}
+}
+
+// modeline applies the modeline in the first or last lines of the document.
+// The last lines are searched only if the document has been read to the end
+// when the first lines are available. It is checked only once for each document.
+func (root *Root) modeline() {
+	m := root.Doc
+	if m.modelineChecked || root.screenMode != Docs {
+		return
+	}
+	end := m.BufEndNum()
+	if end < modelineLines && !m.BufEOF() {
+		return
+	}
+	m.modelineChecked = true
+
+	lns := make([]int, 0, modelineLines*2)
+	for n := 0; n < min(end, modelineLines); n++ {
+		lns = append(lns, n)
+	}
+	if m.BufEOF() {
+		for n := max(end-modelineLines, modelineLines); n < end; n++ {
+			lns = append(lns, n)
+		}
+	}
+	for _, n := range lns {
+		settings, ok := parseModeline(plainLine(m.GetLine(n)))
+		if !ok {
+			continue
+		}
+		log.Printf("%s: modeline %d", m.FileName, n+1)
+		applyModeline(&m.general, settings)
+		// The settings of the modeline take precedence over AutoTable.
+		m.tableChecked = true
+		root.setWrapHeaderLen()
+		m.ClearCache()
+		return
+	}
+}
@@ -0,0 +1,65 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseModeline(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   []modelineSetting
+		wantOk bool
+	}{
+		{
+			name: "comment",
+			line: "# ov: wrap=off column-delimiter=,",
+			want: []modelineSetting{
+				{name: "wrap", value: "off"},
+				{name: "column-delimiter", value: ","},
+			},
+			wantOk: true,
+		},
+		{
+			name:   "html comment",
+			line:   "<!-- ov: header=2 -->",
+			want:   []modelineSetting{{name: "header", value: "2"}},
+			wantOk: true,
+		},
+		{
+			name:   "no settings",
+			line:   "# ov: viewer",
+			wantOk: false,
+		},
+		{
+			name:   "not modeline",
+			line:   "cov: wrap=off",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseModeline(tt.line)
+			if !reflect.DeepEqual(got, tt.want) || ok != tt.wantOk {
+				t.Errorf("parseModeline() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_applyModeline(t *testing.T) {
+	g := general{WrapMode: true, TabWidth: 8}
+	applyModeline(&g, []modelineSetting{
+		{name: "wrap", value: "off"},
+		{name: "column-mode", value: "on"},
+		{name: "column-delimiter", value: ","},
+		{name: "TabWidth", value: "4"},
+		{name: "header", value: "x"},
+		{name: "unknown", value: "1"},
+	})
+	want := general{WrapMode: false, ColumnMode: true, ColumnDelimiter: ",", TabWidth: 4}
+	if !reflect.DeepEqual(g, want) {
+		t.Errorf("applyModeline() = %+v, want %+v", g, want)
+	}
+}
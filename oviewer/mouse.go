@@ -8,7 +8,6 @@ import (
 
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
-	"github.com/mattn/go-runewidth"
 )
 
 // mouseEvent handles mouse events.
@@ -25,6 +24,14 @@ func (root *Root) mouseEvent(ev *tcell.EventMouse) {
 		return
 	}
 
+	if root.shiftBypass(ev) {
+		return
+	}
+
+	if root.openLink(ev) {
+		return
+	}
+
 	if button != tcell.ButtonNone || root.mouseSelect {
 		root.selectRange(ev)
 		return
@@ -33,6 +40,29 @@ func (root *Root) mouseEvent(ev *tcell.EventMouse) {
 	root.skipDraw = true
 }
 
+// shiftBypass releases the mouse to the terminal when it is clicked with Shift held,
+// so that the text can be selected by the terminal.
+func (root *Root) shiftBypass(ev *tcell.EventMouse) bool {
+	if !root.MouseShiftBypass || ev.Modifiers()&tcell.ModShift == 0 || ev.Buttons() == tcell.ButtonNone {
+		return false
+	}
+	root.Screen.DisableMouse()
+	root.mouseReleased = true
+	root.setMessage("mouse released to the terminal (press any key to capture it)")
+	return true
+}
+
+// captureMouse captures the mouse again after it is released by shiftBypass.
+func (root *Root) captureMouse() {
+	if !root.mouseReleased {
+		return
+	}
+	root.mouseReleased = false
+	if !root.Config.DisableMouse {
+		root.Screen.EnableMouse()
+	}
+}
+
 // wheelUp moves the mouse wheel up.
 func (root *Root) wheelUp() {
 	root.setMessage("")
@@ -281,11 +311,12 @@ func (root *Root) branchWidth(lc lineContents, branch int) int {
 	i := 0
 	w := root.startX
 	x := 0
+	markerWidth := root.wrapMarkerWidth()
 	for n := 0; n < len(lc); n++ {
 		c := lc[n]
 		if w+c.width > root.vWidth {
 			i++
-			w = root.startX
+			w = root.startX + markerWidth
 		}
 		if i >= branch {
 			break
@@ -293,6 +324,10 @@ func (root *Root) branchWidth(lc lineContents, branch int) int {
 		w += c.width
 		x += c.width
 	}
+	// The wrap marker is displayed before the continuation rows.
+	if branch > 0 {
+		x -= markerWidth
+	}
 	return x
 }
 
@@ -354,13 +389,11 @@ func (root *Root) getClipboard(_ context.Context) {
 	str, err := clipboard.ReadAll()
 	if err != nil {
 		log.Printf("getClipboard: %v", err)
-		return
+		if root.register == "" {
+			return
+		}
+		str = root.register
 	}
 
-	pos := stringWidth(input.value, input.cursorX+1)
-	runes := []rune(input.value)
-	input.value = string(runes[:pos])
-	input.value += str
-	input.value += string(runes[pos:])
-	input.cursorX += runewidth.StringWidth(str)
+	input.insert(str)
 }
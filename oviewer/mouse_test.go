@@ -0,0 +1,64 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_shiftBypass(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name        string
+		shiftBypass bool
+		ev          *tcell.EventMouse
+		want        bool
+	}{
+		{
+			name:        "shift click",
+			shiftBypass: true,
+			ev:          tcell.NewEventMouse(1, 1, tcell.Button1, tcell.ModShift),
+			want:        true,
+		},
+		{
+			name:        "click",
+			shiftBypass: true,
+			ev:          tcell.NewEventMouse(1, 1, tcell.Button1, tcell.ModNone),
+			want:        false,
+		},
+		{
+			name:        "shift move",
+			shiftBypass: true,
+			ev:          tcell.NewEventMouse(1, 1, tcell.ButtonNone, tcell.ModShift),
+			want:        false,
+		},
+		{
+			name:        "disabled",
+			shiftBypass: false,
+			ev:          tcell.NewEventMouse(1, 1, tcell.Button1, tcell.ModShift),
+			want:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := NewOviewer(testLineDocument(t, 0, "a"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.MouseShiftBypass = tt.shiftBypass
+			if got := root.shiftBypass(tt.ev); got != tt.want {
+				t.Errorf("Root.shiftBypass() = %v, want %v", got, tt.want)
+			}
+			if root.mouseReleased != tt.want {
+				t.Errorf("Root.shiftBypass() mouseReleased = %v, want %v", root.mouseReleased, tt.want)
+			}
+			root.captureMouse()
+			if root.mouseReleased {
+				t.Errorf("Root.captureMouse() mouseReleased = true")
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package oviewer
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 )
 
@@ -25,6 +26,98 @@ func (root *Root) moveLine(lN int) {
 	root.Doc.topLX = 0
 }
 
+// jumpLine moves to the line lN of the document (not the line of the body)
+// and displays it at the jump target with ScrollOff lines of context above it.
+func (root *Root) jumpLine(lN int) {
+	m := root.Doc
+	// The top is not moved above the first line, so that the jump is kept after drawing.
+	root.moveLine(max(lN-m.Header-root.jumpOffset(), 0))
+	m.jumpLN = lN
+	m.jumpTopLN = m.topLN
+}
+
+// jumpColumn scrolls horizontally so that the col-th (1-based) character
+// of the line lN is displayed. It does nothing in wrap mode.
+func (root *Root) jumpColumn(lN int, col int) {
+	m := root.Doc
+	if m.WrapMode || col <= 0 {
+		return
+	}
+	lc, err := m.lineToContents(lN, m.TabWidth)
+	if err != nil {
+		return
+	}
+	x := contentsX(lc, col-1)
+	width := root.vWidth - root.startX
+	if x < width {
+		m.x = 0
+		return
+	}
+	m.x = x - width/2
+}
+
+// contentsX returns the position in the contents of the n-th (0-based) character.
+func contentsX(lc lineContents, n int) int {
+	c := 0
+	for x, content := range lc {
+		if content.mainc == 0 {
+			continue
+		}
+		if c == n {
+			return x
+		}
+		c++
+	}
+	return len(lc)
+}
+
+// jumpOffset returns the position of the jump target from the top of the body.
+// It is calculated from the current height, so it follows the resize.
+// ScrollOff lines of context are kept above and below the target.
+func (root *Root) jumpOffset() int {
+	height := root.statusPos - root.headerLen()
+	target, ok := jumpTargetHeight(root.JumpTarget, height)
+	if !ok {
+		log.Printf("invalid jump target %s", root.JumpTarget)
+	}
+	scrollOff := root.scrollOff()
+	return max(min(target, height-1-scrollOff), scrollOff)
+}
+
+// jumpTargetHeight returns the position of the jump target in the height.
+// The target is the number of lines from the top, a percentage of the height ("30%"),
+// or "center". A negative number counts from the bottom.
+// The empty string is the top.
+func jumpTargetHeight(target string, height int) (int, bool) {
+	target = strings.TrimSpace(target)
+	switch {
+	case target == "":
+		return 0, true
+	case target == "center":
+		return height / 2, true
+	case strings.HasSuffix(target, "%"):
+		p, err := strconv.Atoi(strings.TrimSuffix(target, "%"))
+		if err != nil || p < 0 || p > 100 {
+			return 0, false
+		}
+		return height * p / 100, true
+	}
+	n, err := strconv.Atoi(target)
+	if err != nil {
+		return 0, false
+	}
+	if n < 0 {
+		n += height
+	}
+	return max(n, 0), true
+}
+
+// scrollOff returns the number of lines of context above the jump target,
+// which is at most half of the body.
+func (root *Root) scrollOff() int {
+	return max(min(root.ScrollOff, (root.statusPos-root.headerLen())/2), 0)
+}
+
 // Move up one screen.
 func (root *Root) movePgUp() {
 	root.resetSelect()
@@ -0,0 +1,79 @@
+package oviewer
+
+import (
+	"log"
+	"time"
+)
+
+// The names of the measurements passed to Observer.
+const (
+	// MetricDraw is the duration of drawing the screen.
+	MetricDraw = "draw"
+	// MetricSearch is the duration of a search scan.
+	MetricSearch = "search"
+	// MetricRead is the duration of reading a document to the end.
+	MetricRead = "read"
+	// MetricAppendedLines is the number of the lines appended to the documents.
+	MetricAppendedLines = "appended_lines"
+	// MetricDroppedLines is the number of the lines dropped by the backpressure.
+	MetricDroppedLines = "dropped_lines"
+	// MetricDroppedEvents is the number of the update events that could not be posted.
+	MetricDroppedEvents = "dropped_events"
+)
+
+// Observer is the interface that receives the measurements of the viewer,
+// so that the embedding application can export them as metrics or traces.
+// The methods are called from multiple goroutines and must not block.
+type Observer interface {
+	// ObserveDuration is called with the time taken by the operation of the name.
+	ObserveDuration(name string, d time.Duration)
+	// AddCount is called with the number of the occurrences of the name.
+	AddCount(name string, n int)
+}
+
+// SetObserver sets the Observer that receives the measurements.
+func (root *Root) SetObserver(observer Observer) {
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	root.observer = observer
+	for _, doc := range root.DocList {
+		doc.setObserver(observer)
+	}
+}
+
+// observeDuration reports the duration since start.
+// In the debug mode, the duration is also written to the log.
+func (root *Root) observeDuration(name string, start time.Time) {
+	if root.observer == nil && !root.Debug {
+		return
+	}
+	d := time.Since(start)
+	if root.Debug {
+		log.Printf("%s: %v", name, d)
+	}
+	if root.observer != nil {
+		root.observer.ObserveDuration(name, d)
+	}
+}
+
+// observeCount reports the count.
+func (root *Root) observeCount(name string, n int) {
+	if root.observer == nil {
+		return
+	}
+	root.observer.AddCount(name, n)
+}
+
+// setObserver sets the Observer of the document.
+func (m *Document) setObserver(observer Observer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.observer = observer
+}
+
+// getObserver returns the Observer of the document.
+func (m *Document) getObserver() Observer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.observer
+}
@@ -0,0 +1,121 @@
+package oviewer
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testObserver records the measurements.
+type testObserver struct {
+	mu        sync.Mutex
+	counts    map[string]int
+	durations map[string]int
+}
+
+func newTestObserver() *testObserver {
+	return &testObserver{counts: make(map[string]int), durations: make(map[string]int)}
+}
+
+func (o *testObserver) ObserveDuration(name string, d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.durations[name]++
+}
+
+func (o *testObserver) AddCount(name string, n int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.counts[name] += n
+}
+
+func TestDocument_observer(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    int32
+		wantAppend  int
+		wantDropped int
+	}{
+		{
+			name:        "none",
+			strategy:    backpressureNone,
+			wantAppend:  6,
+			wantDropped: 0,
+		},
+		{
+			name:        "drop",
+			strategy:    backpressureDrop,
+			wantAppend:  3,
+			wantDropped: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			observer := newTestObserver()
+			m.setObserver(observer)
+			m.setBackpressure(tt.strategy, 2)
+			var b strings.Builder
+			for n := 0; n < 6; n++ {
+				b.WriteString(strconv.Itoa(n) + "\n")
+			}
+			if err := m.ReadAll(strings.NewReader(b.String())); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+
+			observer.mu.Lock()
+			defer observer.mu.Unlock()
+			if got := observer.counts[MetricAppendedLines]; got != tt.wantAppend {
+				t.Errorf("%s = %v, want %v", MetricAppendedLines, got, tt.wantAppend)
+			}
+			if got := observer.counts[MetricDroppedLines]; got != tt.wantDropped {
+				t.Errorf("%s = %v, want %v", MetricDroppedLines, got, tt.wantDropped)
+			}
+			if got := observer.durations[MetricRead]; got != 1 {
+				t.Errorf("%s observed %v times, want %v", MetricRead, got, 1)
+			}
+		})
+	}
+}
+
+func TestRoot_SetObserver(t *testing.T) {
+	m := testLineDocument(t, 0, "a")
+	root := &Root{Doc: m, DocList: []*Document{m}}
+	// No observer.
+	root.observeCount(MetricDroppedEvents, 1)
+
+	observer := newTestObserver()
+	root.SetObserver(observer)
+	root.observeCount(MetricDroppedEvents, 1)
+	root.observeDuration(MetricSearch, time.Now())
+	m.append("b")
+	if observer.counts[MetricDroppedEvents] != 1 || observer.durations[MetricSearch] != 1 || observer.counts[MetricAppendedLines] != 1 {
+		t.Errorf("Root.SetObserver() = %v %v", observer.counts, observer.durations)
+	}
+}
+
+func TestRoot_observeDuration_debug(t *testing.T) {
+	logDoc, err := NewLogDoc()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.SetOutput(os.Stderr)
+	root := &Root{}
+	root.observeDuration(MetricDraw, time.Now())
+	if logDoc.BufEndNum() != 0 {
+		t.Errorf("Root.observeDuration() logged without debug: %v", logDoc.GetLine(0))
+	}
+	root.Debug = true
+	root.observeDuration(MetricDraw, time.Now())
+	if logDoc.BufEndNum() != 1 || !strings.Contains(logDoc.GetLine(0), "draw: ") {
+		t.Errorf("Root.observeDuration() did not log the timing in debug mode")
+	}
+}
@@ -0,0 +1,37 @@
+package oviewer
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_openShared(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.log")
+	if err := os.WriteFile(name, []byte("line\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	f, err := openShared(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	// The open file can be rotated by the writer.
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatalf("rename the open file: %v", err)
+	}
+	got, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "line\n" {
+		t.Errorf("openShared() read %q, want %q", got, "line\n")
+	}
+
+	if _, err := openShared(filepath.Join(dir, "none")); !os.IsNotExist(err) {
+		t.Errorf("openShared() error = %v, want not exist", err)
+	}
+}
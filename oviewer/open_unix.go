@@ -0,0 +1,14 @@
+//go:build !windows
+// +build !windows
+
+package oviewer
+
+import (
+	"os"
+)
+
+// openShared opens the file for reading.
+// The other processes can write, rename and delete the open file.
+func openShared(name string) (*os.File, error) {
+	return os.Open(name)
+}
@@ -0,0 +1,28 @@
+//go:build windows
+// +build windows
+
+package oviewer
+
+import (
+	"os"
+	"syscall"
+)
+
+// openShared opens the file for reading and allows the other processes
+// to write, rename and delete it while it is open (FILE_SHARE_DELETE),
+// so that the log files being rotated can be followed.
+// os.Open does not allow the deletion and the rename.
+func openShared(name string) (*os.File, error) {
+	p, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return os.Open(name)
+	}
+	h, err := syscall.CreateFile(p, syscall.GENERIC_READ,
+		syscall.FILE_SHARE_READ|syscall.FILE_SHARE_WRITE|syscall.FILE_SHARE_DELETE,
+		nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		// Fall back to os.Open for the directories and the error message.
+		return os.Open(name)
+	}
+	return os.NewFile(uintptr(h), name), nil
+}
@@ -0,0 +1,112 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// openFileInput represents the open file input mode.
+type openFileInput struct {
+	value string
+	clist *candidate
+	pathCompletion
+	tcell.EventTime
+}
+
+// newOpenFileInput returns openFileInput.
+func newOpenFileInput(clist *candidate) *openFileInput {
+	return &openFileInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (o *openFileInput) Prompt() string {
+	return "Open file:"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (o *openFileInput) Confirm(str string) tcell.Event {
+	o.value = str
+	o.clist.list = toLast(o.clist.list, str)
+	o.clist.p = 0
+	o.SetEventNow()
+	return o
+}
+
+// Up returns strings when the up key is pressed during input.
+func (o *openFileInput) Up(str string) string {
+	return o.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (o *openFileInput) Down(str string) string {
+	return o.clist.match(str).down()
+}
+
+// setOpenFileMode sets the open file input mode.
+func (root *Root) setOpenFileMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = OpenFile
+	input.EventInput = newOpenFileInput(input.OpenFileCandidate)
+}
+
+// noFileDocument returns the empty document displayed
+// until a file is opened when started without a file and input.
+func noFileDocument() (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	m.FileName = "(no file)"
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	return m, nil
+}
+
+// openFile opens the file as a new document.
+// If the file is already open, it switches to the document.
+// The empty document of the start without a file is closed.
+func (root *Root) openFile(fileName string) {
+	if root.restricted() {
+		return
+	}
+	if fileName == "" {
+		return
+	}
+	if num := root.documentNum(fileName); num >= 0 {
+		root.setDocumentNum(num)
+		return
+	}
+	fi, err := os.Stat(fileName)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	if fi.IsDir() {
+		root.setMessage(fmt.Sprintf("%s is a directory", fileName))
+		return
+	}
+	m, err := NewDocument()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	if err := m.ReadFile(fileName); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	root.addDocument(m)
+	if noFile := root.noFileDoc; noFile != nil {
+		root.noFileDoc = nil
+		root.closeDocuments(func(m *Document) bool {
+			return m == noFile
+		})
+	}
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("open %s", fileName))
+}
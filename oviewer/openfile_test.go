@@ -0,0 +1,50 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_openFile(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name      string
+		fileName  string
+		wantLen   int
+		wantFile  string
+		wantNoDoc bool
+	}{
+		{name: "file", fileName: "../README.md", wantLen: 1, wantFile: "../README.md", wantNoDoc: false},
+		{name: "not exist", fileName: "../not-exist.txt", wantLen: 1, wantFile: "(no file)", wantNoDoc: true},
+		{name: "directory", fileName: "..", wantLen: 1, wantFile: "(no file)", wantNoDoc: true},
+		{name: "empty", fileName: "", wantLen: 1, wantFile: "(no file)", wantNoDoc: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := noFileDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			root, err := NewOviewer(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root.noFileDoc = m
+			root.prepareView()
+			root.openFile(tt.fileName)
+			if got := root.DocumentLen(); got != tt.wantLen {
+				t.Errorf("Root.DocumentLen() = %v, want %v", got, tt.wantLen)
+			}
+			if got := root.Doc.FileName; got != tt.wantFile {
+				t.Errorf("Root.Doc.FileName = %v, want %v", got, tt.wantFile)
+			}
+			if got := root.noFileDoc != nil; got != tt.wantNoDoc {
+				t.Errorf("Root.noFileDoc = %v, want %v", got, tt.wantNoDoc)
+			}
+		})
+	}
+}
@@ -8,13 +8,17 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"regexp"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"code.rocketnine.space/tslocum/cbind"
 	"github.com/fsnotify/fsnotify"
 	"github.com/gdamore/tcell/v2"
+	"golang.org/x/term"
 )
 
 // Root structure contains information about the drawing.
@@ -30,10 +34,16 @@ type Root struct {
 	helpDoc *Document
 	// log
 	logDoc *Document
+	// settings
+	settingsDoc *Document
 
 	// DocList
 	DocList    []*Document
 	CurrentDoc int
+
+	// Restricted disables the actions that run commands, read or write files
+	// or stop the process on the host, such as for the remote sessions.
+	Restricted bool
 	// mu controls the RWMutex.
 	mu sync.RWMutex
 
@@ -43,6 +53,8 @@ type Root struct {
 	input *Input
 	// keyConfig contains the binding settings for the key.
 	keyConfig *cbind.Configuration
+	// chords is the bindings of the key sequences.
+	chords *chordBinds
 
 	// message is the message to display.
 	message string
@@ -62,6 +74,14 @@ type Root struct {
 	// skipDraw skips draw once when true.
 	// skipDraw is set to true when the mouse cursor just moves (no event occurs).
 	skipDraw bool
+	// updatePending is 1 while the update event of the appended lines is waiting to be handled.
+	updatePending int32
+	// lastDraw is the time of the last draw for MaxFPS.
+	lastDraw time.Time
+	// drawPending is true while the draw delayed by MaxFPS is waiting.
+	drawPending bool
+	// scrolling is the smooth scroll in progress.
+	scrolling *smoothScrolling
 
 	// x1, y1, x2, y2 are the coordinates selected by the mouse.
 	x1 int
@@ -71,6 +91,29 @@ type Root struct {
 
 	// mousePressed is a flag when the mouse selection button is pressed.
 	mousePressed bool
+	// mouseReleased is true if the mouse is released to the terminal by MouseShiftBypass.
+	mouseReleased bool
+
+	// readAheadDoc and readAheadLN are the document and the top line of the last read-ahead.
+	readAheadDoc *Document
+	readAheadLN  int
+
+	// running is 1 while Run is running, and runDone is closed when Run returns.
+	running int32
+	runDone chan struct{}
+	// exitMatched is 1 if the viewer quit on the match of ExitPattern.
+	exitMatched int32
+
+	// lineBuf is the buffer of the contents of the line being drawn,
+	// reused to avoid the allocation for each line of each frame.
+	lineBuf lineContents
+
+	// noFileDoc is the empty document displayed until a file is opened
+	// when started without a file and input.
+	noFileDoc *Document
+
+	// marks is the lines that match the search for the scroll bar.
+	marks matchMarks
 	// mouseSelect is a flag with mouse selection.
 	mouseSelect bool
 	// mouseRectangle is a flag for rectangle selection.
@@ -78,6 +121,24 @@ type Root struct {
 
 	// wrapHeaderLen is the actual header length when wrapped.
 	wrapHeaderLen int
+	// wrapHeaderKey is the state when wrapHeaderLen was calculated.
+	wrapHeaderKey wrapHeaderKey
+	// headerNum is the number of header lines displayed in the current section.
+	headerNum int
+	// headerLineStart is the line of HeaderLine, or -1 if not displayed.
+	headerLineStart int
+	// headerLineLen is the actual length of HeaderLine.
+	headerLineLen int
+	// sectionHeaderStart is the first line of the section header, or -1 if not displayed.
+	sectionHeaderStart int
+	// sectionHeaderNum is the number of lines of the section header.
+	sectionHeaderNum int
+	// sectionHeaderLen is the actual section header length.
+	sectionHeaderLen int
+	// sectionRuleRegs is the compiled patterns of SectionRules.
+	sectionRuleRegs map[string]*regexp.Regexp
+	// errorRegs is the compiled patterns of ErrorPatterns.
+	errorRegs []*regexp.Regexp
 
 	// bottomLN is the last line number displayed.
 	bottomLN int
@@ -91,6 +152,51 @@ type Root struct {
 
 	// cancelKeys represents the cancellation key string.
 	cancelKeys []string
+	// reloadKey is the key to reload the file changed on disk.
+	reloadKey string
+	// delimiterKey is the key to enter the delimiter.
+	delimiterKey string
+
+	// watch is the command run periodically by WatchCommand.
+	watch *watchCommand
+	// scrollBind is the scroll-bind mode, or nil if it is off.
+	scrollBind *scrollBind
+
+	// count is the count prefix entered before the command.
+	count int
+	// searchWrapped is true if the last search wrapped around.
+	searchWrapped bool
+	// sortType is the comparison type of the sort.
+	sortType sortType
+	// columnSearch restricts the search and filter to the selected column in column mode.
+	columnSearch bool
+	// sectionSearch restricts the search to the current section.
+	sectionSearch bool
+	// termBackground is the background of the terminal, "light", "dark" or unknown.
+	termBackground string
+	// pinned is the pinned search highlights.
+	pinned []pinnedHighlight
+	// register is the last yanked string.
+	register string
+	// pendingLocation is the location of the opened file moved to when it is read.
+	pendingLocation *pendingLocation
+	// filterCancel stops counting the lines of the filter preview.
+	filterCancel context.CancelFunc
+
+	// saveConfig is a function that writes the settings to the config file.
+	saveConfig func(map[string]interface{}) error
+	// changedSettings is the keys of the settings changed by set_option.
+	changedSettings map[string]bool
+	// loadConfig is a function that reads the config file again.
+	loadConfig func() (Config, error)
+	// isSet is a function that returns true if the setting is given explicitly.
+	isSet func(key string) bool
+	// clock is the time source of the periodic processing.
+	clock Clock
+	// observer receives the measurements.
+	observer Observer
+	// searcherFunc is a function that returns the custom Searcher of the search string.
+	searcherFunc func(str string) Searcher
 }
 
 // LineNumber is Number of logical lines and number of wrapping lines on the screen.
@@ -105,16 +211,49 @@ type general struct {
 	TabWidth int
 	// HeaderLen is number of header rows to be fixed.
 	Header int
+	// HeaderLine is the line number (from 1) of the header row that is displayed at the top
+	// after it scrolls out, such as the header of a table after a banner (0 is none).
+	HeaderLine int
 	// Color to alternate rows
 	AlternateRows bool
+	// AlternateEvery is the interval of the rows to be colored.
+	AlternateEvery int
 	// Column mode
 	ColumnMode bool
+	// Color each column with StyleColumnRainbow.
+	ColumnRainbow bool
+	// ColumnBand highlights the selected column as a vertical band.
+	ColumnBand bool
 	// Line Number
 	LineNumMode bool
+	// DimUnmatched dims the lines that do not match the search.
+	DimUnmatched bool
+	// CursorLine highlights the current line (the line moved to by a search or goto).
+	CursorLine bool
 	// Wrap is Wrap mode.
 	WrapMode bool
+	// TruncateMode displays the marker at the end of the lines that do not fit (when not wrapping).
+	TruncateMode bool
+	// CursorMove applies the carriage return and the cursor movement escape sequences in a line.
+	CursorMove bool
+	// CollapseCR displays only the last state of the line rewritten by carriage returns.
+	CollapseCR bool
 	// Column Delimiter
 	ColumnDelimiter string
+	// SectionDelimiter is a regular expression that matches the first line of a section.
+	SectionDelimiter string
+	// SectionHeaderNum is the number of lines of the section header displayed at the top.
+	SectionHeaderNum int
+	// LogLevel highlights the log level of the lines with StyleLogLevel.
+	LogLevel bool
+	// UnusualSpace highlights the full-width spaces, the non-breaking spaces
+	// and the other invisible whitespace with StyleUnusualSpace.
+	UnusualSpace bool
+	// Timestamp prefixes the lines appended in follow mode with their arrival time.
+	Timestamp bool
+	// MaxLines is the number of the most recent lines retained in the buffer (0 is unlimited).
+	// The oldest lines are dropped as new lines arrive.
+	MaxLines int
 	// Follow mode.
 	FollowMode bool
 	// Follow all.
@@ -139,6 +278,54 @@ type Config struct {
 	StyleSearchHighlight ovStyle
 	// StyleColumnHighlight is the style that applies to the column highlight.
 	StyleColumnHighlight ovStyle
+	// StyleUnmatched is the style that applies to the lines that do not match the search.
+	StyleUnmatched ovStyle
+	// StyleColumnBand is the style that applies to the column band.
+	StyleColumnBand ovStyle
+	// StyleCursorLine is the style that applies to the current line.
+	StyleCursorLine ovStyle
+	// StyleWrapMarker is the style that applies to the wrap marker and the truncate marker.
+	StyleWrapMarker ovStyle
+	// StyleTimestamp is the style that applies to the arrival time of the lines.
+	StyleTimestamp ovStyle
+	// StyleFileSeparator is the style that applies to the separator lines of the concatenated files.
+	StyleFileSeparator ovStyle
+	// StyleDiffAdd is the style that applies to the added lines of the diff.
+	StyleDiffAdd ovStyle
+	// StyleDiffDelete is the style that applies to the deleted lines of the diff.
+	StyleDiffDelete ovStyle
+	// StyleDiffChange is the style that applies to the changed lines of the diff.
+	StyleDiffChange ovStyle
+	// StyleDiffChar is the style that applies to the changed characters within the changed lines
+	// of the diff and the watch command.
+	StyleDiffChar ovStyle
+	// StyleSelectedLine is the style that applies to the selected lines.
+	StyleSelectedLine ovStyle
+	// StyleWatchChange is the style that applies to the lines changed from the previous run of the watch command.
+	StyleWatchChange ovStyle
+	// StyleReplace is the style that applies to the replaced text of the replace mode.
+	StyleReplace ovStyle
+	// StyleFileLink is the style that applies to the file:line references with FileLinks.
+	StyleFileLink ovStyle
+	// StylePrompt is the style that applies to the prompt of the input modes.
+	StylePrompt ovStyle
+	// StylePromptError is the style that applies to the prompt when the regular expression is invalid.
+	StylePromptError ovStyle
+	// StyleScrollBar is the style that applies to the thumb of the scroll bar.
+	StyleScrollBar ovStyle
+	// StyleScrollBarMatch is the style that applies to the marks of the search matches in the scroll bar.
+	StyleScrollBarMatch ovStyle
+	// StyleUnusualSpace is the style that applies to the unusual spaces with UnusualSpace.
+	StyleUnusualSpace ovStyle
+	// StyleLogLevel is the styles that apply to the log levels
+	// (trace, debug, info, warn, error and fatal).
+	StyleLogLevel map[string]ovStyle
+	// StyleColumnRainbow is the ordered palette of styles that applies to the columns.
+	StyleColumnRainbow []ovStyle
+	// StylePinnedHighlight is the ordered styles that apply to the pinned search highlights.
+	StylePinnedHighlight []ovStyle
+	// StyleMergeSource is the ordered styles that apply to the lines of the merged documents.
+	StyleMergeSource []ovStyle
 
 	// Old setting method.
 	// Alternating background color.
@@ -153,19 +340,132 @@ type Config struct {
 	// General represents the general behavior.
 	General general
 	// Mode represents the operation of the customized mode.
-	Mode map[string]general
+	Mode map[string]ModeConfig
+	// ModeRules is a list of rules that select the view mode when a document is opened.
+	ModeRules []ViewModeRule
+	// SectionRules is a list of rules that override the header settings per section.
+	SectionRules []SectionRule
+	// ErrorPatterns is a list of the patterns of the error lines
+	// with the named groups file, line and optionally col.
+	ErrorPatterns []string
+	// Prompts replaces the prompts of the input modes (search, filter, goto, tabwidth, etc.).
+	// {default}, {file}, {header}, {tabwidth} and {delimiter} are replaced with the current values.
+	Prompts map[string]string
+	// FileLinks highlights the file:line references with StyleFileLink,
+	// and alt+click on them opens the file at the line.
+	FileLinks bool
+
+	// ScrollBar displays the scroll bar in the right column of the screen,
+	// with the marks of the search matches.
+	ScrollBar bool
 
 	// Mouse support disable.
 	DisableMouse bool
+	// PasteLiteral escapes the metacharacters of the text pasted into the search,
+	// so that it is searched literally.
+	PasteLiteral bool
+	// MouseShiftBypass releases the mouse to the terminal when it is clicked with Shift held,
+	// so that the text can be selected by the terminal. A key press captures it again.
+	MouseShiftBypass bool
 	// AfterWrite writes the current screen on exit.
 	AfterWrite bool
+	// SelectOutput writes the selected lines on exit instead of the current screen.
+	SelectOutput bool
 	// QuiteSmall Quit if the output fits on one screen.
 	QuitSmall bool
+	// QuitSmallWait is the time in milliseconds to wait for the end of the input
+	// before checking QuitSmall.
+	QuitSmallWait int
+	// KeepEmpty stays open with a message on the empty input, even with QuitSmall.
+	KeepEmpty bool
+	// ExitPattern is the pattern whose match is reported by PatternMatched on exit.
+	ExitPattern string
+	// QuitOnMatch quits as soon as ExitPattern matches.
+	QuitOnMatch bool
+	// Monochrome displays without the colors of the escape sequences and the styles.
+	Monochrome bool
+	// MonochromeAttributes keeps the attributes such as bold and underline in Monochrome.
+	MonochromeAttributes bool
+	// Background selects the default styles for the "light" or "dark" background.
+	// The background color of the terminal is detected if it is empty.
+	Background string
+	// MaxColors is the number of the colors of the terminal (8, 16 or 256).
+	// The other colors are converted to the nearest color of them.
+	// 0 uses the number reported by the terminal.
+	MaxColors int
 	// CaseSensitive is case-sensitive if true
 	CaseSensitive bool
+	// WrapMarker is displayed at the beginning of the continuation rows of a wrapped line.
+	WrapMarker string
+	// WrapIndent is the number of spaces after the wrap marker.
+	WrapIndent int
+	// TruncateMarker is displayed at the end of a truncated line in truncate mode.
+	TruncateMarker string
+	// LogLevelLine applies the style of the log level to the whole line instead of the level token.
+	LogLevelLine bool
+	// TimestampFormat is the time format of the arrival time of the lines.
+	TimestampFormat string
+	// SearchWrap continues the search from the other end when no more match.
+	SearchWrap bool
+	// JumpTarget is the position of the line moved to by search, goto line and errors:
+	// the number of lines from the top (negative from the bottom), a percentage ("30%") or "center".
+	JumpTarget string
+	// ScrollOff is the number of lines of context displayed above the line
+	// moved to by search, goto line and errors.
+	ScrollOff int
+	// RegexpSearch searches with the regular expression.
+	// The search string is searched literally if it is false.
+	RegexpSearch bool
+	// UpdateInterval is the interval in milliseconds to check the appended lines and redraw.
+	// It is the maximum frequency of the redraw by the appended lines (default 100).
+	UpdateInterval int
+	// MaxFPS is the maximum number of the redraws per second (0 is unlimited).
+	// The redraws of a burst of events are coalesced, and the last state is always drawn.
+	MaxFPS int
+	// FollowPollInterval is the interval in milliseconds to poll the followed files
+	// in addition to the notification of the changes (0 disables polling).
+	FollowPollInterval int
+	// ReadBufferSize is the size in bytes of the buffer to read the followed files
+	// (0 is the default size).
+	ReadBufferSize int
+	// ChunkSize is the size in bytes of the chunk buffers that the lines read are stored in (0 is 64KB).
+	ChunkSize int
+	// ReadAhead is the number of the screens of the lines before and after the screen
+	// whose contents are prepared in the background (0 disables it).
+	ReadAhead int
+	// Backpressure is the strategy when the lines arrive faster than they are displayed:
+	// "block", "drop" or "sample" ("" appends all lines).
+	Backpressure string
+	// BackpressureLines is the number of the lines that can be appended between the updates of the screen.
+	BackpressureLines int
+	// Modeline applies the document settings of the "ov:" modeline
+	// in the first or last lines of the document.
+	Modeline bool
+	// AutoTable detects the bordered table output of psql and mysql,
+	// and sets the header and the column mode.
+	AutoTable bool
+	// WatchInterval is the interval in seconds to run the command again in the watch mode.
+	WatchInterval int
+	// WatchAppend appends the output of each run in the watch mode instead of replacing it.
+	WatchAppend bool
+	// SmoothScroll is the duration in milliseconds of the smooth scroll of the page movements.
+	// 0 disables the smooth scroll.
+	SmoothScroll int
 	// Debug represents whether to enable the debug output.
 	Debug bool
-
+	// HistoryFile is the file to save the input history.
+	// If empty, the input history is not saved.
+	HistoryFile string
+	// HistoryMax is the maximum number of entries kept in each input history.
+	HistoryMax int
+	// RememberPosition restores the last position and modes of the files
+	// when they are opened again.
+	RememberPosition bool
+	// PositionFile is the file to save the last positions of the files.
+	PositionFile string
+
+	// KeybindPreset is the name of the key binding preset ("default", "vi" or "emacs").
+	KeybindPreset string
 	// KeyBinding
 	Keybind map[string][]string
 }
@@ -209,6 +509,10 @@ const (
 	Help
 	// LogDoc is Error screen mode.
 	LogDoc
+	// Settings is Settings screen mode.
+	Settings
+	// Record is the screen mode of the transposed row.
+	Record
 )
 
 var (
@@ -228,6 +532,14 @@ var (
 	ErrFailedKeyBind = errors.New("failed to set keybind")
 	// ErrSignalCatch indicates that the signal has been caught.
 	ErrSignalCatch = errors.New("signal catch")
+	// ErrModeInheritLoop indicates that the view mode inherits itself.
+	ErrModeInheritLoop = errors.New("view mode inheritance loop")
+	// ErrInvalidSubstitution indicates an invalid s/pattern/replacement/ of the replace mode.
+	ErrInvalidSubstitution = errors.New("invalid substitution")
+	// ErrInvalidBackpressure indicates an invalid strategy of Backpressure.
+	ErrInvalidBackpressure = errors.New("invalid backpressure")
+	// ErrNotSupported indicates that the operation is not supported on the platform.
+	ErrNotSupported = errors.New("not supported")
 )
 
 var tcellNewScreen = tcell.NewScreen
@@ -235,11 +547,32 @@ var tcellNewScreen = tcell.NewScreen
 // NewOviewer return the structure of oviewer.
 // NewOviewer requires one or more documents.
 func NewOviewer(docs ...*Document) (*Root, error) {
+	root, err := newRoot(docs...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The terminal is queried before the screen takes over it.
+	root.termBackground = termBackground()
+
+	screen, err := tcellNewScreen()
+	if err != nil {
+		return nil, err
+	}
+	if err := root.setScreen(screen); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// newRoot returns the structure of oviewer without the screen.
+func newRoot(docs ...*Document) (*Root, error) {
 	if len(docs) == 0 {
 		return nil, ErrNotFound
 	}
 	root := &Root{
 		minStartX: -10,
+		clock:     realClock{},
 	}
 	root.Config = NewConfig()
 	root.keyConfig = cbind.NewConfiguration()
@@ -248,16 +581,23 @@ func NewOviewer(docs ...*Document) (*Root, error) {
 	root.input = NewInput()
 	root.screenMode = Docs
 
-	screen, err := tcellNewScreen()
+	// The log is written to the log document from here,
+	// so that it does not corrupt the screen.
+	logDoc, err := NewLogDoc()
 	if err != nil {
 		return nil, err
 	}
+	root.logDoc = logDoc
+	return root, nil
+}
+
+// setScreen initializes the screen and sets it as the drawing target.
+func (root *Root) setScreen(screen tcell.Screen) error {
 	if err := screen.Init(); err != nil {
-		return nil, fmt.Errorf("Screen.Init(): %w", err)
+		return fmt.Errorf("Screen.Init(): %w", err)
 	}
 	root.Screen = screen
-
-	return root, nil
+	return nil
 }
 
 // NewConfig return the structure of Config with default values.
@@ -284,9 +624,107 @@ func NewConfig() Config {
 		StyleColumnHighlight: ovStyle{
 			Reverse: true,
 		},
+		StyleUnmatched: ovStyle{
+			Dim: true,
+		},
+		StyleColumnBand: ovStyle{
+			Background: "#303030",
+		},
+		StyleWrapMarker: ovStyle{
+			Foreground: "gray",
+		},
+		StyleCursorLine: ovStyle{
+			Underline: true,
+		},
+		StyleTimestamp: ovStyle{
+			Foreground: "green",
+		},
+		StyleFileSeparator: ovStyle{
+			Bold:      true,
+			Underline: true,
+		},
+		StyleDiffAdd: ovStyle{
+			Foreground: "green",
+		},
+		StyleDiffDelete: ovStyle{
+			Foreground: "red",
+		},
+		StyleDiffChange: ovStyle{
+			Foreground: "yellow",
+		},
+		StyleDiffChar: ovStyle{
+			Reverse: true,
+		},
+		StyleSelectedLine: ovStyle{
+			Background: "#303060",
+		},
+		StyleWatchChange: ovStyle{
+			Bold:       true,
+			Foreground: "yellow",
+		},
+		StyleReplace: ovStyle{
+			Foreground: "yellow",
+			Underline:  true,
+		},
+		StyleFileLink: ovStyle{
+			Underline: true,
+		},
+		StylePromptError: ovStyle{
+			Foreground: "red",
+		},
+		StyleUnusualSpace: ovStyle{
+			Background: "darkred",
+		},
+		StyleScrollBar: ovStyle{
+			Reverse: true,
+		},
+		StyleScrollBarMatch: ovStyle{
+			Foreground: "yellow",
+		},
+		StyleLogLevel: map[string]ovStyle{
+			"trace": {Dim: true},
+			"debug": {Foreground: "gray"},
+			"info":  {Foreground: "green"},
+			"warn":  {Foreground: "yellow"},
+			"error": {Foreground: "red"},
+			"fatal": {Foreground: "red", Bold: true, Reverse: true},
+		},
+		StyleColumnRainbow: []ovStyle{
+			{Foreground: "crimson"},
+			{Foreground: "aqua"},
+			{Foreground: "lightsalmon"},
+			{Foreground: "lime"},
+			{Foreground: "blue"},
+			{Foreground: "yellowgreen"},
+		},
+		StylePinnedHighlight: []ovStyle{
+			{Foreground: "black", Background: "yellow"},
+			{Foreground: "black", Background: "aqua"},
+			{Foreground: "black", Background: "lime"},
+			{Foreground: "black", Background: "fuchsia"},
+		},
+		StyleMergeSource: []ovStyle{
+			{Foreground: "aqua"},
+			{Foreground: "lightsalmon"},
+			{Foreground: "lime"},
+			{Foreground: "violet"},
+		},
 		General: general{
-			TabWidth: 8,
+			TabWidth:       8,
+			AlternateEvery: 2,
 		},
+		HistoryMax:           100,
+		RememberPosition:     true,
+		TruncateMarker:       ">",
+		TimestampFormat:      "15:04:05",
+		BackpressureLines:    10000,
+		ReadAhead:            1,
+		RegexpSearch:         true,
+		Modeline:             true,
+		MouseShiftBypass:     true,
+		PasteLiteral:         true,
+		MonochromeAttributes: true,
+		ErrorPatterns:        defaultErrorPatterns,
 	}
 }
 
@@ -312,6 +750,20 @@ func NewRoot(read io.Reader) (*Root, error) {
 
 func openSTDIN() (*Root, error) {
 	docList := make([]*Document, 0, 1)
+	// Prompt for a file instead of waiting for the input from the terminal.
+	if term.IsTerminal(0) {
+		m, err := noFileDocument()
+		if err != nil {
+			return nil, err
+		}
+		root, err := NewOviewer(m)
+		if err != nil {
+			return nil, err
+		}
+		root.noFileDoc = m
+		return root, nil
+	}
+
 	m, err := NewDocument()
 	if err != nil {
 		return nil, err
@@ -398,7 +850,13 @@ func (root *Root) SetWatcher(watcher *fsnotify.Watcher) {
 }
 
 func (root *Root) setKeyConfig() (map[string][]string, error) {
-	keyBind := GetKeyBinds(root.Config.Keybind)
+	keyBind, err := GetPresetKeyBinds(root.Config.KeybindPreset, root.Config.Keybind)
+	if err != nil {
+		return nil, err
+	}
+	if root.Restricted {
+		restrictKeyBinds(keyBind)
+	}
 	if err := root.setKeyBind(keyBind); err != nil {
 		return nil, err
 	}
@@ -409,6 +867,12 @@ func (root *Root) setKeyConfig() (map[string][]string, error) {
 	} else {
 		root.cancelKeys = keys
 	}
+	if keys := keyBind[actionReload]; len(keys) > 0 {
+		root.reloadKey = keys[0]
+	}
+	if keys := keyBind[actionDelimiter]; len(keys) > 0 {
+		root.delimiterKey = keys[0]
+	}
 	return keyBind, nil
 }
 
@@ -427,8 +891,25 @@ func NewHelp(k KeyBind) (*Document, error) {
 	return help, err
 }
 
+// setModeCandidates sets the view mode names to the candidates.
+func (root *Root) setModeCandidates() {
+	list := make([]string, 0, len(root.Config.Mode)+1)
+	list = append(list, "general")
+	for name := range root.Config.Mode {
+		list = append(list, name)
+	}
+	root.input.ModeCandidate.list = list
+	root.input.ModeCandidate.p = 0
+}
+
 // Run starts the terminal pager.
 func (root *Root) Run() error {
+	root.runDone = make(chan struct{})
+	atomic.StoreInt32(&root.running, 1)
+	defer func() {
+		atomic.StoreInt32(&root.running, 0)
+		close(root.runDone)
+	}()
 	defer root.Close()
 
 	watcher, err := fsnotify.NewWatcher()
@@ -449,15 +930,19 @@ func (root *Root) Run() error {
 	}
 	root.helpDoc = help
 
-	logDoc, err := NewLogDoc()
-	if err != nil {
-		return err
+	if err := root.loadHistory(); err != nil {
+		log.Printf("load history: %v", err)
 	}
-	root.logDoc = logDoc
+	defer func() {
+		if err := root.saveHistory(); err != nil {
+			log.Printf("save history: %v", err)
+		}
+	}()
 
 	if !root.Config.DisableMouse {
 		root.Screen.EnableMouse()
 	}
+	root.Screen.EnablePaste()
 
 	// Call from man command.
 	manPN := os.Getenv("MAN_PN")
@@ -470,26 +955,42 @@ func (root *Root) Run() error {
 	for n, doc := range root.DocList {
 		log.Printf("open [%d]%s", n, doc.FileName)
 		doc.general = root.Config.General
+		root.setReadOptions(doc)
+		root.applyModeRules(doc)
+	}
+	if err := root.restorePositions(); err != nil {
+		log.Printf("restore position: %v", err)
 	}
+	defer func() {
+		if err := root.savePositions(); err != nil {
+			log.Printf("save position: %v", err)
+		}
+	}()
+	root.applyBackground()
 	root.setGlobalStyle()
 	root.Screen.Clear()
 
-	list := make([]string, 0, len(root.Config.Mode)+1)
-	list = append(list, "general")
-	for name := range root.Config.Mode {
-		list = append(list, name)
-	}
-	root.input.ModeCandidate.list = list
+	root.setModeCandidates()
 
 	root.ViewSync()
 	// Exit if fits on screen
-	if root.QuitSmall && root.docSmall() {
-		root.AfterWrite = true
-		return nil
+	if root.QuitSmall && root.noFileDoc == nil {
+		root.waitEOF(time.Duration(root.QuitSmallWait) * time.Millisecond)
+		if root.docSmall() && !(root.KeepEmpty && root.docEmpty()) {
+			root.AfterWrite = true
+			return nil
+		}
+	}
+	if root.KeepEmpty && root.docEmpty() {
+		root.setMessage("empty input")
+	}
+	if root.noFileDoc != nil {
+		root.setOpenFileMode()
 	}
 
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGINT)
+	defer signal.Stop(sigs)
 
 	quitChan := make(chan struct{})
 
@@ -498,12 +999,26 @@ func (root *Root) Run() error {
 	defer cancel()
 
 	go root.main(ctx, quitChan)
+	if root.QuitOnMatch {
+		go root.quitOnMatch(ctx)
+	}
+	if root.watch != nil {
+		go root.watchLoop(ctx)
+	}
+	if root.FollowPollInterval > 0 {
+		go root.pollFollow(ctx)
+	}
 
 	for {
 		select {
 		case <-quitChan:
 			return nil
 		case sig := <-sigs:
+			// SIGHUP reloads the config if possible.
+			if sig == syscall.SIGHUP && root.loadConfig != nil {
+				root.ReloadConfig()
+				continue
+			}
 			return fmt.Errorf("%w [%s]", ErrSignalCatch, sig)
 		}
 	}
@@ -514,6 +1029,35 @@ func (root *Root) Close() {
 	root.Screen.Fini()
 }
 
+// Shutdown stops the event loop and the reading of the documents,
+// and writes the lines of the current document read so far to w if w is not nil.
+// It can be called from another goroutine while Run is running,
+// and it returns after Run has returned and the screen has been finished.
+// It is for the programs that embed the viewer and switch to the plain output.
+func (root *Root) Shutdown(w io.Writer) error {
+	if atomic.LoadInt32(&root.running) == 1 {
+		ev := &eventAppQuit{shutdown: true}
+		ev.SetEventNow()
+		go func() {
+			root.Screen.PostEventWait(ev)
+		}()
+		<-root.runDone
+	}
+
+	var err error
+	if w != nil {
+		err = root.Doc.Export(w)
+	}
+
+	root.mu.RLock()
+	docs := append([]*Document(nil), root.DocList...)
+	root.mu.RUnlock()
+	for _, m := range docs {
+		m.stopReading()
+	}
+	return err
+}
+
 func (root *Root) setMessage(msg string) {
 	if root.message == msg {
 		return
@@ -611,6 +1155,10 @@ func (root *Root) prepareView() {
 	// Do not allow size 0.
 	root.vWidth = max(root.vWidth, 1)
 	root.vHight = max(root.vHight, 1)
+	// The right column is the scroll bar.
+	if root.ScrollBar && root.vWidth > 1 {
+		root.vWidth--
+	}
 
 	root.lnumber = make([]lineNumber, root.vHight+1)
 	root.setWrapHeaderLen()
@@ -642,6 +1190,25 @@ func (root *Root) docSmall() bool {
 	return true
 }
 
+// waitEOF waits until the input of the document ends, up to timeout.
+// It does not wait if the lines are already more than the screen.
+func (root *Root) waitEOF(timeout time.Duration) {
+	m := root.Doc
+	deadline := time.Now().Add(timeout)
+	for !m.BufEOF() && m.BufEndNum() <= root.vHight && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// docEmpty returns true if the only document has ended without any lines.
+func (root *Root) docEmpty() bool {
+	if len(root.DocList) > 1 {
+		return false
+	}
+	m := root.Doc
+	return m.BufEOF() && m.BufEndNum() == 0
+}
+
 // WriteOriginal writes to the original terminal.
 func (root *Root) WriteOriginal() {
 	m := root.Doc
@@ -654,6 +1221,13 @@ func (root *Root) WriteOriginal() {
 	}
 }
 
+// WriteSelected writes the selected lines of the current document to stdout.
+func (root *Root) WriteSelected() {
+	if err := root.Doc.writeSelected(os.Stdout); err != nil {
+		log.Println(err)
+	}
+}
+
 // WriteLog write to the log terminal.
 func (root *Root) WriteLog() {
 	maxWriteLog := 10
@@ -671,9 +1245,9 @@ func (root *Root) WriteLog() {
 // headerLen returns the actual number of lines in the header.
 func (root *Root) headerLen() int {
 	if root.Doc.WrapMode {
-		return root.wrapHeaderLen
+		return root.wrapHeaderLen + root.headerLineLen + root.sectionHeaderLen
 	}
-	return root.Doc.Header
+	return root.headerNum + root.headerLineLen + root.sectionHeaderLen
 }
 
 // leftMostX returns a list of left - most x positions when wrapping.
@@ -685,9 +1259,11 @@ func (root *Root) leftMostX(lN int) ([]int, error) {
 
 	listX := make([]int, 0, (len(lc)/root.vWidth)+1)
 	width := (root.vWidth - root.startX)
+	// The continuation rows are narrower by the wrap marker.
+	nextWidth := width - root.wrapMarkerWidth()
 
 	listX = append(listX, 0)
-	for n := width; n < len(lc); n += width {
+	for n := width; n < len(lc); n += nextWidth {
 		if lc[n-1].width == 2 {
 			n--
 		}
@@ -1,8 +1,12 @@
 package oviewer
 
 import (
+	"bytes"
+	"os"
 	"reflect"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -83,3 +87,79 @@ func TestRoot_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestRoot_docEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		eof   bool
+		want  bool
+	}{
+		{name: "empty", lines: nil, eof: true, want: true},
+		{name: "not ended", lines: nil, eof: false, want: false},
+		{name: "lines", lines: []string{"a"}, eof: true, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, 0, tt.lines...)
+			if tt.eof {
+				atomic.StoreInt32(&m.eof, 1)
+			}
+			root := &Root{Doc: m, DocList: []*Document{m}}
+			if got := root.docEmpty(); got != tt.want {
+				t.Errorf("Root.docEmpty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_Shutdown(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	tests := []struct {
+		name string
+		run  bool
+	}{
+		{name: "running", run: true},
+		{name: "not running", run: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := Open("../testdata/test.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-root.Doc.eofCh
+			runErr := make(chan error, 1)
+			if tt.run {
+				go func() {
+					runErr <- root.Run()
+				}()
+				for atomic.LoadInt32(&root.running) == 0 {
+					time.Sleep(time.Millisecond)
+				}
+			}
+			var buf bytes.Buffer
+			if err := root.Shutdown(&buf); err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile("../testdata/test.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("Root.Shutdown() wrote %q, want %q", buf.String(), want)
+			}
+			if !root.Doc.checkClose() {
+				t.Error("the reading of the document is not stopped")
+			}
+			if tt.run {
+				if err := <-runErr; err != nil {
+					t.Errorf("Root.Run() error = %v", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package oviewer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+// pasteEvent handles the start and the end of a bracketed paste.
+func (root *Root) pasteEvent(ev *tcell.EventPaste) {
+	input := root.input
+	if ev.Start() {
+		input.pasting = true
+		input.pasted = input.pasted[:0]
+		return
+	}
+	input.pasting = false
+	str := string(input.pasted)
+	input.pasted = input.pasted[:0]
+	if input.mode == Normal || str == "" {
+		return
+	}
+	root.pasteInput(str)
+}
+
+// pasteKey collects the keys of the pasted text.
+// The keys are not handled as the key bindings while pasting.
+// It returns false if it is not pasting.
+func (root *Root) pasteKey(ev *tcell.EventKey) bool {
+	input := root.input
+	if !input.pasting {
+		return false
+	}
+	switch ev.Key() {
+	case tcell.KeyRune:
+		input.pasted = append(input.pasted, ev.Rune())
+	case tcell.KeyTab:
+		input.pasted = append(input.pasted, '\t')
+	}
+	// The line breaks are ignored because the input is a single line.
+	return true
+}
+
+// pasteInput inserts the pasted text into the input at once.
+// With PasteLiteral, the text pasted into the search is escaped
+// so that it is searched literally.
+func (root *Root) pasteInput(str string) {
+	input := root.input
+	escaped := false
+	if root.PasteLiteral && root.RegexpSearch && isSearchMode(input.mode) {
+		if quoted := regexp.QuoteMeta(str); quoted != str {
+			str = quoted
+			escaped = true
+		}
+	}
+	input.insert(str)
+	input.hint = ""
+	root.checkRegexp()
+	if escaped {
+		input.hint = "pasted literally"
+	}
+}
+
+// isSearchMode returns true if the input mode is the search or the filter.
+func isSearchMode(mode InputMode) bool {
+	switch mode {
+	case Search, Backsearch, Filter:
+		return true
+	}
+	return false
+}
+
+// insert inserts the string at the cursor position.
+func (input *Input) insert(str string) {
+	pos := stringWidth(input.value, input.cursorX+1)
+	runes := []rune(input.value)
+	var b strings.Builder
+	b.WriteString(string(runes[:pos]))
+	b.WriteString(str)
+	b.WriteString(string(runes[pos:]))
+	input.value = b.String()
+	input.cursorX += runewidth.StringWidth(str)
+}
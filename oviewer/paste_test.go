@@ -0,0 +1,98 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_pasteEvent(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         InputMode
+		pasteLiteral bool
+		value        string
+		paste        string
+		want         string
+		wantHint     string
+	}{
+		{
+			name:         "search literal",
+			mode:         Search,
+			pasteLiteral: true,
+			paste:        "error [main]",
+			want:         `error \[main\]`,
+			wantHint:     "pasted literally",
+		},
+		{
+			name:         "search regexp",
+			mode:         Search,
+			pasteLiteral: false,
+			paste:        "a.*b",
+			want:         "a.*b",
+		},
+		{
+			name:         "no metacharacters",
+			mode:         Filter,
+			pasteLiteral: true,
+			paste:        "abc",
+			want:         "abc",
+		},
+		{
+			name:         "goto",
+			mode:         Goline,
+			pasteLiteral: true,
+			paste:        "1.5",
+			want:         "1.5",
+		},
+		{
+			name:         "line breaks",
+			mode:         Search,
+			pasteLiteral: true,
+			paste:        "a\nb",
+			want:         "ab",
+		},
+		{
+			name:         "insert",
+			mode:         Search,
+			pasteLiteral: true,
+			value:        "x",
+			paste:        "ab",
+			want:         "xab",
+		},
+		{
+			name:         "normal",
+			mode:         Normal,
+			pasteLiteral: true,
+			paste:        "q",
+			want:         "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{input: &Input{mode: tt.mode, value: tt.value, cursorX: len(tt.value)}}
+			root.RegexpSearch = true
+			root.PasteLiteral = tt.pasteLiteral
+			root.pasteEvent(tcell.NewEventPaste(true))
+			for _, r := range tt.paste {
+				ev := tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+				if r == '\n' {
+					ev = tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone)
+				}
+				if !root.pasteKey(ev) {
+					t.Fatal("Root.pasteKey() = false while pasting")
+				}
+			}
+			root.pasteEvent(tcell.NewEventPaste(false))
+			if root.input.value != tt.want {
+				t.Errorf("Root.pasteEvent() value = %q, want %q", root.input.value, tt.want)
+			}
+			if root.input.hint != tt.wantHint {
+				t.Errorf("Root.pasteEvent() hint = %q, want %q", root.input.hint, tt.wantHint)
+			}
+			if root.pasteKey(tcell.NewEventKey(tcell.KeyRune, 'a', tcell.ModNone)) {
+				t.Error("Root.pasteKey() = true after pasting")
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package oviewer
+
+import (
+	"fmt"
+)
+
+// pinnedHighlight is the search highlight that is kept after a new search.
+type pinnedHighlight struct {
+	str string
+	reg Searcher
+}
+
+// pinHighlight pins the current search highlight with StylePinnedHighlight.
+// Pinning the same pattern again unpins it.
+func (root *Root) pinHighlight() {
+	if root.input.reg == nil {
+		root.setMessage("no search to pin")
+		return
+	}
+	str := root.input.reg.String()
+	for i, p := range root.pinned {
+		if p.str == str {
+			root.pinned = append(root.pinned[:i], root.pinned[i+1:]...)
+			root.setMessage(fmt.Sprintf("unpinned %s", root.input.value))
+			return
+		}
+	}
+	root.pinned = append(root.pinned, pinnedHighlight{str: str, reg: root.input.reg})
+	// Clear the current highlight so that the pinned style is visible.
+	root.input.reg = nil
+	root.setMessage(fmt.Sprintf("pinned %s", root.input.value))
+}
+
+// clearPinned removes all the pinned highlights.
+func (root *Root) clearPinned() {
+	root.pinned = nil
+	root.setMessage("cleared pinned highlights")
+}
+
+// pinnedStyle applies the styles of the pinned highlights to the line.
+// The styles of StylePinnedHighlight are used in the order of pinning.
+func (root *Root) pinnedStyle(lc lineContents, lineStr string, byteMap map[int]int) {
+	if len(root.StylePinnedHighlight) == 0 {
+		return
+	}
+	for i, p := range root.pinned {
+		style := root.StylePinnedHighlight[i%len(root.StylePinnedHighlight)]
+		for _, r := range searchPosition(lineStr, p.reg) {
+			RangeStyle(lc, byteMap[r[0]], byteMap[r[1]], style)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package oviewer
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_pinnedStyle(t *testing.T) {
+	root := &Root{
+		Config: Config{
+			StylePinnedHighlight: []ovStyle{
+				{Foreground: "yellow"},
+				{Foreground: "aqua"},
+			},
+		},
+		pinned: []pinnedHighlight{
+			{str: "foo", reg: regexp.MustCompile("foo")},
+			{str: "baz", reg: regexp.MustCompile("baz")},
+		},
+	}
+	str := "foo bar baz"
+	lc := strToContents(str, 8)
+	lineStr, byteMap := contentsToStr(lc)
+	root.pinnedStyle(lc, lineStr, byteMap)
+
+	tests := []struct {
+		x    int
+		want tcell.Color
+	}{
+		{x: 0, want: tcell.ColorYellow},
+		{x: 2, want: tcell.ColorYellow},
+		{x: 4, want: tcell.ColorDefault},
+		{x: 8, want: tcell.ColorAqua},
+		{x: 10, want: tcell.ColorAqua},
+	}
+	for _, tt := range tests {
+		fg, _, _ := lc[tt.x].style.Decompose()
+		if fg != tt.want {
+			t.Errorf("pinnedStyle() x=%d foreground = %v, want %v", tt.x, fg, tt.want)
+		}
+	}
+}
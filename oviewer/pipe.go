@@ -0,0 +1,161 @@
+package oviewer
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// pipeInput represents the pipe input mode.
+type pipeInput struct {
+	value string
+	clist *candidate
+	// scope is the name of the range of the lines to pipe.
+	scope string
+	// start and end are the range of the lines [start, end) to pipe.
+	start int
+	end   int
+	pathCompletion
+	tcell.EventTime
+}
+
+// newPipeInput returns pipeInput.
+func newPipeInput(clist *candidate, scope string, start int, end int) *pipeInput {
+	return &pipeInput{clist: clist, scope: scope, start: start, end: end}
+}
+
+// Prompt returns the prompt string in the input field.
+func (p *pipeInput) Prompt() string {
+	return fmt.Sprintf("Pipe(%s)|", p.scope)
+}
+
+// Confirm returns the event when the input is confirmed.
+func (p *pipeInput) Confirm(str string) tcell.Event {
+	p.value = str
+	p.clist.list = toLast(p.clist.list, str)
+	p.clist.p = 0
+	p.SetEventNow()
+	return p
+}
+
+// Up returns strings when the up key is pressed during input.
+func (p *pipeInput) Up(str string) string {
+	return p.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (p *pipeInput) Down(str string) string {
+	return p.clist.match(str).down()
+}
+
+// Complete completes the last word of the command as a file path.
+func (p *pipeInput) Complete(str string) (string, string) {
+	i := strings.LastIndexByte(str, ' ') + 1
+	word, hint := p.pathCompletion.Complete(str[i:])
+	return str[:i] + word, hint
+}
+
+// setPipeMode sets the pipe input mode.
+func (root *Root) setPipeMode() {
+	scope, start, end := root.pipeRange()
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Pipe
+	input.EventInput = newPipeInput(input.PipeCandidate, scope, start, end)
+}
+
+// pipeRange returns the range of the lines to pipe.
+// It is the lines of the mouse selection, the number of lines of the count prefix
+// from the current line, the current section, or the whole document in that order.
+func (root *Root) pipeRange() (string, int, int) {
+	m := root.Doc
+	if root.mouseSelect && root.y1 >= 0 && root.y2 >= 0 && root.y1 < len(root.lnumber) && root.y2 < len(root.lnumber) {
+		y1, y2 := root.y1, root.y2
+		if y2 < y1 {
+			y1, y2 = y2, y1
+		}
+		start, end := root.lnumber[y1].line, root.lnumber[y2].line+1
+		if start >= 0 && end > start {
+			return "selection", start, min(end, m.BufEndNum())
+		}
+	}
+
+	current := m.topLN + m.Header
+	if root.count > 0 {
+		n := root.takeCount()
+		return fmt.Sprintf("%d lines", n), current, min(current+n, m.BufEndNum())
+	}
+	if start, end, ok := m.sectionRange(current); ok {
+		return "section", start, end
+	}
+	return "all", 0, m.BufEndNum()
+}
+
+// pipe adds a document of the output of the command
+// with the lines of the range as the standard input.
+func (root *Root) pipe(input *pipeInput) {
+	if root.restricted() {
+		return
+	}
+	if input.value == "" {
+		return
+	}
+	src := root.Doc
+	m, err := pipeDocument(src, input.start, input.end, input.value)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+
+	m.FileName = fmt.Sprintf("pipe:%s", input.value)
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	m.Header = 0
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("Pipe %d lines (%s) to %s", input.end-input.start, input.scope, input.value))
+}
+
+// pipeDocument executes the command in the shell with the lines [start, end) of src
+// as the standard input, and returns a new document that reads the output.
+func pipeDocument(src *Document, start int, end int, cmd string) (*Document, error) {
+	var b strings.Builder
+	for n := start; n < end; n++ {
+		b.WriteString(src.GetLine(n))
+		b.WriteByte('\n')
+	}
+
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	command := exec.Command(shell, "-c", cmd)
+	command.Stdin = strings.NewReader(b.String())
+	r, w := io.Pipe()
+	command.Stdout = w
+	command.Stderr = w
+	if err := command.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := command.Wait(); err != nil {
+			log.Printf("pipe: %s: %v", cmd, err)
+		}
+		w.Close()
+	}()
+
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ReadAll(r); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
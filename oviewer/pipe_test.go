@@ -0,0 +1,37 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_pipeDocument(t *testing.T) {
+	src := testLineDocument(t, 0, "c", "b", "a", "z")
+	tests := []struct {
+		name  string
+		start int
+		end   int
+		cmd   string
+		want  []string
+	}{
+		{name: "sort", start: 0, end: 3, cmd: "sort", want: []string{"a", "b", "c"}},
+		{name: "range", start: 1, end: 4, cmd: "cat", want: []string{"b", "a", "z"}},
+		{name: "stderr", start: 0, end: 0, cmd: "echo err >&2", want: []string{"err"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := pipeDocument(src, tt.start, tt.end, tt.cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			got := make([]string, 0)
+			for n := 0; n < m.BufEndNum(); n++ {
+				got = append(got, m.GetLine(n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pipeDocument() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// positionMax is the maximum number of files whose positions are saved.
+const positionMax = 1000
+
+// filePosition represents the last viewing position and modes of a file.
+// The modes are omitted in the files saved by the older versions.
+type filePosition struct {
+	Line        int       `json:"line"`
+	X           int       `json:"x"`
+	Header      *int      `json:"header,omitempty"`
+	WrapMode    *bool     `json:"wrap,omitempty"`
+	ColumnMode  *bool     `json:"column,omitempty"`
+	LineNumMode *bool     `json:"lineNumber,omitempty"`
+	Delimiter   *string   `json:"delimiter,omitempty"`
+	Time        time.Time `json:"time"`
+}
+
+// SetIsSet sets the function that returns true if the setting of the key
+// (e.g. "General.WrapMode") is given explicitly
+// by the command line options, the config file or the environment variables.
+// The modes set explicitly are not restored by RememberPosition.
+// If it is not set, only the positions are restored.
+func (root *Root) SetIsSet(f func(key string) bool) {
+	root.isSet = f
+}
+
+// restoreMode returns true if the mode of the key is restored from the last position.
+func (root *Root) restoreMode(key string) bool {
+	return root.isSet != nil && !root.isSet(key)
+}
+
+// positionKey returns the absolute path of the file of the document,
+// or an empty string if the document is not a regular file.
+func positionKey(m *Document) string {
+	if m.FileName == "" {
+		return ""
+	}
+	fi, err := os.Stat(m.FileName)
+	if err != nil || !fi.Mode().IsRegular() {
+		return ""
+	}
+	path, err := filepath.Abs(m.FileName)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// readPositions reads the positions from the file.
+func readPositions(fileName string) (map[string]filePosition, error) {
+	positions := make(map[string]filePosition)
+	buf, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return positions, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(buf, &positions); err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// restorePositions restores the last positions and modes of the documents from PositionFile.
+// It is called after the options are applied,
+// and does not change the modes given explicitly (see SetIsSet).
+func (root *Root) restorePositions() error {
+	if !root.RememberPosition || root.PositionFile == "" {
+		return nil
+	}
+	positions, err := readPositions(root.PositionFile)
+	if err != nil {
+		return err
+	}
+
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for _, m := range root.DocList {
+		pos, ok := positions[positionKey(m)]
+		if !ok {
+			continue
+		}
+		m.topLN = pos.Line
+		m.x = pos.X
+		root.restoreModes(m, pos)
+	}
+	return nil
+}
+
+// restoreModes restores the modes of the document that are not given explicitly.
+func (root *Root) restoreModes(m *Document, pos filePosition) {
+	if pos.Header != nil && root.restoreMode("General.Header") {
+		m.Header = *pos.Header
+	}
+	if pos.WrapMode != nil && root.restoreMode("General.WrapMode") {
+		m.WrapMode = *pos.WrapMode
+	}
+	if pos.ColumnMode != nil && root.restoreMode("General.ColumnMode") {
+		m.ColumnMode = *pos.ColumnMode
+	}
+	if pos.LineNumMode != nil && root.restoreMode("General.LineNumMode") {
+		m.LineNumMode = *pos.LineNumMode
+	}
+	if pos.Delimiter != nil && *pos.Delimiter != "" && root.restoreMode("General.ColumnDelimiter") {
+		m.ColumnDelimiter = *pos.Delimiter
+	}
+}
+
+// savePositions writes the positions and modes of the documents to PositionFile.
+func (root *Root) savePositions() error {
+	if !root.RememberPosition || root.PositionFile == "" {
+		return nil
+	}
+	positions, err := readPositions(root.PositionFile)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	root.mu.RLock()
+	for _, m := range root.DocList {
+		key := positionKey(m)
+		if key == "" {
+			continue
+		}
+		header, wrap, column, lineNum, delimiter := m.Header, m.WrapMode, m.ColumnMode, m.LineNumMode, m.ColumnDelimiter
+		positions[key] = filePosition{
+			Line:        m.topLN,
+			X:           m.x,
+			Header:      &header,
+			WrapMode:    &wrap,
+			ColumnMode:  &column,
+			LineNumMode: &lineNum,
+			Delimiter:   &delimiter,
+			Time:        now,
+		}
+	}
+	root.mu.RUnlock()
+
+	buf, err := json.MarshalIndent(limitPositions(positions, positionMax), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(root.PositionFile), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(root.PositionFile, buf, 0o600)
+}
+
+// limitPositions keeps only the limit most recent positions.
+func limitPositions(positions map[string]filePosition, limit int) map[string]filePosition {
+	if len(positions) <= limit {
+		return positions
+	}
+	keys := make([]string, 0, len(positions))
+	for key := range positions {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return positions[keys[i]].Time.After(positions[keys[j]].Time)
+	})
+	result := make(map[string]filePosition, limit)
+	for _, key := range keys[:limit] {
+		result[key] = positions[key]
+	}
+	return result
+}
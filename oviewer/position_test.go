@@ -0,0 +1,115 @@
+package oviewer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRoot_savePositions(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(fileName, []byte("a\nb\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	newRoot := func() *Root {
+		m, err := NewDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.FileName = fileName
+		root := &Root{Doc: m, DocList: []*Document{m}}
+		root.RememberPosition = true
+		root.PositionFile = filepath.Join(dir, "ov", "position.json")
+		return root
+	}
+
+	root := newRoot()
+	root.Doc.topLN = 2
+	root.Doc.x = 5
+	root.Doc.WrapMode = true
+	root.Doc.ColumnDelimiter = ","
+	if err := root.savePositions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The modes given explicitly are kept.
+	root = newRoot()
+	root.Doc.WrapMode = false
+	root.Doc.ColumnDelimiter = "|"
+	root.SetIsSet(func(key string) bool {
+		return key == "General.ColumnDelimiter"
+	})
+	if err := root.restorePositions(); err != nil {
+		t.Fatal(err)
+	}
+	m := root.Doc
+	if m.topLN != 2 || m.x != 5 || !m.WrapMode || m.ColumnDelimiter != "|" {
+		t.Errorf("restorePositions() = %d, %d, %v, %q, want 2, 5, true, \"|\"", m.topLN, m.x, m.WrapMode, m.ColumnDelimiter)
+	}
+
+	// Only the position is restored without SetIsSet.
+	root = newRoot()
+	if err := root.restorePositions(); err != nil {
+		t.Fatal(err)
+	}
+	if m := root.Doc; m.topLN != 2 || m.WrapMode {
+		t.Errorf("restorePositions() = %d, %v, want 2, false", m.topLN, m.WrapMode)
+	}
+}
+
+func TestRoot_restorePositionsOld(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(fileName, []byte("a\nb\nc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	abs, err := filepath.Abs(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	positionFile := filepath.Join(dir, "position.json")
+	// The position saved without the modes.
+	buf, err := json.Marshal(map[string]map[string]int{abs: {"line": 1, "x": 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(positionFile, buf, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.FileName = fileName
+	m.WrapMode = true
+	root := &Root{Doc: m, DocList: []*Document{m}}
+	root.RememberPosition = true
+	root.PositionFile = positionFile
+	root.SetIsSet(func(string) bool { return false })
+	if err := root.restorePositions(); err != nil {
+		t.Fatal(err)
+	}
+	if m.topLN != 1 || !m.WrapMode {
+		t.Errorf("restorePositions() = %d, %v, want 1, true", m.topLN, m.WrapMode)
+	}
+}
+
+func Test_limitPositions(t *testing.T) {
+	now := time.Now()
+	positions := map[string]filePosition{
+		"old":    {Time: now.Add(-2 * time.Hour)},
+		"new":    {Time: now},
+		"middle": {Time: now.Add(-time.Hour)},
+	}
+	got := limitPositions(positions, 2)
+	if len(got) != 2 {
+		t.Fatalf("limitPositions() = %v, want 2 positions", got)
+	}
+	if _, ok := got["old"]; ok {
+		t.Errorf("limitPositions() kept the oldest position")
+	}
+}
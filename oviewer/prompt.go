@@ -0,0 +1,47 @@
+package oviewer
+
+import (
+	"strconv"
+	"strings"
+)
+
+// inputModeNames is the names of the input modes in Prompts.
+var inputModeNames = map[InputMode]string{
+	ViewMode:   "viewmode",
+	Search:     "search",
+	Backsearch: "backsearch",
+	Goline:     "goto",
+	Header:     "header",
+	Delimiter:  "delimiter",
+	TabWidth:   "tabwidth",
+	SaveBuffer: "savebuffer",
+	Setting:    "setting",
+	Filter:     "filter",
+	JSONFields: "jsonfields",
+	Pipe:       "pipe",
+	Replace:    "replace",
+	Rename:     "rename",
+	DocSwitch:  "docswitch",
+	Shell:      "shell",
+	OpenFile:   "openfile",
+}
+
+// prompt returns the prompt of the input mode.
+// The prompt of Prompts replaces the default prompt,
+// and the placeholders in it are replaced with the current values.
+func (root *Root) prompt() string {
+	input := root.input
+	prompt := input.EventInput.Prompt()
+	custom, ok := root.Prompts[inputModeNames[input.mode]]
+	if !ok {
+		return prompt
+	}
+	m := root.Doc
+	return strings.NewReplacer(
+		"{default}", prompt,
+		"{file}", m.Title(),
+		"{header}", strconv.Itoa(m.Header),
+		"{tabwidth}", strconv.Itoa(m.TabWidth),
+		"{delimiter}", m.ColumnDelimiter,
+	).Replace(custom)
+}
@@ -0,0 +1,54 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func TestRoot_prompt(t *testing.T) {
+	m := testLineDocument(t, 1, "a")
+	m.TabWidth = 4
+	tests := []struct {
+		name    string
+		prompts map[string]string
+		mode    InputMode
+		input   EventInput
+		want    string
+	}{
+		{
+			name:  "default",
+			mode:  TabWidth,
+			input: &tabWidthInput{},
+			want:  "TAB width:",
+		},
+		{
+			name:    "custom",
+			prompts: map[string]string{"tabwidth": "Tab width ({tabwidth}):"},
+			mode:    TabWidth,
+			input:   &tabWidthInput{},
+			want:    "Tab width (4):",
+		},
+		{
+			name:    "default placeholder",
+			prompts: map[string]string{"search": "Search {default}"},
+			mode:    Search,
+			input:   &searchInput{},
+			want:    "Search /",
+		},
+		{
+			name:    "other mode",
+			prompts: map[string]string{"search": "Search:"},
+			mode:    Header,
+			input:   &headerInput{},
+			want:    "Header length:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{Doc: m, input: &Input{mode: tt.mode, EventInput: tt.input}}
+			root.Prompts = tt.prompts
+			if got := root.prompt(); got != tt.want {
+				t.Errorf("Root.prompt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
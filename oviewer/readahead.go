@@ -0,0 +1,57 @@
+package oviewer
+
+// maxReadAheadLines is the maximum number of the lines read ahead on each side of the screen.
+// It is kept less than the cost of the cache so that the read-ahead does not evict the screen.
+const maxReadAheadLines = 300
+
+// readAheadRange returns the range of the lines [start, end) read ahead
+// around the screen of the lines [top, bottom).
+func readAheadRange(top int, bottom int, rows int, screens int, endNum int) (int, int) {
+	n := min(rows*screens, maxReadAheadLines)
+	return max(top-n, 0), min(bottom+n, endNum)
+}
+
+// readAhead prepares the contents of the lines before and after the screen in the background,
+// so that they are in the cache when the screen is moved to them.
+func (root *Root) readAhead() {
+	if root.ReadAhead <= 0 {
+		return
+	}
+	m := root.Doc
+	if m == root.readAheadDoc && m.topLN == root.readAheadLN {
+		return
+	}
+	root.readAheadDoc = m
+	root.readAheadLN = m.topLN
+
+	top := m.topLN + m.Header
+	bottom := root.bottomLN
+	start, end := readAheadRange(top, bottom, root.vHight, root.ReadAhead, m.BufEndNum())
+	opt := m.contentsOption(m.TabWidth)
+	go func() {
+		// The lines just before and after the screen first.
+		for i := 1; top-i >= start || bottom+i-1 < end; i++ {
+			if top-i >= start {
+				m.readAheadContents(top-i, opt)
+			}
+			if bottom+i-1 < end {
+				m.readAheadContents(bottom+i-1, opt)
+			}
+		}
+	}()
+}
+
+// readAheadContents caches the contents of the line if it is not cached,
+// unless the cache has been cleared since opt was taken.
+func (m *Document) readAheadContents(lN int, opt contentsOption) {
+	if _, found := m.cache.Get(lN); found {
+		return
+	}
+	lc := opt.parse(m.GetLine(lN))
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	if m.cacheGen != opt.cacheGen {
+		return
+	}
+	m.cache.Set(lN, lc, 1)
+}
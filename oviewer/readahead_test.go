@@ -0,0 +1,47 @@
+package oviewer
+
+import "testing"
+
+func Test_readAheadRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		top       int
+		bottom    int
+		rows      int
+		screens   int
+		endNum    int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "middle", top: 100, bottom: 124, rows: 25, screens: 1, endNum: 1000, wantStart: 75, wantEnd: 149},
+		{name: "top", top: 10, bottom: 34, rows: 25, screens: 2, endNum: 1000, wantStart: 0, wantEnd: 84},
+		{name: "bottom", top: 980, bottom: 1000, rows: 25, screens: 1, endNum: 1000, wantStart: 955, wantEnd: 1000},
+		{name: "limit", top: 5000, bottom: 5050, rows: 50, screens: 100, endNum: 10000, wantStart: 5000 - maxReadAheadLines, wantEnd: 5050 + maxReadAheadLines},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := readAheadRange(tt.top, tt.bottom, tt.rows, tt.screens, tt.endNum)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("readAheadRange() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestDocument_readAheadContents(t *testing.T) {
+	m := testLineDocument(t, 0, "a\tb", "c")
+	opt := m.contentsOption(4)
+	m.readAheadContents(0, opt)
+	m.cache.Wait()
+	if _, found := m.cache.Get(0); !found {
+		t.Error("the contents read ahead are not cached")
+	}
+
+	// The contents with the options before ClearCache are not cached.
+	m.ClearCache()
+	m.readAheadContents(1, opt)
+	m.cache.Wait()
+	if _, found := m.cache.Get(1); found {
+		t.Error("the contents of the old options are cached")
+	}
+}
@@ -4,13 +4,15 @@ import (
 	"bufio"
 	"bytes"
 	"compress/bzip2"
-	"compress/gzip"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"sync/atomic"
+	"time"
+	"unicode/utf8"
 
 	"github.com/klauspost/compress/zstd"
 	"github.com/pierrec/lz4"
@@ -90,15 +92,22 @@ func compressedFormatReader(cFormat Compressed, reader io.Reader) io.Reader {
 	var err error
 	switch cFormat {
 	case GZIP:
-		r, err = gzip.NewReader(reader)
+		r = newGzipReader(reader)
 	case BZIP2:
-		r = bzip2.NewReader(reader)
+		r = newAsyncReader(bzip2.NewReader(reader))
 	case ZSTD:
-		r, err = zstd.NewReader(reader)
+		// The zstd decoder decodes the blocks in other goroutines by itself.
+		var d *zstd.Decoder
+		if d, err = zstd.NewReader(reader); err == nil {
+			r = zstdReader{d}
+		}
 	case LZ4:
-		r = lz4.NewReader(reader)
+		r = newAsyncReader(lz4.NewReader(reader))
 	case XZ:
-		r, err = xz.NewReader(reader)
+		var x *xz.Reader
+		if x, err = xz.NewReader(reader); err == nil {
+			r = newAsyncReader(x)
+		}
 	}
 	if err != nil || r == nil {
 		r = reader
@@ -116,11 +125,15 @@ func (m *Document) ReadFile(fileName string) error {
 		m.FileName = "(STDIN)"
 	} else {
 		m.FileName = fileName
-		r, err := os.Open(fileName)
+		r, err := openShared(fileName)
 		if err != nil {
 			return err
 		}
 		m.file = r
+		if fi, err := r.Stat(); err == nil && fi.Mode().IsRegular() {
+			m.fileInfo = fi
+			atomic.StoreInt32(&m.fileRead, 1)
+		}
 	}
 
 	cFormat, reader := uncompressedReader(m.file)
@@ -148,7 +161,7 @@ func (m *Document) openFollowMode() {
 	<-m.changCh
 
 	log.Printf("reopen %s", m.FileName)
-	r, err := os.Open(m.FileName)
+	r, err := openShared(m.FileName)
 	if err != nil {
 		log.Printf("reopen %s", err)
 		return
@@ -164,6 +177,12 @@ func (m *Document) openFollowMode() {
 	}
 
 	rr := compressedFormatReader(m.CFormat, r)
+	switch m.encoding {
+	case encodingUTF16LE:
+		rr = newUTF16Reader(rr, binary.LittleEndian)
+	case encodingUTF16BE:
+		rr = newUTF16Reader(rr, binary.BigEndian)
+	}
 	if err := m.ContinueReadAll(rr); err != nil {
 		log.Printf("%s cannot be reopened %v", m.FileName, err)
 	}
@@ -187,14 +206,27 @@ func (m *Document) close() error {
 // It returns if beforeSize is accumulated in buffer
 // before the end of read.
 func (m *Document) ReadAll(r io.Reader) error {
-	reader := bufio.NewReader(r)
+	reader := m.newReader(r)
 	go func() {
+		defer stopReader(r)
 		if m.checkClose() {
 			return
 		}
+		start := time.Now()
+
+		encoding, reader := detectEncoding(reader)
+		newline := detectNewline(reader)
+		m.mu.Lock()
+		m.encoding = encoding
+		m.newline = newline
+		m.mu.Unlock()
 
 		if err := m.readAll(reader); err != nil {
 			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrClosedPipe) || errors.Is(err, os.ErrClosed) {
+				m.appendDropMarker()
+				if observer := m.getObserver(); observer != nil {
+					observer.ObserveDuration(MetricRead, time.Since(start))
+				}
 				close(m.eofCh)
 				atomic.StoreInt32(&m.eof, 1)
 				return
@@ -209,7 +241,9 @@ func (m *Document) ReadAll(r io.Reader) error {
 
 // ContinueReadAll continues to read even if it reaches EOF.
 func (m *Document) ContinueReadAll(r io.Reader) error {
-	reader := bufio.NewReader(r)
+	// The lines appended to the followed file are a stream.
+	atomic.StoreInt32(&m.fileRead, 0)
+	reader := m.newReader(r)
 	for {
 		if m.checkClose() {
 			return nil
@@ -225,6 +259,26 @@ func (m *Document) ContinueReadAll(r io.Reader) error {
 	}
 }
 
+// setReadOptions sets the options of reading the document from the config.
+func (root *Root) setReadOptions(m *Document) {
+	m.readBufferSize = root.ReadBufferSize
+	m.setChunkSize(root.ChunkSize)
+	m.setObserver(root.observer)
+	strategy, err := backpressureStrategy(root.Backpressure)
+	if err != nil {
+		log.Println(err)
+	}
+	m.setBackpressure(strategy, root.BackpressureLines)
+}
+
+// newReader returns a buffered reader of readBufferSize.
+func (m *Document) newReader(r io.Reader) *bufio.Reader {
+	if m.readBufferSize > 0 {
+		return bufio.NewReaderSize(r, m.readBufferSize)
+	}
+	return bufio.NewReader(r)
+}
+
 func (m *Document) readAll(reader *bufio.Reader) error {
 	var line bytes.Buffer
 
@@ -238,19 +292,75 @@ func (m *Document) readAll(reader *bufio.Reader) error {
 			return err
 		}
 		line.Write(buf)
+		// All the segments in the line read so far are split.
+		for line.Len() > m.segmentSize {
+			m.appendSegment(&line)
+		}
 		if isPrefix {
 			continue
 		}
 
-		m.append(line.String())
+		if m.admit() {
+			m.append(m.chunkString(line.Bytes()))
+		}
 		line.Reset()
 	}
 }
 
+// appendSegment appends the first segmentSize bytes of the line being read
+// as a segment that continues to the next line, so that an extremely long line
+// is drawn and searched in segments. The segments are counted as one line
+// in the line numbers (see streamLN).
+func (m *Document) appendSegment(line *bytes.Buffer) {
+	b := line.Bytes()
+	n := m.segmentSize
+	// Split at the beginning of a character.
+	for n > 0 && n < len(b) && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	if n == 0 {
+		n = m.segmentSize
+	}
+
+	m.mu.Lock()
+	if m.continued == nil {
+		log.Printf("split long line %d into segments of %d bytes", m.endNum+1, m.segmentSize)
+	}
+	m.continued = append(m.continued, m.endNum)
+	m.mu.Unlock()
+	m.append(string(b[:n]))
+
+	rest := make([]byte, len(b)-n)
+	copy(rest, b[n:])
+	line.Reset()
+	line.Write(rest)
+}
+
+// takeAppended returns the number of the lines appended since the last call.
+func (m *Document) takeAppended() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := m.appended
+	m.appended = 0
+	return n
+}
+
 func (m *Document) append(line string) {
 	m.mu.Lock()
+	if atomic.LoadInt32(&m.recordArrival) == 1 {
+		if m.arrival == nil {
+			m.arrival = make(map[int]time.Time)
+		}
+		m.arrival[m.endNum] = time.Now()
+	}
 	m.lines = append(m.lines, line)
 	m.endNum++
+	m.appended++
+	m.size += int64(len(line))
+	observer := m.observer
 	m.mu.Unlock()
 	atomic.StoreInt32(&m.changed, 1)
+	if observer != nil {
+		observer.AddCount(MetricAppendedLines, 1)
+	}
 }
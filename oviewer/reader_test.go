@@ -1,8 +1,13 @@
 package oviewer
 
 import (
+	"bufio"
 	"bytes"
+	"errors"
 	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -72,3 +77,118 @@ func TestDocument_ReadAll(t *testing.T) {
 		})
 	}
 }
+
+func TestDocument_readAllSegment(t *testing.T) {
+	tests := []struct {
+		name        string
+		segmentSize int
+		bufSize     int
+		input       string
+		want        []string
+	}{
+		{
+			name:        "testShort",
+			segmentSize: 32,
+			bufSize:     16,
+			input:       "foo\nbar\n",
+			want:        []string{"foo", "bar"},
+		},
+		{
+			name:        "testLong",
+			segmentSize: 20,
+			bufSize:     16,
+			input:       strings.Repeat("a", 50) + "\nb\n",
+			want:        []string{strings.Repeat("a", 20), strings.Repeat("a", 20), strings.Repeat("a", 10), "b"},
+		},
+		{
+			name:        "testMultiByte",
+			segmentSize: 20,
+			bufSize:     16,
+			input:       strings.Repeat("あ", 20) + "\n",
+			want:        []string{strings.Repeat("あ", 6), strings.Repeat("あ", 6), strings.Repeat("あ", 6), strings.Repeat("あ", 2)},
+		},
+		{
+			// The line reaches exactly segmentSize when the buffer size divides it.
+			name:        "testBufferDividesSegment",
+			segmentSize: 32,
+			bufSize:     16,
+			input:       strings.Repeat("a", 80) + "\n",
+			want:        []string{strings.Repeat("a", 32), strings.Repeat("a", 32), strings.Repeat("a", 16)},
+		},
+		{
+			// The read buffer contains several segments.
+			name:        "testBufferLargerThanSegment",
+			segmentSize: 16,
+			bufSize:     64,
+			input:       strings.Repeat("a", 50) + "\n",
+			want:        []string{strings.Repeat("a", 16), strings.Repeat("a", 16), strings.Repeat("a", 16), strings.Repeat("a", 2)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.segmentSize = tt.segmentSize
+			if err := m.readAll(bufio.NewReaderSize(strings.NewReader(tt.input), tt.bufSize)); !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			got := make([]string, 0)
+			for n := 0; n < m.BufEndNum(); n++ {
+				got = append(got, m.GetLine(n))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Document.readAll() = %v, want %v", got, tt.want)
+			}
+
+			var b bytes.Buffer
+			if err := m.Export(&b); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.input {
+				t.Errorf("Document.Export() = %v, want %v", b.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestDocument_appendArrival(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.append("before")
+	atomic.StoreInt32(&m.recordArrival, 1)
+	m.append("after")
+
+	if _, ok := m.arrivalTime(0); ok {
+		t.Errorf("Document.arrivalTime(0) recorded before follow mode")
+	}
+	if _, ok := m.arrivalTime(1); !ok {
+		t.Errorf("Document.arrivalTime(1) not recorded")
+	}
+}
+
+func TestDocument_newReader(t *testing.T) {
+	tests := []struct {
+		name string
+		size int
+		want int
+	}{
+		{name: "default", size: 0, want: 4096},
+		{name: "large", size: 65536, want: 65536},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.readBufferSize = tt.size
+			if got := m.newReader(strings.NewReader("")).Size(); got != tt.want {
+				t.Errorf("Document.newReader().Size() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package oviewer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// record is to switch between the record screen and normal screen.
+// The record screen displays the current row transposed as "name: value" lines.
+func (root *Root) record() {
+	if root.screenMode == Record {
+		root.toNormal()
+		return
+	}
+	src := root.targetDoc()
+	lN := src.currentLN()
+	if lN < src.Header || lN >= src.BufEndNum() {
+		root.setMessage("no record")
+		return
+	}
+	m, err := NewDocument()
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	m.FileName = fmt.Sprintf("record:%d:%s", lN+1, src.FileName)
+	for _, line := range recordLines(src, lN) {
+		m.append(line)
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	root.setDocument(m)
+	root.screenMode = Record
+}
+
+// currentLN returns the line moved to by a search or goto if it is displayed,
+// otherwise the first line of the body.
+func (m *Document) currentLN() int {
+	if m.jumpLN >= 0 && m.topLN == m.jumpTopLN {
+		return m.jumpLN
+	}
+	return m.topLN + m.Header
+}
+
+// recordLines returns the columns of the line lN as "name: value" lines.
+// The names are the columns of the header row (HeaderLine or the last line of Header),
+// or the column numbers if there is no header.
+func recordLines(m *Document, lN int) []string {
+	delimiter := m.ColumnDelimiter
+	if delimiter == "" {
+		delimiter = ","
+	}
+	values := splitRecord(plainLine(m.GetLine(lN)), delimiter)
+
+	var names []string
+	switch {
+	case m.HeaderLine > 0 && m.HeaderLine-1 != lN:
+		names = splitRecord(plainLine(m.GetLine(m.HeaderLine-1)), delimiter)
+	case m.Header > 0 && m.Header-1 != lN:
+		names = splitRecord(plainLine(m.GetLine(m.Header-1)), delimiter)
+	}
+
+	width := 0
+	for i := range values {
+		if i >= len(names) {
+			names = append(names, "")
+		}
+		if names[i] == "" {
+			names[i] = strconv.Itoa(i + 1)
+		}
+		width = max(width, runewidth.StringWidth(names[i]))
+	}
+
+	lines := make([]string, len(values))
+	for i, v := range values {
+		lines[i] = names[i] + strings.Repeat(" ", width-runewidth.StringWidth(names[i])) + ": " + v
+	}
+	return lines
+}
+
+// splitRecord splits the line by the delimiter and trims the spaces of the columns.
+func splitRecord(line string, delimiter string) []string {
+	var columns []string
+	if strings.TrimSpace(delimiter) == "" {
+		columns = strings.Fields(line)
+	} else {
+		columns = strings.Split(line, delimiter)
+	}
+	for i, c := range columns {
+		columns[i] = strings.TrimSpace(c)
+	}
+	return columns
+}
@@ -0,0 +1,68 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_recordLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []string
+		header     int
+		headerLine int
+		delimiter  string
+		lN         int
+		want       []string
+	}{
+		{
+			name:      "header",
+			lines:     []string{"id,name,comment", "1,foo,bar"},
+			header:    1,
+			delimiter: ",",
+			lN:        1,
+			want:      []string{"id     : 1", "name   : foo", "comment: bar"},
+		},
+		{
+			name:       "header line",
+			lines:      []string{"report", "id,name", "1,foo"},
+			headerLine: 2,
+			delimiter:  ",",
+			lN:         2,
+			want:       []string{"id  : 1", "name: foo"},
+		},
+		{
+			name:      "no header",
+			lines:     []string{"1|foo"},
+			delimiter: "|",
+			lN:        0,
+			want:      []string{"1: 1", "2: foo"},
+		},
+		{
+			name:      "more values",
+			lines:     []string{"id", "1, foo"},
+			header:    1,
+			delimiter: ",",
+			lN:        1,
+			want:      []string{"id: 1", "2 : foo"},
+		},
+		{
+			name:      "spaces",
+			lines:     []string{"id  name", "1   foo"},
+			header:    1,
+			delimiter: " ",
+			lN:        1,
+			want:      []string{"id  : 1", "name: foo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, tt.header, tt.lines...)
+			m.HeaderLine = tt.headerLine
+			m.ColumnDelimiter = tt.delimiter
+			if got := recordLines(m, tt.lN); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("recordLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+
+	"code.rocketnine.space/tslocum/cbind"
+	"github.com/gdamore/tcell/v2"
+)
+
+// SetLoadConfig sets the function to read the config file again.
+func (root *Root) SetLoadConfig(f func() (Config, error)) {
+	root.loadConfig = f
+}
+
+// eventReloadConfig represents a reload config event.
+type eventReloadConfig struct {
+	tcell.EventTime
+}
+
+// ReloadConfig fires a reload config event.
+func (root *Root) ReloadConfig() {
+	if !root.checkScreen() {
+		return
+	}
+	ev := &eventReloadConfig{}
+	ev.SetEventNow()
+	go func() {
+		root.Screen.PostEventWait(ev)
+	}()
+}
+
+// reloadConfig reads the config file again and applies it.
+// The config is applied only if its key bindings are valid.
+// The general settings changed in the config file are applied to the documents,
+// and the others keep the modes changed at runtime.
+func (root *Root) reloadConfig() {
+	if root.loadConfig == nil {
+		root.setMessage("cannot reload config")
+		return
+	}
+	config, err := root.loadConfig()
+	if err != nil {
+		root.setMessage(fmt.Sprintf("reload config: %s", err))
+		return
+	}
+	if err := root.checkKeyConfig(config); err != nil {
+		root.setMessage(fmt.Sprintf("reload config: %s", err))
+		return
+	}
+
+	root.mu.RLock()
+	before := make([]general, len(root.DocList))
+	for i, doc := range root.DocList {
+		before[i] = root.configGeneral(doc)
+	}
+	root.mu.RUnlock()
+
+	root.Config = config
+	root.applyBackground()
+	root.errorRegs = nil
+	root.keyConfig = cbind.NewConfiguration()
+	keyBind, err := root.setKeyConfig()
+	if err != nil {
+		log.Printf("reload config: %s", err)
+	}
+	if help, err := NewHelp(keyBind); err == nil {
+		root.helpDoc = help
+	}
+
+	root.mu.RLock()
+	for i, doc := range root.DocList {
+		setChangedGeneral(&doc.general, before[i], root.configGeneral(doc))
+		doc.resetSectionCache()
+		doc.ClearCache()
+	}
+	root.mu.RUnlock()
+
+	root.setModeCandidates()
+	root.setGlobalStyle()
+	if root.Config.DisableMouse {
+		root.Screen.DisableMouse()
+	} else {
+		root.Screen.EnableMouse()
+	}
+	root.setWrapHeaderLen()
+	root.ViewSync()
+	log.Printf("reload config")
+	root.setMessage("Reloaded config")
+}
+
+// checkKeyConfig returns the error of the key bindings of the config without setting them.
+func (root *Root) checkKeyConfig(config Config) error {
+	keyBind, err := GetPresetKeyBinds(config.KeybindPreset, config.Keybind)
+	if err != nil {
+		return err
+	}
+	actionHandlers := root.setHandler()
+	chords := newChordBinds()
+	for a, keys := range keyBind {
+		if actionHandlers[a] == nil {
+			return fmt.Errorf("%w for [%s] unknown action", ErrFailedKeyBind, a)
+		}
+		for _, k := range keys {
+			if isChord(k) {
+				if err := chords.set(k, func() {}); err != nil {
+					return fmt.Errorf("%w [%s] for %s: %s", ErrFailedKeyBind, k, a, err)
+				}
+				continue
+			}
+			if _, _, _, err := cbind.Decode(k); err != nil {
+				return fmt.Errorf("%w [%s] for %s: %s", ErrFailedKeyBind, k, a, err)
+			}
+		}
+	}
+	return nil
+}
+
+// setChangedGeneral sets the settings of dst that differ between old and new to the values of new,
+// and keeps the others.
+func setChangedGeneral(dst *general, old general, new general) {
+	d := reflect.ValueOf(dst).Elem()
+	o := reflect.ValueOf(old)
+	n := reflect.ValueOf(new)
+	for i := 0; i < d.NumField(); i++ {
+		if !reflect.DeepEqual(o.Field(i).Interface(), n.Field(i).Interface()) {
+			d.Field(i).Set(n.Field(i))
+		}
+	}
+}
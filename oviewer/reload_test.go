@@ -0,0 +1,74 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_reloadConfig(t *testing.T) {
+	m := testLineDocument(t, 0, "a", "b")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.prepareView()
+	m.general = root.Config.General
+	// The mode toggled at runtime.
+	m.WrapMode = !root.Config.General.WrapMode
+
+	config := NewConfig()
+	config.General.TabWidth = 4
+	root.SetLoadConfig(func() (Config, error) {
+		return config, nil
+	})
+	root.reloadConfig()
+	if root.Config.General.TabWidth != 4 || m.TabWidth != 4 {
+		t.Errorf("reloadConfig() TabWidth = %d, %d, want 4", root.Config.General.TabWidth, m.TabWidth)
+	}
+	if m.WrapMode == config.General.WrapMode {
+		t.Errorf("reloadConfig() reset the WrapMode changed at runtime")
+	}
+
+	// The config with the invalid key bindings is not applied.
+	bad := NewConfig()
+	bad.General.TabWidth = 2
+	bad.Keybind = map[string][]string{"no_such_action": {"x"}}
+	root.SetLoadConfig(func() (Config, error) {
+		return bad, nil
+	})
+	root.reloadConfig()
+	if root.Config.General.TabWidth != 4 || m.TabWidth != 4 {
+		t.Errorf("reloadConfig() applied the invalid config: TabWidth = %d, %d", root.Config.General.TabWidth, m.TabWidth)
+	}
+	if root.Config.Keybind["no_such_action"] != nil {
+		t.Errorf("reloadConfig() applied the invalid key bindings")
+	}
+	if !strings.HasPrefix(root.message, "reload config:") {
+		t.Errorf("reloadConfig() message = %q, want the error", root.message)
+	}
+}
+
+func TestRoot_reloadConfigSectionCache(t *testing.T) {
+	m := testLineDocument(t, 0, "# a", "1", "# b", "2")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.prepareView()
+	m.SectionDelimiter = "^#"
+	if got := m.sectionStart(3); got != 2 {
+		t.Fatalf("sectionStart() = %d, want 2", got)
+	}
+	// The result of the lines before the reload is not reused.
+	m.sectionCacheStart = 99
+	m.topLN = 3
+	root.SetLoadConfig(func() (Config, error) {
+		return NewConfig(), nil
+	})
+	root.reloadConfig()
+	if got := m.sectionStart(3); got != 2 {
+		t.Errorf("sectionStart() after reloadConfig() = %d, want 2", got)
+	}
+}
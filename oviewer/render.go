@@ -0,0 +1,90 @@
+package oviewer
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// NewOviewerScreen returns the structure of oviewer that draws on the screen instead of the terminal.
+// Any implementation of tcell.Screen can be the backend,
+// such as tcell.NewSimulationScreen for tests, NewWriterScreen for batch output,
+// or a screen that sends the cells to another frontend.
+// The screen is initialized by NewOviewerScreen.
+func NewOviewerScreen(screen tcell.Screen, docs ...*Document) (*Root, error) {
+	root, err := newRoot(docs...)
+	if err != nil {
+		return nil, err
+	}
+	if err := root.setScreen(screen); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// writerScreen is the screen that writes the last screen as text to the writer when it is finished.
+type writerScreen struct {
+	tcell.SimulationScreen
+	w      io.Writer
+	width  int
+	height int
+}
+
+// NewWriterScreen returns the screen of the size that is not displayed,
+// and writes the last screen as text to w when it is finished (by Close or Run returns).
+// It is the backend of the batch output without a terminal.
+func NewWriterScreen(w io.Writer, width int, height int) tcell.Screen {
+	return &writerScreen{
+		SimulationScreen: tcell.NewSimulationScreen("UTF-8"),
+		w:                w,
+		width:            width,
+		height:           height,
+	}
+}
+
+// Init initializes the screen with the size.
+func (s *writerScreen) Init() error {
+	if err := s.SimulationScreen.Init(); err != nil {
+		return err
+	}
+	s.SetSize(s.width, s.height)
+	return nil
+}
+
+// Fini writes the screen to the writer and finishes the screen.
+func (s *writerScreen) Fini() {
+	if s.w != nil {
+		if err := writeScreen(s.w, s.SimulationScreen); err != nil {
+			s.w = nil
+		}
+	}
+	s.SimulationScreen.Fini()
+}
+
+// writeScreen writes the contents of the screen as text.
+// The trailing spaces of the rows are removed.
+func writeScreen(w io.Writer, screen tcell.Screen) error {
+	bw := bufio.NewWriter(w)
+	width, height := screen.Size()
+	var row strings.Builder
+	for y := 0; y < height; y++ {
+		row.Reset()
+		for x := 0; x < width; {
+			mainc, combc, _, cw := screen.GetContent(x, y)
+			if mainc == 0 {
+				mainc = ' '
+			}
+			row.WriteRune(mainc)
+			for _, r := range combc {
+				row.WriteRune(r)
+			}
+			x += max(cw, 1)
+		}
+		if _, err := bw.WriteString(strings.TrimRight(row.String(), " ") + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
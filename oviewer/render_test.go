@@ -0,0 +1,33 @@
+package oviewer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewOviewerScreen_writer(t *testing.T) {
+	var buf bytes.Buffer
+	m := testLineDocument(t, 0, "first line", "日本語の行", "third")
+	root, err := NewOviewerScreen(NewWriterScreen(&buf, 20, 4), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.prepareView()
+	root.draw()
+	if buf.Len() != 0 {
+		t.Fatalf("the screen is written before it is finished: %q", buf.String())
+	}
+	root.Close()
+
+	rows := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(rows) != 4 {
+		t.Fatalf("NewWriterScreen() wrote %d rows, want 4: %q", len(rows), buf.String())
+	}
+	want := []string{"first line", "日本語の行", "third"}
+	for i, w := range want {
+		if rows[i] != w {
+			t.Errorf("row %d = %q, want %q", i, rows[i], w)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package oviewer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// replaceInput represents the replace input mode.
+type replaceInput struct {
+	value string
+	clist *candidate
+	tcell.EventTime
+}
+
+// newReplaceInput returns replaceInput.
+func newReplaceInput(clist *candidate) *replaceInput {
+	return &replaceInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (r *replaceInput) Prompt() string {
+	return "Replace(s/pattern/replacement/):"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (r *replaceInput) Confirm(str string) tcell.Event {
+	r.value = str
+	r.clist.list = toLast(r.clist.list, str)
+	r.clist.p = 0
+	r.SetEventNow()
+	return r
+}
+
+// Up returns strings when the up key is pressed during input.
+func (r *replaceInput) Up(str string) string {
+	return r.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (r *replaceInput) Down(str string) string {
+	return r.clist.match(str).down()
+}
+
+// Hint returns the error of the substitution being typed.
+func (r *replaceInput) Hint(str string) string {
+	if str == "" {
+		return ""
+	}
+	if _, err := parseSubstitution(str); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// setReplaceMode sets the replace input mode.
+func (root *Root) setReplaceMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Replace
+	input.EventInput = newReplaceInput(input.ReplaceCandidate)
+}
+
+// substitution represents the s/pattern/replacement/flags of the replace mode.
+type substitution struct {
+	reg         *regexp.Regexp
+	replacement string
+	// global replaces all the matches of the line, otherwise the first match.
+	global bool
+	// style is applied to the replaced text.
+	style ovStyle
+}
+
+// parseSubstitution parses s/pattern/replacement/flags.
+// Any character can be the delimiter instead of '/'.
+// The flags are g (all the matches of the line) and i (case-insensitive).
+// In the replacement, \1-\9 and & are the submatches as in sed,
+// and $1 and ${name} are the submatches as in Go.
+func parseSubstitution(str string) (*substitution, error) {
+	if len(str) < 2 || str[0] != 's' {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubstitution, str)
+	}
+	delim := str[1]
+	if delim == '\\' || delim == ' ' || ('a' <= delim && delim <= 'z') || ('A' <= delim && delim <= 'Z') || ('0' <= delim && delim <= '9') {
+		return nil, fmt.Errorf("%w: delimiter %q", ErrInvalidSubstitution, delim)
+	}
+	parts := splitUnescaped(str[2:], delim)
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubstitution, str)
+	}
+
+	s := &substitution{}
+	pattern := parts[0]
+	if len(parts) == 3 {
+		for _, f := range parts[2] {
+			switch f {
+			case 'g':
+				s.global = true
+			case 'i':
+				pattern = "(?i)" + pattern
+			default:
+				return nil, fmt.Errorf("%w: flag %q", ErrInvalidSubstitution, f)
+			}
+		}
+	}
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.reg = reg
+	s.replacement = sedReplacement(parts[1])
+	return s, nil
+}
+
+// splitUnescaped splits str by the delimiter that is not escaped by a backslash.
+// The escaped delimiters are unescaped.
+func splitUnescaped(str string, delim byte) []string {
+	parts := make([]string, 0, 3)
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		switch {
+		case str[i] == '\\' && i+1 < len(str) && str[i+1] == delim:
+			b.WriteByte(delim)
+			i++
+		case str[i] == '\\' && i+1 < len(str):
+			b.WriteByte(str[i])
+			b.WriteByte(str[i+1])
+			i++
+		case str[i] == delim:
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(str[i])
+		}
+	}
+	if b.Len() > 0 || len(parts) < 2 {
+		parts = append(parts, b.String())
+	}
+	return parts
+}
+
+// sedReplacement converts \1-\9 and & of sed to the template of regexp.Expand.
+func sedReplacement(str string) string {
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case c == '\\' && i+1 < len(str) && '0' <= str[i+1] && str[i+1] <= '9':
+			fmt.Fprintf(&b, "${%c}", str[i+1])
+			i++
+		case c == '\\' && i+1 < len(str) && str[i+1] == '&':
+			b.WriteByte('&')
+			i++
+		case c == '\\' && i+1 < len(str) && str[i+1] == '\\':
+			b.WriteByte('\\')
+			i++
+		case c == '&':
+			b.WriteString("${0}")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// matches returns the positions of the matches to replace in str.
+func (s *substitution) matches(str string) [][]int {
+	n := 1
+	if s.global {
+		n = -1
+	}
+	return s.reg.FindAllStringSubmatchIndex(str, n)
+}
+
+// replaceString returns str with the matches replaced.
+func (s *substitution) replaceString(str string) string {
+	var b strings.Builder
+	last := 0
+	for _, match := range s.matches(str) {
+		b.WriteString(str[last:match[0]])
+		b.Write(s.reg.ExpandString(nil, s.replacement, str, match))
+		last = match[1]
+	}
+	b.WriteString(str[last:])
+	return b.String()
+}
+
+// substituteContents returns the contents with the matches replaced.
+// The replaced text is styled with the style of the substitution.
+func substituteContents(lc lineContents, s *substitution, tabWidth int) lineContents {
+	str, byteMap := contentsToStr(lc)
+	matches := s.matches(str)
+	if len(matches) == 0 {
+		return lc
+	}
+
+	out := make(lineContents, 0, len(lc))
+	last := 0
+	for _, match := range matches {
+		start, ok := byteMap[match[0]]
+		if !ok || start < last {
+			continue
+		}
+		end, ok := byteMap[match[1]]
+		if !ok {
+			continue
+		}
+		style := tcell.StyleDefault
+		switch {
+		case start < len(lc):
+			style = lc[start].style
+		case start > 0:
+			style = lc[start-1].style
+		}
+		out = append(out, lc[last:start]...)
+		rc := strToContents(string(s.reg.ExpandString(nil, s.replacement, str, match)), tabWidth)
+		for i := range rc {
+			rc[i].style = applyStyle(style, s.style)
+		}
+		out = append(out, rc...)
+		last = end
+	}
+	return append(out, lc[last:]...)
+}
+
+// replace sets the substitution that is applied to the display of the document.
+// The document itself is not changed, but the save buffer writes the replaced text.
+// An empty input clears the substitution.
+func (root *Root) replace(str string) {
+	m := root.Doc
+	if str == "" {
+		m.setSubstitution(nil)
+		root.setMessage("replace cleared")
+		return
+	}
+	s, err := parseSubstitution(str)
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	s.style = root.StyleReplace
+	m.setSubstitution(s)
+	root.setMessage(fmt.Sprintf("replace:%s", str))
+}
+
+// setSubstitution sets the substitution and clears the cache of the contents.
+func (m *Document) setSubstitution(s *substitution) {
+	m.substitution = s
+	m.ClearCache()
+}
@@ -0,0 +1,74 @@
+package oviewer
+
+import (
+	"errors"
+	"testing"
+)
+
+func Test_parseSubstitution(t *testing.T) {
+	tests := []struct {
+		name    string
+		str     string
+		input   string
+		want    string
+		wantErr error
+	}{
+		{name: "first", str: "s/a/b/", input: "aaa", want: "baa"},
+		{name: "global", str: "s/a/b/g", input: "aaa", want: "bbb"},
+		{name: "noTrailing", str: "s/a/b", input: "xa", want: "xb"},
+		{name: "insensitive", str: "s/A/b/gi", input: "aA", want: "bb"},
+		{name: "sedGroup", str: `s/(\w+)=(\w+)/\2=\1/`, input: "k=v", want: "v=k"},
+		{name: "ampersand", str: `s/\d+/<&>/g`, input: "a1b22", want: "a<1>b<22>"},
+		{name: "escapedAmpersand", str: `s/x/\&/`, input: "x", want: "&"},
+		{name: "goGroup", str: `s/(\d+)/${1}0/`, input: "5", want: "50"},
+		{name: "delimiter", str: "s|/usr|/opt|", input: "/usr/bin", want: "/opt/bin"},
+		{name: "escapedDelimiter", str: `s/\/usr/\/opt/`, input: "/usr/bin", want: "/opt/bin"},
+		{name: "empty", str: "s/a//g", input: "banana", want: "bnn"},
+		{name: "noPattern", str: "s//b/", wantErr: ErrInvalidSubstitution},
+		{name: "noReplacement", str: "s/a", wantErr: ErrInvalidSubstitution},
+		{name: "badFlag", str: "s/a/b/x", wantErr: ErrInvalidSubstitution},
+		{name: "badDelimiter", str: "sxaxbx", wantErr: ErrInvalidSubstitution},
+		{name: "notSubstitution", str: "a/b/", wantErr: ErrInvalidSubstitution},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSubstitution(tt.str)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("parseSubstitution() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.replaceString(tt.input); got != tt.want {
+				t.Errorf("substitution.replaceString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_substituteContents(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		line string
+		want string
+	}{
+		{name: "replace", str: "s/secret/***/g", line: "a secret b secret", want: "a *** b ***"},
+		{name: "color", str: "s/b/XX/", line: "a\x1b[31mb\x1b[0mc", want: "aXXc"},
+		{name: "wide", str: "s/い/i/", line: "あいう", want: "あiう"},
+		{name: "insert", str: "s/^/> /", line: "quote", want: "> quote"},
+		{name: "noMatch", str: "s/z/y/", line: "abc", want: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := parseSubstitution(tt.str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lc := substituteContents(parseString(tt.line, 8), s, 8)
+			if got, _ := contentsToStr(lc); got != tt.want {
+				t.Errorf("substituteContents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package oviewer
+
+import (
+	"log"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// smoothFrameInterval is the minimum interval between the frames of the smooth scroll.
+const smoothFrameInterval = 16 * time.Millisecond
+
+// smoothActions is a list of actions scrolled smoothly with SmoothScroll.
+var smoothActions = map[string]bool{
+	actionMovePgUp: true,
+	actionMovePgDn: true,
+	actionMoveHfUp: true,
+	actionMoveHfDn: true,
+}
+
+// smoothScrolling is the state of the smooth scroll in progress.
+type smoothScrolling struct {
+	doc     *Document
+	startLN int
+	endLN   int
+	endLX   int
+	frames  int
+	frame   int
+	// interval is the interval between the frames.
+	interval time.Duration
+	// lN is the line of the last frame, to detect the move by others.
+	lN int
+}
+
+// eventScrollFrame represents the next frame of the smooth scroll.
+type eventScrollFrame struct {
+	scroll *smoothScrolling
+	tcell.EventTime
+}
+
+// smoothScroll returns a function that calls f
+// and draws the intermediate frames to the moved position.
+// The frames are drawn by the events posted at the intervals,
+// so that the other events are handled during the scroll.
+func (root *Root) smoothScroll(f func()) func() {
+	return func() {
+		// A new movement starts from the end of the scroll in progress.
+		root.finishScroll()
+		m := root.Doc
+		startLN := m.topLN
+		f()
+		if root.SmoothScroll <= 0 || m != root.Doc {
+			return
+		}
+		root.startScroll(startLN)
+	}
+}
+
+// startScroll starts the smooth scroll from startLN to the current position in SmoothScroll milliseconds.
+func (root *Root) startScroll(startLN int) {
+	m := root.Doc
+	duration := time.Duration(root.SmoothScroll) * time.Millisecond
+	frames := smoothFrames(duration, m.topLN-startLN)
+	if frames == 0 {
+		return
+	}
+	s := &smoothScrolling{
+		doc:      m,
+		startLN:  startLN,
+		endLN:    m.topLN,
+		endLX:    m.topLX,
+		frames:   frames,
+		interval: duration / time.Duration(frames+1),
+	}
+	root.scrolling = s
+	root.scrollFrame(s)
+}
+
+// scrollFrame moves to the next frame of the smooth scroll and schedules the next one.
+// The scroll is canceled if another scroll has started or the position has been moved by others.
+func (root *Root) scrollFrame(s *smoothScrolling) {
+	if root.scrolling != s {
+		return
+	}
+	m := s.doc
+	if root.Doc != m || (s.frame > 0 && m.topLN != s.lN) {
+		root.scrolling = nil
+		return
+	}
+	s.frame++
+	if s.frame > s.frames {
+		m.topLN, m.topLX = s.endLN, s.endLX
+		root.scrolling = nil
+		return
+	}
+	m.topLN = s.startLN + (s.endLN-s.startLN)*s.frame/(s.frames+1)
+	m.topLX = 0
+	s.lN = m.topLN
+	time.AfterFunc(s.interval, func() {
+		ev := &eventScrollFrame{scroll: s}
+		ev.SetEventNow()
+		if err := root.Screen.PostEvent(ev); err != nil {
+			log.Println(err)
+		}
+	})
+}
+
+// finishScroll moves to the end of the smooth scroll in progress and stops it.
+func (root *Root) finishScroll() {
+	s := root.scrolling
+	if s == nil {
+		return
+	}
+	root.scrolling = nil
+	if root.Doc == s.doc && s.doc.topLN == s.lN {
+		s.doc.topLN, s.doc.topLX = s.endLN, s.endLX
+		// Draw to update the bottom line that the page movements are based on.
+		root.draw()
+	}
+}
+
+// smoothFrames returns the number of the intermediate frames
+// to scroll the lines in the duration.
+// There is at most one frame per line.
+func smoothFrames(duration time.Duration, lines int) int {
+	if lines < 0 {
+		lines = -lines
+	}
+	frames := int(duration / smoothFrameInterval)
+	return max(min(frames, lines-1), 0)
+}
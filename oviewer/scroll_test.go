@@ -0,0 +1,233 @@
+package oviewer
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func Test_smoothFrames(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		lines    int
+		want     int
+	}{
+		{name: "disabled", duration: 0, lines: 40, want: 0},
+		{name: "frames", duration: 160 * time.Millisecond, lines: 40, want: 10},
+		{name: "lines", duration: 160 * time.Millisecond, lines: 4, want: 3},
+		{name: "up", duration: 160 * time.Millisecond, lines: -40, want: 10},
+		{name: "noMove", duration: 160 * time.Millisecond, lines: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := smoothFrames(tt.duration, tt.lines); got != tt.want {
+				t.Errorf("smoothFrames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_smoothScroll(t *testing.T) {
+	lines := make([]string, 200)
+	for i := range lines {
+		lines[i] = strconv.Itoa(i)
+	}
+	m := testLineDocument(t, 0, lines...)
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Screen.(tcell.SimulationScreen).SetSize(80, 25)
+	root.prepareView()
+	root.draw()
+	root.SmoothScroll = 100
+	pgDn := root.smoothScroll(root.movePgDn)
+
+	start := time.Now()
+	pgDn()
+	if d := time.Since(start); d >= 100*time.Millisecond {
+		t.Errorf("smoothScroll() blocked for %v", d)
+	}
+	s := root.scrolling
+	if s == nil {
+		t.Fatal("smoothScroll() did not start the scroll")
+	}
+	if m.topLN <= 0 || m.topLN >= s.endLN {
+		t.Errorf("smoothScroll() topLN = %d, want the frame between 0 and %d", m.topLN, s.endLN)
+	}
+
+	// A new movement starts from the end of the scroll in progress.
+	end := s.endLN
+	pgDn()
+	if root.scrolling == s || root.scrolling == nil || root.scrolling.startLN != end {
+		t.Fatalf("the second scroll does not start from %d", end)
+	}
+
+	s = root.scrolling
+	for root.scrolling != nil {
+		if ev, ok := root.Screen.PollEvent().(*eventScrollFrame); ok {
+			root.scrollFrame(ev.scroll)
+		}
+	}
+	if m.topLN != s.endLN {
+		t.Errorf("smoothScroll() topLN = %d, want %d", m.topLN, s.endLN)
+	}
+}
+
+func TestRoot_scrollOff(t *testing.T) {
+	tests := []struct {
+		name      string
+		scrollOff int
+		statusPos int
+		want      int
+	}{
+		{name: "disabled", scrollOff: 0, statusPos: 24, want: 0},
+		{name: "scrollOff", scrollOff: 5, statusPos: 24, want: 5},
+		{name: "half", scrollOff: 20, statusPos: 24, want: 12},
+		{name: "negative", scrollOff: -1, statusPos: 24, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{
+				Doc:       testLineDocument(t, 0, "a"),
+				statusPos: tt.statusPos,
+			}
+			root.ScrollOff = tt.scrollOff
+			if got := root.scrollOff(); got != tt.want {
+				t.Errorf("Root.scrollOff() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_jumpOffset(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		scrollOff int
+		want      int
+	}{
+		{name: "top", target: "", scrollOff: 0, want: 0},
+		{name: "topScrollOff", target: "", scrollOff: 3, want: 3},
+		{name: "bottom", target: "-1", scrollOff: 0, want: 19},
+		{name: "bottomScrollOff", target: "-1", scrollOff: 3, want: 16},
+		{name: "center", target: "center", scrollOff: 3, want: 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{
+				Doc:       testLineDocument(t, 0, "a"),
+				statusPos: 20,
+			}
+			root.JumpTarget = tt.target
+			root.ScrollOff = tt.scrollOff
+			if got := root.jumpOffset(); got != tt.want {
+				t.Errorf("Root.jumpOffset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_jumpTargetHeight(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		height int
+		want   int
+		wantOk bool
+	}{
+		{name: "top", target: "", height: 20, want: 0, wantOk: true},
+		{name: "number", target: "3", height: 20, want: 3, wantOk: true},
+		{name: "bottom", target: "-5", height: 20, want: 15, wantOk: true},
+		{name: "percent", target: "30%", height: 20, want: 6, wantOk: true},
+		{name: "center", target: "center", height: 21, want: 10, wantOk: true},
+		{name: "invalid", target: "middle", height: 20, want: 0, wantOk: false},
+		{name: "invalidPercent", target: "150%", height: 20, want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := jumpTargetHeight(tt.target, tt.height)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("jumpTargetHeight() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_contentsX(t *testing.T) {
+	tests := []struct {
+		name string
+		str  string
+		n    int
+		want int
+	}{
+		{name: "ascii", str: "abcdef", n: 3, want: 3},
+		{name: "wide", str: "あいう", n: 2, want: 4},
+		{name: "over", str: "abc", n: 10, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := contentsX(parseString(tt.str, 8), tt.n); got != tt.want {
+				t.Errorf("contentsX() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseLineCol(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantLN  int
+		wantCol int
+		wantErr bool
+	}{
+		{name: "line", input: "1423", wantLN: 1423},
+		{name: "lineCol", input: "1423:87", wantLN: 1423, wantCol: 87},
+		{name: "space", input: " 12:3 ", wantLN: 12, wantCol: 3},
+		{name: "name", input: "usage", wantErr: true},
+		{name: "badCol", input: "12:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lN, col, err := parseLineCol(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLineCol() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if lN != tt.wantLN || col != tt.wantCol {
+				t.Errorf("parseLineCol() = %v, %v, want %v, %v", lN, col, tt.wantLN, tt.wantCol)
+			}
+		})
+	}
+}
+
+func TestRoot_reflowTop(t *testing.T) {
+	tests := []struct {
+		name     string
+		wrapMode bool
+		width    int
+		topLX    int
+		want     int
+	}{
+		{name: "row start", wrapMode: true, width: 10, topLX: 10, want: 10},
+		{name: "inside row", wrapMode: true, width: 10, topLX: 13, want: 10},
+		{name: "narrower", wrapMode: true, width: 8, topLX: 10, want: 8},
+		{name: "last row", wrapMode: true, width: 7, topLX: 24, want: 21},
+		{name: "no wrap", wrapMode: false, width: 10, topLX: 13, want: 13},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, 0, "0123456789012345678901234")
+			m.WrapMode = tt.wrapMode
+			m.topLX = tt.topLX
+			root := &Root{Doc: m, vWidth: tt.width}
+			root.reflowTop()
+			if m.topLX != tt.want {
+				t.Errorf("Root.reflowTop() topLX = %v, want %v", m.topLX, tt.want)
+			}
+		})
+	}
+}
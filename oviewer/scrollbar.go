@@ -0,0 +1,176 @@
+package oviewer
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// scanChunk is the number of the lines scanned for the match marks at a time.
+const scanChunk = 10000
+
+// matchMarks holds the lines that match the search for the scroll bar.
+// The lines are scanned in the background and the scroll bar is updated as the scan proceeds.
+type matchMarks struct {
+	mu sync.Mutex
+	// doc is the scanned document.
+	doc *Document
+	// pattern is the string of the scanned search.
+	pattern string
+	// column is the scanned column (-1 is the whole line).
+	column int
+	// lines are the line numbers that match in ascending order.
+	lines []int
+	// scanned is the line number up to which the scan is done.
+	scanned int
+	// scanning is true while the scan is running.
+	scanning bool
+	// gen is incremented when the search changes, to stop the old scan.
+	gen int
+}
+
+// reset starts over the marks if the document or the search has changed.
+func (mm *matchMarks) reset(m *Document, pattern string, column int) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if mm.doc == m && mm.pattern == pattern && mm.column == column {
+		return
+	}
+	mm.doc = m
+	mm.pattern = pattern
+	mm.column = column
+	mm.lines = nil
+	mm.scanned = m.Header
+	mm.scanning = false
+	mm.gen++
+}
+
+// next returns the range to scan and the generation of the scan,
+// and ok is false if there is nothing to scan or the scan is running.
+func (mm *matchMarks) next(end int) (start int, gen int, ok bool) {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if mm.scanning || mm.scanned >= end {
+		return 0, 0, false
+	}
+	mm.scanning = true
+	return mm.scanned, mm.gen, true
+}
+
+// add adds the matched lines scanned up to scanned,
+// and returns false if the search has changed.
+func (mm *matchMarks) add(gen int, lines []int, scanned int, done bool) bool {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	if gen != mm.gen {
+		return false
+	}
+	mm.lines = append(mm.lines, lines...)
+	mm.scanned = scanned
+	if done {
+		mm.scanning = false
+	}
+	return true
+}
+
+// count returns the number of the matched lines in the range [start, end).
+func (mm *matchMarks) count(start int, end int) int {
+	mm.mu.Lock()
+	defer mm.mu.Unlock()
+	return sort.SearchInts(mm.lines, end) - sort.SearchInts(mm.lines, start)
+}
+
+// scanMatchMarks scans the lines that match reg from start to end in the background.
+func (root *Root) scanMatchMarks(m *Document, reg Searcher, column int, start int, end int, gen int) {
+	go func() {
+		for start < end {
+			chunkEnd := min(start+scanChunk, end)
+			var lines []int
+			for n := start; n < chunkEnd; n++ {
+				if matchLine(reg, m.GetLine(n), m.ColumnDelimiter, column) {
+					lines = append(lines, n)
+				}
+			}
+			if !root.marks.add(gen, lines, chunkEnd, chunkEnd >= end) {
+				return
+			}
+			start = chunkEnd
+			// Redraw at the next update.
+			atomic.StoreInt32(&m.changed, 1)
+		}
+	}()
+}
+
+// updateMatchMarks starts the scan of the matches for the scroll bar
+// if the search has changed or the lines have been appended.
+func (root *Root) updateMatchMarks() {
+	m := root.Doc
+	pattern := ""
+	reg := root.input.reg
+	if reg != nil {
+		pattern = reg.String()
+	}
+	column := root.filterColumn()
+	root.marks.reset(m, pattern, column)
+	if reg == nil {
+		return
+	}
+	end := m.BufEndNum()
+	start, gen, ok := root.marks.next(end)
+	if !ok {
+		return
+	}
+	root.scanMatchMarks(m, reg, column, start, end, gen)
+}
+
+// scrollBarRange returns the range of the lines for the row y of the scroll bar of the height.
+func scrollBarRange(y int, height int, total int) (int, int) {
+	return y * total / height, (y + 1) * total / height
+}
+
+// drawScrollBar draws the scroll bar in the right column of the screen,
+// with the marks of the rows where the search matches.
+func (root *Root) drawScrollBar() {
+	if !root.ScrollBar {
+		return
+	}
+	root.updateMatchMarks()
+
+	m := root.Doc
+	x := root.vWidth
+	height := root.statusPos
+	total := m.BufEndNum()
+	if height <= 0 || total == 0 {
+		return
+	}
+
+	counts := make([]int, height)
+	maxCount := 0
+	for y := 0; y < height; y++ {
+		start, end := scrollBarRange(y, height, total)
+		counts[y] = root.marks.count(start, end)
+		maxCount = max(maxCount, counts[y])
+	}
+
+	thumbStyle := applyStyle(tcell.StyleDefault, root.StyleScrollBar)
+	for y := 0; y < height; y++ {
+		start, end := scrollBarRange(y, height, total)
+		style := tcell.StyleDefault
+		if start <= root.bottomLN && (end > m.topLN || start == end && start >= m.topLN) {
+			style = thumbStyle
+		}
+		r := ' '
+		if counts[y] > 0 {
+			style = applyStyle(style, root.StyleScrollBarMatch)
+			r = '-'
+			// The rows with many matches are marked heavier.
+			if maxCount > 1 && counts[y]*2 >= maxCount {
+				r = '='
+			}
+		}
+		root.Screen.SetContent(x, y, r, nil, style)
+	}
+	root.Screen.SetContent(x, root.statusPos, ' ', nil, tcell.StyleDefault)
+}
@@ -0,0 +1,74 @@
+package oviewer
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func Test_scrollBarRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		y         int
+		height    int
+		total     int
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "first", y: 0, height: 10, total: 100, wantStart: 0, wantEnd: 10},
+		{name: "last", y: 9, height: 10, total: 100, wantStart: 90, wantEnd: 100},
+		{name: "short", y: 3, height: 10, total: 5, wantStart: 1, wantEnd: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := scrollBarRange(tt.y, tt.height, tt.total)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("scrollBarRange() = %v, %v, want %v, %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestRoot_updateMatchMarks(t *testing.T) {
+	m := testLineDocument(t, 1, "match header", "a", "match", "b", "match match", "c")
+	root := &Root{Doc: m, input: &Input{}}
+	root.input.reg = regexp.MustCompile("match")
+	root.updateMatchMarks()
+	waitMarks(t, root)
+	if got := root.marks.count(0, m.BufEndNum()); got != 2 {
+		t.Errorf("matchMarks.count() = %v, want 2", got)
+	}
+	if got := root.marks.count(3, 4); got != 0 {
+		t.Errorf("matchMarks.count(3, 4) = %v, want 0", got)
+	}
+
+	// The appended lines are scanned.
+	m.append("match")
+	root.updateMatchMarks()
+	waitMarks(t, root)
+	if got := root.marks.count(0, m.BufEndNum()); got != 3 {
+		t.Errorf("matchMarks.count() = %v, want 3", got)
+	}
+
+	// The new search starts over.
+	root.input.reg = regexp.MustCompile("b")
+	root.updateMatchMarks()
+	waitMarks(t, root)
+	if got := root.marks.count(0, m.BufEndNum()); got != 1 {
+		t.Errorf("matchMarks.count() = %v, want 1", got)
+	}
+}
+
+func waitMarks(t *testing.T, root *Root) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		root.marks.mu.Lock()
+		scanning := root.marks.scanning
+		root.marks.mu.Unlock()
+		if !scanning {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("the scan of the match marks did not finish")
+}
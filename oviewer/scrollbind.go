@@ -0,0 +1,60 @@
+package oviewer
+
+// scrollBind represents the scroll-bind mode,
+// which moves the other documents by the same amount as the current document.
+type scrollBind struct {
+	// doc is the document whose position is recorded.
+	doc *Document
+	// topLN and x are the recorded position of doc.
+	topLN int
+	x     int
+}
+
+// toggleScrollBind toggles the scroll-bind mode each time it is called.
+func (root *Root) toggleScrollBind() {
+	if root.scrollBind != nil {
+		root.scrollBind = nil
+		root.setMessage("Set ScrollBind false")
+		return
+	}
+	root.scrollBind = &scrollBind{doc: root.Doc, topLN: root.Doc.topLN, x: root.Doc.x}
+	root.setMessage("Set ScrollBind true")
+}
+
+// syncScrollBind moves the other documents by the amount
+// that the current document has moved since the last call.
+func (root *Root) syncScrollBind() {
+	b := root.scrollBind
+	if b == nil || root.screenMode != Docs {
+		return
+	}
+	m := root.Doc
+	if b.doc == m {
+		dy, dx := m.topLN-b.topLN, m.x-b.x
+		if dy != 0 || dx != 0 {
+			root.mu.RLock()
+			for _, doc := range root.DocList {
+				if doc != m {
+					doc.scrollBy(dy, dx)
+				}
+			}
+			root.mu.RUnlock()
+		}
+	}
+	b.doc, b.topLN, b.x = m, m.topLN, m.x
+}
+
+// scrollBy moves the position of the document by dy lines and dx columns.
+func (m *Document) scrollBy(dy int, dx int) {
+	m.topLN = max(0, min(m.topLN+dy, m.BufEndNum()-1))
+	m.topLX = 0
+	m.x = max(0, m.x+dx)
+}
+
+// scrollBindStatus returns the status of the scroll-bind mode.
+func (root *Root) scrollBindStatus() string {
+	if root.scrollBind == nil {
+		return ""
+	}
+	return "(Scroll Bind)"
+}
@@ -0,0 +1,51 @@
+package oviewer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_syncScrollBind(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	docs := testDocList(t, "a", "b")
+	for _, m := range docs {
+		if err := m.ReadAll(strings.NewReader(strings.Repeat("line\n", 10))); err != nil {
+			t.Fatal(err)
+		}
+		<-m.eofCh
+	}
+	root, err := NewOviewer(docs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs[1].topLN = 2
+	root.toggleScrollBind()
+
+	tests := []struct {
+		name  string
+		topLN int
+		x     int
+		want  int
+		wantX int
+	}{
+		{name: "down", topLN: 3, x: 0, want: 5, wantX: 0},
+		{name: "right", topLN: 3, x: 4, want: 5, wantX: 4},
+		{name: "bottom", topLN: 9, x: 4, want: 9, wantX: 4},
+		{name: "top", topLN: 0, x: 0, want: 0, wantX: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root.Doc.topLN = tt.topLN
+			root.Doc.x = tt.x
+			root.syncScrollBind()
+			if docs[1].topLN != tt.want || docs[1].x != tt.wantX {
+				t.Errorf("Root.syncScrollBind() = %d, %d, want %d, %d", docs[1].topLN, docs[1].x, tt.want, tt.wantX)
+			}
+		})
+	}
+}
@@ -2,10 +2,12 @@ package oviewer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"regexp"
 	"strings"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -26,7 +28,7 @@ func (root *Root) forwardSearch(ctx context.Context, input string) {
 		return
 	}
 	root.input.value = input
-	root.search(ctx, root.Doc.topLN+root.Doc.Header, root.searchLine)
+	root.search(ctx, root.Doc.currentLN(), root.searchLine)
 }
 
 // backSearch is backward search.
@@ -36,12 +38,34 @@ func (root *Root) backSearch(ctx context.Context, input string) {
 		return
 	}
 	root.input.value = input
-	root.search(ctx, root.Doc.topLN+root.Doc.Header, root.backSearchLine)
+	root.search(ctx, root.Doc.currentLN(), root.backSearchLine)
 }
 
-// search searches forward or backward.
-func (root *Root) search(ctx context.Context, lN int, searchFunc func(context.Context, int) (int, error)) {
+// nextSearch searches forward count times from the line after the current line.
+// It stops at the first search that is not found or canceled.
+func (root *Root) nextSearch(ctx context.Context, count int) {
+	for i := 0; i < max(count, 1); i++ {
+		if err := root.search(ctx, root.Doc.currentLN()+1, root.searchLine); err != nil {
+			return
+		}
+	}
+}
+
+// nextBackSearch searches backward count times from the line before the current line.
+// It stops at the first search that is not found or canceled.
+func (root *Root) nextBackSearch(ctx context.Context, count int) {
+	for i := 0; i < max(count, 1); i++ {
+		if err := root.search(ctx, root.Doc.currentLN()-1, root.backSearchLine); err != nil {
+			return
+		}
+	}
+}
+
+// search searches forward or backward, and moves to the line found.
+// It returns ErrNotFound or ErrCancel if it did not move.
+func (root *Root) search(ctx context.Context, lN int, searchFunc func(context.Context, int) (int, error)) error {
 	root.setMessage(fmt.Sprintf("search:%v (%v)Cancel", root.input.value, strings.Join(root.cancelKeys, ",")))
+	root.searchWrapped = false
 
 	eg, ctx := errgroup.WithContext(ctx)
 	ctx, cancel := context.WithCancel(ctx)
@@ -51,20 +75,28 @@ func (root *Root) search(ctx context.Context, lN int, searchFunc func(context.Co
 		return root.cancelWait(cancel)
 	})
 
+	start := time.Now()
 	eg.Go(func() error {
 		lN, err := searchFunc(ctx, lN)
 		if err != nil {
 			return err
 		}
-		root.moveLine(lN - root.Doc.Header)
+		root.jumpLine(lN)
 		return nil
 	})
 
-	if err := eg.Wait(); err != nil {
+	err := eg.Wait()
+	root.observeDuration(MetricSearch, start)
+	if err != nil {
 		root.setMessage(err.Error())
-		return
+		return err
+	}
+	if root.searchWrapped {
+		root.setMessage(fmt.Sprintf("search:%v (wrapped)", root.input.value))
+		return nil
 	}
 	root.setMessage(fmt.Sprintf("search:%v", root.input.value))
+	return nil
 }
 
 // searchLine is searches below from the specified line.
@@ -76,25 +108,21 @@ func (root *Root) searchLine(ctx context.Context, num int) (int, error) {
 		return num, ErrNotFound
 	}
 
-	root.input.reg = regexpComple(root.input.value, root.CaseSensitive)
+	root.input.reg = root.newSearcher(root.input.value)
 	if root.input.reg == nil {
 		return num, ErrNotFound
 	}
 
-	searchType := getSearchType(root.input.value, root.CaseSensitive)
+	searchType := root.searchType(root.input.value)
 
-	for n := num; n < root.Doc.BufEndNum(); n++ {
-		if root.contains(root.Doc.GetLine(n), searchType) {
-			return n, nil
-		}
-		select {
-		case <-ctx.Done():
-			return 0, ErrCancel
-		default:
-		}
+	lo, hi := root.searchRange()
+	num = max(num, lo)
+	lN, err := root.findLine(ctx, num, hi, 1, searchType)
+	if errors.Is(err, ErrNotFound) && root.SearchWrap {
+		lN, err = root.findLine(ctx, lo, num, 1, searchType)
+		root.searchWrapped = err == nil
 	}
-
-	return 0, ErrNotFound
+	return lN, err
 }
 
 // backsearch is searches upward from the specified line.
@@ -102,15 +130,43 @@ func (root *Root) backSearchLine(ctx context.Context, num int) (int, error) {
 	defer root.searchQuit()
 	num = min(num, root.Doc.BufEndNum()-1)
 
-	root.input.reg = regexpComple(root.input.value, root.CaseSensitive)
+	root.input.reg = root.newSearcher(root.input.value)
 	if root.input.reg == nil {
-		return num, nil
+		return num, ErrNotFound
+	}
+
+	searchType := root.searchType(root.input.value)
+
+	lo, hi := root.searchRange()
+	num = min(num, hi-1)
+	lN, err := root.findLine(ctx, num, lo-1, -1, searchType)
+	if errors.Is(err, ErrNotFound) && root.SearchWrap {
+		lN, err = root.findLine(ctx, hi-1, num, -1, searchType)
+		root.searchWrapped = err == nil
 	}
+	return lN, err
+}
 
-	searchType := getSearchType(root.input.value, root.CaseSensitive)
+// searchRange returns the range of the lines [lo, hi) to be searched.
+// In the section search, it is the section that contains the current line.
+func (root *Root) searchRange() (lo int, hi int) {
+	m := root.Doc
+	if root.sectionSearch {
+		if start, end, ok := m.sectionRange(m.topLN + m.Header); ok {
+			return start, end
+		}
+	}
+	return 0, m.BufEndNum()
+}
 
-	for n := num; n >= 0; n-- {
-		if root.contains(root.Doc.GetLine(n), searchType) {
+// findLine returns the first line that contains the search string,
+// from start to end (not included) in the direction of step.
+func (root *Root) findLine(ctx context.Context, start int, end int, step int, searchType SearchType) (int, error) {
+	if searchType != searchRegexp && !root.isColumnSearch() {
+		return root.Doc.findLiteral(ctx, start, end, step, root.input.value, searchType == searchInsensitive)
+	}
+	for n := start; (step > 0 && n < end) || (step < 0 && n > end); n += step {
+		if root.contains(root.searchTarget(root.Doc.lineForSearch(n)), searchType) {
 			return n, nil
 		}
 		select {
@@ -122,6 +178,40 @@ func (root *Root) backSearchLine(ctx context.Context, num int) (int, error) {
 	return 0, ErrNotFound
 }
 
+// isColumnSearch returns true if the search is restricted to the selected column.
+func (root *Root) isColumnSearch() bool {
+	return root.columnSearch && root.Doc.ColumnMode
+}
+
+// searchTarget returns the part of the line to be searched.
+func (root *Root) searchTarget(line string) string {
+	if !root.isColumnSearch() {
+		return line
+	}
+	if strings.ContainsAny(line, "\x1b\b") {
+		line = stripEscapeSequence.ReplaceAllString(line, "")
+	}
+	return columnString(line, root.Doc.ColumnDelimiter, root.Doc.columnNum)
+}
+
+// searchPositions returns the positions of the search matches in the line.
+// In the column search, only the selected column is searched.
+func (root *Root) searchPositions(str string) [][]int {
+	if !root.isColumnSearch() {
+		return searchPosition(str, root.input.reg)
+	}
+	start, end := columnRange(str, root.Doc.ColumnDelimiter, root.Doc.columnNum)
+	if start < 0 {
+		return nil
+	}
+	poss := searchPosition(str[start:end], root.input.reg)
+	for _, pos := range poss {
+		pos[0] += start
+		pos[1] += start
+	}
+	return poss
+}
+
 // regexpComple is regexp.Compile the search string.
 func regexpComple(r string, caseSensitive bool) *regexp.Regexp {
 	if !caseSensitive {
@@ -153,7 +243,7 @@ func (root *Root) contains(s string, t SearchType) bool {
 	case searchSensitive:
 		return strings.Contains(s, root.input.value)
 	case searchInsensitive:
-		return strings.Contains(strings.ToLower(s), strings.ToLower(root.input.value))
+		return containsFold(s, root.input.value)
 	default:
 		return root.input.reg.MatchString(s)
 	}
@@ -205,11 +295,33 @@ func rangePosition(s, substr string, number int) (int, int) {
 	return start, end
 }
 
+// columnRange returns the range of the column of number in s.
+// It returns -1, -1 if there is no column.
+func columnRange(s string, delimiter string, number int) (int, int) {
+	start, end := rangePosition(s, delimiter, number)
+	if start < 0 {
+		return -1, -1
+	}
+	if end < 0 || end < start {
+		end = len(s)
+	}
+	return start, end
+}
+
+// columnString returns the column of number in s.
+func columnString(s string, delimiter string, number int) string {
+	start, end := columnRange(s, delimiter, number)
+	if start < 0 {
+		return ""
+	}
+	return s[start:end]
+}
+
 // searchPosition returns an array of the beginning and end of the search string.
-func searchPosition(s string, re *regexp.Regexp) [][]int {
+func searchPosition(s string, re Searcher) [][]int {
 	if re == nil || re.String() == "" {
 		return nil
 	}
 
-	return re.FindAllIndex([]byte(s), -1)
+	return re.FindAllStringIndex(s, -1)
 }
@@ -1,9 +1,12 @@
 package oviewer
 
 import (
+	"context"
 	"reflect"
 	"regexp"
 	"testing"
+
+	"github.com/gdamore/tcell/v2"
 )
 
 func TestRoot_contains(t *testing.T) {
@@ -293,3 +296,254 @@ func Test_searchPosition(t *testing.T) {
 		})
 	}
 }
+
+func Test_columnString(t *testing.T) {
+	type args struct {
+		s         string
+		delimiter string
+		number    int
+	}
+	tests := []struct {
+		name string
+		args args
+		want string
+	}{
+		{name: "first", args: args{s: "a,b,c", delimiter: ",", number: 0}, want: "a"},
+		{name: "middle", args: args{s: "a,b,c", delimiter: ",", number: 1}, want: "b"},
+		{name: "last", args: args{s: "a,b,c", delimiter: ",", number: 2}, want: "c"},
+		{name: "none", args: args{s: "a,b,c", delimiter: ",", number: 3}, want: ""},
+		{name: "noDelimiter", args: args{s: "abc", delimiter: ",", number: 0}, want: "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnString(tt.args.s, tt.args.delimiter, tt.args.number); got != tt.want {
+				t.Errorf("columnString() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_searchRange(t *testing.T) {
+	m := testLineDocument(t, 1, "header", "# one", "a", "b", "# two", "c", "d")
+	m.SectionDelimiter = "^#"
+	tests := []struct {
+		name          string
+		sectionSearch bool
+		topLN         int
+		wantLo        int
+		wantHi        int
+	}{
+		{name: "all", sectionSearch: false, topLN: 1, wantLo: 0, wantHi: 7},
+		{name: "first section", sectionSearch: true, topLN: 1, wantLo: 1, wantHi: 4},
+		{name: "second section", sectionSearch: true, topLN: 4, wantLo: 4, wantHi: 7},
+		{name: "no section", sectionSearch: true, topLN: -1, wantLo: 0, wantHi: 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{Doc: m, sectionSearch: tt.sectionSearch}
+			m.topLN = tt.topLN
+			lo, hi := root.searchRange()
+			if lo != tt.wantLo || hi != tt.wantHi {
+				t.Errorf("Root.searchRange() = %v, %v, want %v, %v", lo, hi, tt.wantLo, tt.wantHi)
+			}
+		})
+	}
+}
+
+func TestRoot_checkRegexp(t *testing.T) {
+	tests := []struct {
+		name         string
+		regexpSearch bool
+		mode         InputMode
+		value        string
+		wantErr      bool
+	}{
+		{
+			name:         "valid",
+			regexpSearch: true,
+			mode:         Search,
+			value:        "a.*b",
+			wantErr:      false,
+		},
+		{
+			name:         "invalid",
+			regexpSearch: true,
+			mode:         Search,
+			value:        "a(b",
+			wantErr:      true,
+		},
+		{
+			name:         "invalid filter",
+			regexpSearch: true,
+			mode:         Filter,
+			value:        "[a",
+			wantErr:      true,
+		},
+		{
+			name:         "literal search",
+			regexpSearch: false,
+			mode:         Backsearch,
+			value:        "a(b",
+			wantErr:      false,
+		},
+		{
+			name:         "other mode",
+			regexpSearch: true,
+			mode:         Goline,
+			value:        "a(b",
+			wantErr:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{input: &Input{mode: tt.mode, value: tt.value}}
+			root.RegexpSearch = tt.regexpSearch
+			root.checkRegexp()
+			if (root.input.regexpErr != nil) != tt.wantErr {
+				t.Errorf("Root.checkRegexp() error = %v, wantErr %v", root.input.regexpErr, tt.wantErr)
+			}
+			if tt.wantErr && root.input.hint == "" {
+				t.Errorf("Root.checkRegexp() hint is empty")
+			}
+		})
+	}
+}
+
+// testSearchRoot returns the root of the 24-line screen
+// of the document that contains "match" at the lines of matches.
+func testSearchRoot(t *testing.T, matches ...int) *Root {
+	t.Helper()
+	lines := make([]string, 100)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	for _, n := range matches {
+		lines[n] = "match"
+	}
+	m := testLineDocument(t, 0, lines...)
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Screen.(tcell.SimulationScreen).SetSize(80, 25)
+	root.prepareView()
+	root.draw()
+	return root
+}
+
+func TestRoot_nextSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		target    string
+		scrollOff int
+	}{
+		{name: "top", target: "", scrollOff: 0},
+		{name: "scrollOff", target: "", scrollOff: 3},
+		{name: "center", target: "center", scrollOff: 0},
+		{name: "number", target: "5", scrollOff: 0},
+		{name: "bottom", target: "-1", scrollOff: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testSearchRoot(t, 5, 30, 60)
+			root.JumpTarget = tt.target
+			root.ScrollOff = tt.scrollOff
+			ctx := context.Background()
+
+			root.forwardSearch(ctx, "match")
+			root.draw()
+			if got := root.Doc.jumpLN; got != 5 {
+				t.Fatalf("forwardSearch() jumpLN = %d, want 5", got)
+			}
+			for _, want := range []int{30, 60} {
+				root.nextSearch(ctx, 1)
+				root.draw()
+				if got := root.Doc.jumpLN; got != want {
+					t.Fatalf("nextSearch() jumpLN = %d, want %d", got, want)
+				}
+			}
+			for _, want := range []int{30, 5} {
+				root.nextBackSearch(ctx, 1)
+				root.draw()
+				if got := root.Doc.jumpLN; got != want {
+					t.Fatalf("nextBackSearch() jumpLN = %d, want %d", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoot_nextSearchCount(t *testing.T) {
+	root := testSearchRoot(t, 5, 30)
+	observer := newTestObserver()
+	root.SetObserver(observer)
+	ctx := context.Background()
+
+	root.forwardSearch(ctx, "match")
+	// The repeat stops at the first search that is not found.
+	root.nextSearch(ctx, 1000)
+	if got := root.Doc.jumpLN; got != 30 {
+		t.Errorf("nextSearch() jumpLN = %d, want 30", got)
+	}
+	root.nextBackSearch(ctx, 1000)
+	if got := root.Doc.jumpLN; got != 5 {
+		t.Errorf("nextBackSearch() jumpLN = %d, want 5", got)
+	}
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	if got := observer.durations[MetricSearch]; got != 5 {
+		t.Errorf("searched %d times, want 5", got)
+	}
+}
+
+func TestRoot_searchWrap(t *testing.T) {
+	tests := []struct {
+		name        string
+		wrap        bool
+		back        bool
+		wantLN      int
+		wantMessage string
+	}{
+		{name: "forward", wrap: true, back: false, wantLN: 5, wantMessage: "search:match (wrapped)"},
+		{name: "forward no wrap", wrap: false, back: false, wantLN: 30, wantMessage: ErrNotFound.Error()},
+		{name: "backward", wrap: true, back: true, wantLN: 30, wantMessage: "search:match (wrapped)"},
+		{name: "backward no wrap", wrap: false, back: true, wantLN: 5, wantMessage: ErrNotFound.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := testSearchRoot(t, 5, 30)
+			root.SearchWrap = tt.wrap
+			ctx := context.Background()
+			root.input.value = "match"
+			start := 30
+			if tt.back {
+				start = 5
+			}
+			root.jumpLine(start)
+			root.draw()
+			if tt.back {
+				root.nextBackSearch(ctx, 1)
+			} else {
+				root.nextSearch(ctx, 1)
+			}
+			if got := root.Doc.jumpLN; got != tt.wantLN {
+				t.Errorf("jumpLN = %d, want %d", got, tt.wantLN)
+			}
+			if root.message != tt.wantMessage {
+				t.Errorf("message = %q, want %q", root.message, tt.wantMessage)
+			}
+			if root.searchWrapped != tt.wrap {
+				t.Errorf("searchWrapped = %v, want %v", root.searchWrapped, tt.wrap)
+			}
+		})
+	}
+
+	// A search that does not wrap clears the notice.
+	root := testSearchRoot(t, 5, 30)
+	root.SearchWrap = true
+	root.input.value = "match"
+	root.nextSearch(context.Background(), 1)
+	if root.searchWrapped || root.message != "search:match" {
+		t.Errorf("searchWrapped = %v, message = %q, want false, %q", root.searchWrapped, root.message, "search:match")
+	}
+}
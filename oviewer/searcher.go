@@ -0,0 +1,46 @@
+package oviewer
+
+import "regexp"
+
+// Searcher is the interface that matches the search string in the lines.
+// *regexp.Regexp implements Searcher.
+// The Searcher is used to move to the match, highlight the matches and filter the lines.
+type Searcher interface {
+	// MatchString reports whether the line contains a match.
+	MatchString(s string) bool
+	// FindAllStringIndex returns the positions of the matches in the line.
+	// If n >= 0, it returns at most n matches.
+	FindAllStringIndex(s string, n int) [][]int
+	// String returns the source text of the search.
+	String() string
+}
+
+// SetSearcher sets the function that returns the Searcher of the search string.
+// If the function returns nil, the built-in searcher is used.
+func (root *Root) SetSearcher(f func(str string) Searcher) {
+	root.searcherFunc = f
+}
+
+// newSearcher returns the Searcher of the search string.
+// It returns nil if the search string cannot be searched.
+func (root *Root) newSearcher(str string) Searcher {
+	if root.searcherFunc != nil {
+		if s := root.searcherFunc(str); s != nil {
+			return s
+		}
+	}
+	// Do not return a nil *regexp.Regexp as a non-nil Searcher.
+	if reg := root.searchRegexp(str); reg != nil {
+		return reg
+	}
+	return nil
+}
+
+// isCustomSearcher returns true if the current search uses a Searcher set by SetSearcher.
+func (root *Root) isCustomSearcher() bool {
+	if root.input == nil || root.input.reg == nil {
+		return false
+	}
+	_, ok := root.input.reg.(*regexp.Regexp)
+	return !ok
+}
@@ -0,0 +1,92 @@
+package oviewer
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// wordSearcher matches the whole words separated by spaces.
+type wordSearcher string
+
+func (w wordSearcher) MatchString(s string) bool {
+	return len(w.FindAllStringIndex(s, 1)) > 0
+}
+
+func (w wordSearcher) FindAllStringIndex(s string, n int) [][]int {
+	var poss [][]int
+	start := 0
+	for _, f := range strings.Split(s, " ") {
+		if f == string(w) && (n < 0 || len(poss) < n) {
+			poss = append(poss, []int{start, start + len(f)})
+		}
+		start += len(f) + 1
+	}
+	return poss
+}
+
+func (w wordSearcher) String() string {
+	return string(w)
+}
+
+func TestRoot_newSearcher(t *testing.T) {
+	tests := []struct {
+		name       string
+		searcher   func(string) Searcher
+		str        string
+		wantCustom bool
+	}{
+		{
+			name:       "builtin",
+			searcher:   nil,
+			str:        "foo",
+			wantCustom: false,
+		},
+		{
+			name:       "custom",
+			searcher:   func(str string) Searcher { return wordSearcher(str) },
+			str:        "foo",
+			wantCustom: true,
+		},
+		{
+			name: "fallback",
+			searcher: func(str string) Searcher {
+				return nil
+			},
+			str:        "foo",
+			wantCustom: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Root{input: &Input{}}
+			root.SetSearcher(tt.searcher)
+			root.input.reg = root.newSearcher(tt.str)
+			if root.input.reg == nil {
+				t.Fatal("Root.newSearcher() = nil")
+			}
+			if got := root.isCustomSearcher(); got != tt.wantCustom {
+				t.Errorf("Root.isCustomSearcher() = %v, want %v", got, tt.wantCustom)
+			}
+		})
+	}
+}
+
+func TestRoot_findLine_searcher(t *testing.T) {
+	m := testLineDocument(t, 0, "food bar", "foo bar", "bar foo")
+	root := &Root{Doc: m, input: &Input{value: "foo"}}
+	root.SetSearcher(func(str string) Searcher { return wordSearcher(str) })
+	root.input.reg = root.newSearcher(root.input.value)
+
+	got, err := root.findLine(context.Background(), 0, m.BufEndNum(), 1, root.searchType(root.input.value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("Root.findLine() = %v, want %v", got, 1)
+	}
+	if poss := searchPosition(m.GetLine(2), root.input.reg); !reflect.DeepEqual(poss, [][]int{{4, 7}}) {
+		t.Errorf("searchPosition() = %v, want %v", poss, [][]int{{4, 7}})
+	}
+}
@@ -0,0 +1,306 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync/atomic"
+)
+
+// SectionRule overrides the header settings of the sections
+// whose first line matches Pattern.
+type SectionRule struct {
+	// Pattern is a regular expression that matches the first line of the section.
+	Pattern string
+	// Header is the number of header lines displayed in the section.
+	Header *int `json:",omitempty"`
+	// SectionHeaderNum is the number of lines of the section header.
+	SectionHeaderNum *int `json:",omitempty"`
+}
+
+// sectionRegexp returns the compiled SectionDelimiter.
+// It returns nil if SectionDelimiter is empty or invalid.
+func (m *Document) sectionRegexp() *regexp.Regexp {
+	if m.SectionDelimiter == "" {
+		return nil
+	}
+	if m.sectionReg != nil && m.sectionRegStr == m.SectionDelimiter {
+		return m.sectionReg
+	}
+	reg, err := regexp.Compile(m.SectionDelimiter)
+	if err != nil {
+		log.Printf("section delimiter: %s", err)
+		m.SectionDelimiter = ""
+		return nil
+	}
+	m.sectionReg = reg
+	m.sectionRegStr = m.SectionDelimiter
+	m.resetSectionCache()
+	return reg
+}
+
+// resetSectionCache discards the result of the last sectionStart,
+// when the lines or the line numbers have changed.
+func (m *Document) resetSectionCache() {
+	m.sectionCacheLN = -1
+}
+
+// sectionStart returns the first line of the section that contains lN.
+// It returns -1 if lN is not in a section.
+func (m *Document) sectionStart(lN int) int {
+	reg := m.sectionRegexp()
+	if reg == nil {
+		return -1
+	}
+	lN = min(lN, m.BufEndNum()-1)
+
+	// The result of the last line is reused,
+	// because there are no section delimiters between the start and the line.
+	bottom := m.Header
+	if m.sectionCacheLN >= 0 && m.sectionCacheHeader == m.Header {
+		switch {
+		case lN >= m.sectionCacheLN:
+			bottom = m.sectionCacheLN + 1
+		case lN >= m.sectionCacheStart:
+			return m.sectionCacheStart
+		}
+	}
+
+	start := -1
+	for n := lN; n >= max(bottom, m.Header); n-- {
+		if reg.MatchString(m.GetLine(n)) {
+			start = n
+			break
+		}
+	}
+	if start < 0 && bottom > m.Header {
+		start = m.sectionCacheStart
+	}
+	m.sectionCacheLN = lN
+	m.sectionCacheStart = start
+	m.sectionCacheHeader = m.Header
+	return start
+}
+
+// sectionRange returns the range of the lines [start, end) of the section that contains lN.
+// ok is false if lN is not in a section.
+func (m *Document) sectionRange(lN int) (start int, end int, ok bool) {
+	start = m.sectionStart(lN)
+	if start < 0 {
+		return 0, 0, false
+	}
+	reg := m.sectionRegexp()
+	end = m.BufEndNum()
+	for n := start + 1; n < end; n++ {
+		if reg.MatchString(m.GetLine(n)) {
+			return start, n, true
+		}
+	}
+	return start, end, true
+}
+
+// sectionRule returns the rule that matches the first line of the section.
+func (root *Root) sectionRule(line string) (SectionRule, bool) {
+	for _, rule := range root.SectionRules {
+		reg, ok := root.sectionRuleRegs[rule.Pattern]
+		if !ok {
+			var err error
+			reg, err = regexp.Compile(rule.Pattern)
+			if err != nil {
+				log.Printf("section rule: %s", err)
+			}
+			if root.sectionRuleRegs == nil {
+				root.sectionRuleRegs = make(map[string]*regexp.Regexp)
+			}
+			root.sectionRuleRegs[rule.Pattern] = reg
+		}
+		if reg != nil && reg.MatchString(line) {
+			return rule, true
+		}
+	}
+	return SectionRule{}, false
+}
+
+// prepareSection prepares the header and the section header
+// for the section of the current position.
+func (root *Root) prepareSection() {
+	m := root.Doc
+	root.headerNum = m.Header
+	root.sectionHeaderStart = -1
+	root.sectionHeaderNum = 0
+	root.sectionHeaderLen = 0
+
+	topLN := m.topLN + m.Header
+	root.prepareHeaderLine(topLN)
+
+	start := m.sectionStart(topLN)
+	if start < 0 {
+		return
+	}
+
+	num := m.SectionHeaderNum
+	if rule, ok := root.sectionRule(m.GetLine(start)); ok {
+		if rule.SectionHeaderNum != nil {
+			num = *rule.SectionHeaderNum
+		}
+		if rule.Header != nil {
+			root.headerNum = min(max(*rule.Header, 0), m.Header)
+		}
+	}
+	// The section header is displayed after it scrolls out of the body.
+	if num <= 0 || start+num > topLN {
+		return
+	}
+
+	root.sectionHeaderStart = start
+	root.sectionHeaderNum = num
+	root.sectionHeaderLen = num
+	if m.WrapMode {
+		root.sectionHeaderLen = root.wrapRows(start, num)
+	}
+}
+
+// prepareHeaderLine prepares the header row of HeaderLine,
+// which is displayed after it scrolls out of the body.
+func (root *Root) prepareHeaderLine(topLN int) {
+	m := root.Doc
+	root.headerLineStart = -1
+	root.headerLineLen = 0
+	lN := m.HeaderLine - 1
+	if lN < m.Header || lN >= topLN {
+		return
+	}
+	root.headerLineStart = lN
+	root.headerLineLen = 1
+	if m.WrapMode {
+		root.headerLineLen = root.wrapRows(lN, 1)
+	}
+}
+
+// sectionNames returns the first lines of the sections without the escape sequences.
+func (m *Document) sectionNames() []string {
+	reg := m.sectionRegexp()
+	if reg == nil {
+		return nil
+	}
+	names := make([]string, 0)
+	for n := m.Header; n < m.BufEndNum(); n++ {
+		line := plainLine(m.GetLine(n))
+		if reg.MatchString(line) {
+			names = append(names, strings.TrimSpace(line))
+		}
+	}
+	return names
+}
+
+// matchSectionNames returns the names that contain str case-insensitively.
+func matchSectionNames(names []string, str string) []string {
+	str = strings.ToLower(strings.TrimSpace(str))
+	list := make([]string, 0)
+	for _, name := range names {
+		if strings.Contains(strings.ToLower(name), str) {
+			list = append(list, name)
+		}
+	}
+	return list
+}
+
+// goSection moves to the next section whose name contains str case-insensitively.
+// It wraps around at the end of the document.
+func (root *Root) goSection(str string) {
+	m := root.Doc
+	reg := m.sectionRegexp()
+	if reg == nil {
+		return
+	}
+	lower := strings.ToLower(strings.TrimSpace(str))
+	current := m.topLN + m.Header
+	end := m.BufEndNum()
+	for i := 1; i <= end-m.Header; i++ {
+		n := m.Header + (current-m.Header+i)%(end-m.Header)
+		line := plainLine(m.GetLine(n))
+		if reg.MatchString(line) && strings.Contains(strings.ToLower(line), lower) {
+			root.jumpLine(n)
+			root.setMessage(fmt.Sprintf("Moved to section %s", strings.TrimSpace(line)))
+			return
+		}
+	}
+	root.setMessage(fmt.Sprintf("section not found: %s", str))
+}
+
+// sectionCount is the number of the matches in a section.
+type sectionCount struct {
+	name  string
+	count int
+}
+
+// sectionMatchCounts returns the number of the matches of reg in each section.
+// The lines before the first section are counted as a section without a name.
+// If column is not negative, only the column is matched.
+func sectionMatchCounts(m *Document, reg Searcher, column int) []sectionCount {
+	sReg := m.sectionRegexp()
+	counts := make([]sectionCount, 0)
+	current := -1
+	for n := m.Header; n < m.BufEndNum(); n++ {
+		line := plainLine(m.GetLine(n))
+		if sReg != nil && sReg.MatchString(line) {
+			counts = append(counts, sectionCount{name: strings.TrimSpace(line)})
+			current = len(counts) - 1
+		}
+		if column >= 0 {
+			line = columnString(line, m.ColumnDelimiter, column)
+		}
+		num := len(reg.FindAllStringIndex(line, -1))
+		if num == 0 {
+			continue
+		}
+		if current < 0 {
+			counts = append([]sectionCount{{name: ""}}, counts...)
+			current = 0
+		}
+		counts[current].count += num
+	}
+	return counts
+}
+
+// sectionReport adds a document of the number of the matches of the last search in each section.
+func (root *Root) sectionReport() {
+	reg := root.input.reg
+	if reg == nil {
+		root.setMessage("no search")
+		return
+	}
+	src := root.Doc
+	if src.sectionRegexp() == nil {
+		root.setMessage("no section delimiter")
+		return
+	}
+	counts := sectionMatchCounts(src, reg, root.filterColumn())
+
+	m, err := NewDocument()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	str := strings.TrimPrefix(reg.String(), "(?i)")
+	m.FileName = fmt.Sprintf("sections:%s:%s", str, src.FileName)
+	m.append(fmt.Sprintf("%8s  %s", "matches", "section"))
+	total := 0
+	for _, c := range counts {
+		name := c.name
+		if name == "" {
+			name = "(before the first section)"
+		}
+		m.append(fmt.Sprintf("%8d  %s", c.count, name))
+		total += c.count
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+	m.derived = true
+	root.addDocument(m)
+	m.Header = 1
+	m.WrapMode = false
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("sections:%v %d matches", str, total))
+}
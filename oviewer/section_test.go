@@ -0,0 +1,153 @@
+package oviewer
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDocument_sectionStart(t *testing.T) {
+	lines := []string{"header", "# one", "a", "b", "# two", "c", "d"}
+	tests := []struct {
+		name      string
+		delimiter string
+		header    int
+		lNs       []int
+		want      []int
+	}{
+		{
+			name:      "testNoDelimiter",
+			delimiter: "",
+			lNs:       []int{3},
+			want:      []int{-1},
+		},
+		{
+			name:      "testDown",
+			delimiter: "^#",
+			header:    1,
+			lNs:       []int{0, 1, 2, 3, 4, 6, 10},
+			want:      []int{-1, 1, 1, 1, 4, 4, 4},
+		},
+		{
+			name:      "testUp",
+			delimiter: "^#",
+			header:    1,
+			lNs:       []int{6, 5, 3, 1, 0},
+			want:      []int{4, 4, 1, 1, -1},
+		},
+		{
+			name:      "testHeader",
+			delimiter: "^#",
+			header:    2,
+			lNs:       []int{3, 5},
+			want:      []int{-1, 4},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testLineDocument(t, tt.header, lines...)
+			m.SectionDelimiter = tt.delimiter
+			for i, lN := range tt.lNs {
+				if got := m.sectionStart(lN); got != tt.want[i] {
+					t.Errorf("Document.sectionStart(%d) = %v, want %v", lN, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDocument_sectionRange(t *testing.T) {
+	m := testLineDocument(t, 1, "header", "# one", "a", "b", "# two", "c", "d")
+	m.SectionDelimiter = "^#"
+	tests := []struct {
+		lN        int
+		wantStart int
+		wantEnd   int
+		wantOk    bool
+	}{
+		{lN: 0, wantOk: false},
+		{lN: 1, wantStart: 1, wantEnd: 4, wantOk: true},
+		{lN: 3, wantStart: 1, wantEnd: 4, wantOk: true},
+		{lN: 5, wantStart: 4, wantEnd: 7, wantOk: true},
+	}
+	for _, tt := range tests {
+		start, end, ok := m.sectionRange(tt.lN)
+		if start != tt.wantStart || end != tt.wantEnd || ok != tt.wantOk {
+			t.Errorf("Document.sectionRange(%d) = %v, %v, %v, want %v, %v, %v", tt.lN, start, end, ok, tt.wantStart, tt.wantEnd, tt.wantOk)
+		}
+	}
+}
+
+func TestDocument_sectionNames(t *testing.T) {
+	m := testLineDocument(t, 1, "header", "# One", "a", "\x1b[1m# Two\x1b[0m", "b", "# Three")
+	m.SectionDelimiter = "^#"
+	names := m.sectionNames()
+	want := []string{"# One", "# Two", "# Three"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Document.sectionNames() = %v, want %v", names, want)
+	}
+	if got, want := matchSectionNames(names, "t"), []string{"# Two", "# Three"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("matchSectionNames() = %v, want %v", got, want)
+	}
+	if got := matchSectionNames(names, "four"); len(got) != 0 {
+		t.Errorf("matchSectionNames() = %v, want empty", got)
+	}
+}
+
+func TestRoot_prepareHeaderLine(t *testing.T) {
+	m := testLineDocument(t, 0, "banner", "", "name,value", "a,1", "b,2", "c,3")
+	tests := []struct {
+		name       string
+		header     int
+		headerLine int
+		topLN      int
+		wantStart  int
+		wantLen    int
+	}{
+		{name: "none", headerLine: 0, topLN: 4, wantStart: -1, wantLen: 0},
+		{name: "not scrolled out", headerLine: 3, topLN: 2, wantStart: -1, wantLen: 0},
+		{name: "scrolled out", headerLine: 3, topLN: 3, wantStart: 2, wantLen: 1},
+		{name: "in the header", header: 3, headerLine: 2, topLN: 4, wantStart: -1, wantLen: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.Header = tt.header
+			m.HeaderLine = tt.headerLine
+			root := &Root{Doc: m}
+			root.prepareHeaderLine(tt.topLN)
+			if root.headerLineStart != tt.wantStart || root.headerLineLen != tt.wantLen {
+				t.Errorf("Root.prepareHeaderLine() = %v, %v, want %v, %v", root.headerLineStart, root.headerLineLen, tt.wantStart, tt.wantLen)
+			}
+		})
+	}
+}
+
+func Test_sectionMatchCounts(t *testing.T) {
+	m := testLineDocument(t, 1, "error header", "error", "# One", "error a error", "# Two", "b", "# Three", "a,error")
+	m.SectionDelimiter = "^#"
+	m.ColumnDelimiter = ","
+	reg := regexp.MustCompile("error")
+	tests := []struct {
+		name   string
+		column int
+		want   []sectionCount
+	}{
+		{
+			name:   "all",
+			column: -1,
+			want:   []sectionCount{{"", 1}, {"# One", 2}, {"# Two", 0}, {"# Three", 1}},
+		},
+		{
+			name:   "column",
+			column: 0,
+			want:   []sectionCount{{"", 1}, {"# One", 2}, {"# Two", 0}, {"# Three", 0}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sectionMatchCounts(m, reg, tt.column); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sectionMatchCounts() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
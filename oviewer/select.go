@@ -0,0 +1,52 @@
+package oviewer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// toggleSelectLine selects or deselects the current line and moves down one line.
+// The selected lines are written on exit with SelectOutput.
+func (root *Root) toggleSelectLine() {
+	m := root.Doc
+	lN := m.currentLN()
+	if lN >= m.BufEndNum() {
+		return
+	}
+	if m.selected == nil {
+		m.selected = make(map[int]bool)
+	}
+	if m.selected[lN] {
+		delete(m.selected, lN)
+	} else {
+		m.selected[lN] = true
+	}
+	// The current line moves to the next line.
+	if lN == m.jumpLN && m.topLN == m.jumpTopLN && lN+1 < m.BufEndNum() {
+		root.jumpLine(lN + 1)
+	} else {
+		root.moveDown()
+	}
+	root.setMessage(fmt.Sprintf("Selected %d lines", len(m.selected)))
+}
+
+// selectedLines returns the line numbers of the selected lines in ascending order.
+func (m *Document) selectedLines() []int {
+	lNs := make([]int, 0, len(m.selected))
+	for lN := range m.selected {
+		lNs = append(lNs, lN)
+	}
+	sort.Ints(lNs)
+	return lNs
+}
+
+// writeSelected writes the selected lines of the document to w.
+func (m *Document) writeSelected(w io.Writer) error {
+	for _, lN := range m.selectedLines() {
+		if _, err := fmt.Fprintln(w, m.GetLine(lN)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
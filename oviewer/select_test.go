@@ -0,0 +1,43 @@
+package oviewer
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDocument_writeSelected(t *testing.T) {
+	m := testLineDocument(t, 0, "a", "b", "c", "d")
+	m.selected = map[int]bool{3: true, 1: true}
+	var b bytes.Buffer
+	if err := m.writeSelected(&b); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), "b\nd\n"; got != want {
+		t.Errorf("Document.writeSelected() = %q, want %q", got, want)
+	}
+}
+
+func TestRoot_toggleSelectLine(t *testing.T) {
+	root := testSearchRoot(t)
+	m := root.Doc
+
+	// Without a jump, the top line of the body is selected.
+	root.toggleSelectLine()
+	if !m.selected[0] {
+		t.Errorf("toggleSelectLine() selected = %v, want line 0", m.selectedLines())
+	}
+
+	// After a jump, the line moved to is selected, and the next one after it.
+	m.selected = nil
+	root.ScrollOff = 3
+	root.jumpLine(30)
+	root.toggleSelectLine()
+	root.toggleSelectLine()
+	if got, want := m.selectedLines(), []int{30, 31}; !reflect.DeepEqual(got, want) {
+		t.Errorf("toggleSelectLine() selected = %v, want %v", got, want)
+	}
+	if got := m.currentLN(); got != 32 {
+		t.Errorf("toggleSelectLine() current line = %d, want 32", got)
+	}
+}
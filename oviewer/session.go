@@ -0,0 +1,238 @@
+package oviewer
+
+import (
+	"io"
+	"os"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// termMu serializes the lookups of the terminal type of the sessions,
+// because tcell looks up the terminal type from the environment variable TERM.
+var termMu sync.Mutex
+
+// SessionTty is the terminal of a remote session, such as the channel of an SSH session.
+// The input from the client is read from the channel and the screen is written to it.
+// The size of the window is given by the client (pty-req and window-change requests of SSH),
+// and is notified to the screen by Resize.
+type SessionTty struct {
+	rw io.ReadWriter
+
+	mu     sync.Mutex
+	width  int
+	height int
+	resize func()
+	// drain is closed by Drain to stop waiting for the input.
+	drain chan struct{}
+	// done is closed by Close to stop reading the input.
+	done      chan struct{}
+	closeOnce sync.Once
+
+	once  sync.Once
+	input chan []byte
+	rest  []byte
+	err   error
+}
+
+// NewSessionTty returns the terminal of the session of the window size.
+func NewSessionTty(rw io.ReadWriter, width int, height int) *SessionTty {
+	return &SessionTty{
+		rw:     rw,
+		width:  width,
+		height: height,
+		drain:  make(chan struct{}),
+		done:   make(chan struct{}),
+		input:  make(chan []byte),
+	}
+}
+
+// Start starts reading the input of the session.
+func (t *SessionTty) Start() error {
+	t.mu.Lock()
+	select {
+	case <-t.drain:
+		t.drain = make(chan struct{})
+	default:
+	}
+	t.mu.Unlock()
+	t.once.Do(func() {
+		go t.readLoop()
+	})
+	return nil
+}
+
+// readLoop reads the input of the session until the session is closed.
+// It is separated from Read, so that Drain can stop Read waiting for the input.
+// It does not wait for Read after Close.
+func (t *SessionTty) readLoop() {
+	defer close(t.input)
+	for {
+		buf := make([]byte, 128)
+		n, err := t.rw.Read(buf)
+		if n > 0 {
+			select {
+			case t.input <- buf[:n]:
+			case <-t.done:
+				return
+			}
+		}
+		if err != nil {
+			t.mu.Lock()
+			t.err = err
+			t.mu.Unlock()
+			return
+		}
+	}
+}
+
+// Stop does nothing because the session has no terminal mode to restore.
+func (t *SessionTty) Stop() error {
+	return nil
+}
+
+// Drain stops waiting for the input.
+func (t *SessionTty) Drain() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	select {
+	case <-t.drain:
+	default:
+		close(t.drain)
+	}
+	return nil
+}
+
+// NotifyResize registers the function called when the window is resized.
+func (t *SessionTty) NotifyResize(cb func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resize = cb
+}
+
+// WindowSize returns the size of the window.
+func (t *SessionTty) WindowSize() (int, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.width, t.height, nil
+}
+
+// Resize changes the size of the window and notifies the screen.
+// Call it on the window change of the client.
+func (t *SessionTty) Resize(width int, height int) {
+	t.mu.Lock()
+	t.width = width
+	t.height = height
+	cb := t.resize
+	t.mu.Unlock()
+	if cb != nil {
+		cb()
+	}
+}
+
+// Read reads the input of the session.
+// It returns no input after Drain is called.
+func (t *SessionTty) Read(p []byte) (int, error) {
+	if len(t.rest) == 0 {
+		t.mu.Lock()
+		drain := t.drain
+		t.mu.Unlock()
+		select {
+		case b, ok := <-t.input:
+			if !ok {
+				t.mu.Lock()
+				defer t.mu.Unlock()
+				if t.err != nil {
+					return 0, t.err
+				}
+				return 0, io.EOF
+			}
+			t.rest = b
+		case <-drain:
+			return 0, nil
+		}
+	}
+	n := copy(p, t.rest)
+	t.rest = t.rest[n:]
+	return n, nil
+}
+
+// Write writes the output of the screen to the session.
+func (t *SessionTty) Write(p []byte) (int, error) {
+	return t.rw.Write(p)
+}
+
+// Close stops reading the input, and closes the session if it can be closed.
+func (t *SessionTty) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+	})
+	if c, ok := t.rw.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// NewSessionScreen returns the screen on the terminal of the session.
+// termName is the terminal type requested by the client (TERM),
+// and the terminal type of the environment is used if it is empty.
+//
+// Limitation: tcell takes the terminal type only from the environment,
+// so the TERM environment variable of the process is set to termName
+// while the screen is created, and restored afterwards.
+// The other goroutines of the host program that read TERM at the same time
+// see termName. Pass an empty termName to leave the environment untouched.
+func NewSessionScreen(tty *SessionTty, termName string) (tcell.Screen, error) {
+	termMu.Lock()
+	defer termMu.Unlock()
+	if termName != "" && termName != os.Getenv("TERM") {
+		orig, ok := os.LookupEnv("TERM")
+		os.Setenv("TERM", termName)
+		defer func() {
+			if ok {
+				os.Setenv("TERM", orig)
+			} else {
+				os.Unsetenv("TERM")
+			}
+		}()
+	}
+	return tcell.NewTerminfoScreenFromTty(tty)
+}
+
+// NewOviewerSession returns the structure of oviewer attached to the remote session,
+// for serving the pager over SSH and the like.
+// rw is the channel of the session, termName is the terminal type requested by the client,
+// and width and height are the size of the window.
+// Call Resize of the returned SessionTty when the window of the client is resized.
+// Each session needs its own documents,
+// because the position of the document is held in the document.
+//
+// The returned Root is in the restricted mode (Root.Restricted),
+// where the actions that run commands, read or write files on the host,
+// or suspend the process are not available to the client.
+// Set Restricted to false before Run only if the client is trusted.
+// See NewSessionScreen for the handling of termName.
+//
+//	tty, root, err := oviewer.NewOviewerSession(channel, term, width, height, doc)
+//	if err != nil {
+//		return err
+//	}
+//	go func() {
+//		for win := range windowChanges {
+//			tty.Resize(win.Width, win.Height)
+//		}
+//	}()
+//	return root.Run()
+func NewOviewerSession(rw io.ReadWriter, termName string, width int, height int, docs ...*Document) (*SessionTty, *Root, error) {
+	tty := NewSessionTty(rw, width, height)
+	screen, err := NewSessionScreen(tty, termName)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := NewOviewerScreen(screen, docs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	root.Restricted = true
+	return tty, root, nil
+}
@@ -0,0 +1,198 @@
+package oviewer
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// lockedBuffer is the buffer that the client of the session writes to.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// pollEvent waits for the event that ok returns true.
+func pollEvent(t *testing.T, screen tcell.Screen, ok func(tcell.Event) bool) {
+	t.Helper()
+	found := make(chan struct{})
+	go func() {
+		for {
+			ev := screen.PollEvent()
+			if ev == nil || ok(ev) {
+				close(found)
+				return
+			}
+		}
+	}()
+	select {
+	case <-found:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the event was not received")
+	}
+}
+
+func TestNewOviewerSession(t *testing.T) {
+	server, client := net.Pipe()
+	out := &lockedBuffer{}
+	copied := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(out, client)
+		close(copied)
+	}()
+
+	m := testLineDocument(t, 0, "first line", "second line")
+	tty, root, err := NewOviewerSession(server, "xterm", 20, 4, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !root.Restricted {
+		t.Error("the session is not in the restricted mode")
+	}
+	if w, h := root.Screen.Size(); w != 20 || h != 4 {
+		t.Errorf("Size() = %d, %d, want 20, 4", w, h)
+	}
+
+	tty.Resize(30, 5)
+	pollEvent(t, root.Screen, func(ev tcell.Event) bool {
+		_, ok := ev.(*tcell.EventResize)
+		if !ok {
+			return false
+		}
+		w, h := root.Screen.Size()
+		return w == 30 && h == 5
+	})
+
+	root.prepareView()
+	root.draw()
+
+	// The disconnection of the client is the error of the terminal.
+	client.Close()
+	pollEvent(t, root.Screen, func(ev tcell.Event) bool {
+		_, ok := ev.(*tcell.EventError)
+		return ok
+	})
+	root.Close()
+	<-copied
+	// The spaces may be written as the cursor movements.
+	if !strings.Contains(out.String(), "first") || !strings.Contains(out.String(), "second") {
+		t.Errorf("the screen is not written to the session: %q", out.String())
+	}
+}
+
+func TestSessionTty_Drain(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	tty := NewSessionTty(server, 80, 24)
+	if err := tty.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tty.Drain(); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 8)
+	if n, err := tty.Read(buf); n != 0 || err != nil {
+		t.Errorf("Read() after Drain() = %d, %v, want 0, nil", n, err)
+	}
+
+	// Start again after Drain reads the input.
+	if err := tty.Start(); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		_, _ = client.Write([]byte("q"))
+	}()
+	n, err := tty.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); got != "q" {
+		t.Errorf("Read() = %q, want %q", got, "q")
+	}
+}
+
+// endlessReader is the session that is not a Closer and always has the input.
+type endlessReader struct {
+	io.Writer
+}
+
+func (endlessReader) Read(p []byte) (int, error) {
+	return copy(p, "x"), nil
+}
+
+func TestSessionTty_Close(t *testing.T) {
+	tty := NewSessionTty(endlessReader{io.Discard}, 80, 24)
+	if err := tty.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := tty.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The reading stops after Close, even if the input is not read.
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-tty.input:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("the input is still read after Close()")
+		}
+	}
+}
+
+func TestRoot_restricted(t *testing.T) {
+	m := testLineDocument(t, 0, "line")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Restricted = true
+	keyBind, err := root.setKeyConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for a := range restrictedActions {
+		if keys, ok := keyBind[a]; ok {
+			t.Errorf("%s is bound to %v in the restricted mode", a, keys)
+		}
+	}
+	if _, ok := keyBind[actionMoveDown]; !ok {
+		t.Errorf("%s is not bound in the restricted mode", actionMoveDown)
+	}
+
+	root.prepareView()
+	root.keyCapture(tcell.NewEventKey(tcell.KeyRune, '|', tcell.ModNone))
+	if root.input.mode != Normal {
+		t.Errorf("the pipe key entered the input mode %v", root.input.mode)
+	}
+
+	dir := t.TempDir()
+	root.saveBuffer(dir + "/saved")
+	if _, err := os.Stat(dir + "/saved"); !os.IsNotExist(err) {
+		t.Errorf("saveBuffer() wrote the file in the restricted mode: %v", err)
+	}
+	if root.message != "not allowed in restricted mode" {
+		t.Errorf("message = %q, want the refusal", root.message)
+	}
+}
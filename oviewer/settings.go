@@ -0,0 +1,248 @@
+package oviewer
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// settingItem represents one setting that can be changed at runtime.
+type settingItem struct {
+	// section is the name of the group of the setting.
+	section string
+	// name is the name of the setting.
+	name string
+	// value is the settable value of the setting.
+	value reflect.Value
+}
+
+// String returns the value of the setting as a string.
+func (s settingItem) String() string {
+	if s.value.Kind() == reflect.String {
+		return strconv.Quote(s.value.String())
+	}
+	return fmt.Sprintf("%v", s.value.Interface())
+}
+
+// key returns the key of the setting in the config file.
+func (s settingItem) key() string {
+	if s.section == "Document" {
+		return "General." + s.name
+	}
+	return s.name
+}
+
+// set sets the value of the setting from a string.
+func (s settingItem) set(str string) error {
+	switch s.value.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return err
+		}
+		s.value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(str)
+		if err != nil {
+			return ErrInvalidNumber
+		}
+		s.value.SetInt(int64(n))
+	case reflect.String:
+		if u, err := strconv.Unquote(str); err == nil {
+			str = u
+		}
+		s.value.SetString(str)
+	default:
+		return fmt.Errorf("%s cannot be changed", s.name)
+	}
+	return nil
+}
+
+// targetDoc returns the document that is the target of the settings.
+// It is the current document even if the help or settings screen is displayed.
+func (root *Root) targetDoc() *Document {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	return root.DocList[root.CurrentDoc]
+}
+
+// settingItems returns a list of settings that can be changed at runtime.
+func (root *Root) settingItems() []settingItem {
+	items := make([]settingItem, 0)
+	items = appendSettingItems(items, "Document", "", reflect.ValueOf(&root.targetDoc().general).Elem())
+
+	cv := reflect.ValueOf(&root.Config).Elem()
+	ct := cv.Type()
+	for i := 0; i < ct.NumField(); i++ {
+		name := ct.Field(i).Name
+		v := cv.Field(i)
+		switch {
+		case strings.HasPrefix(name, "Style") && v.Kind() == reflect.Struct:
+			items = appendSettingItems(items, "Style", name+".", v)
+		case strings.HasPrefix(name, "Color"):
+			// Old setting method.
+			continue
+		case isSettingKind(v.Kind()):
+			items = append(items, settingItem{section: "Config", name: name, value: v})
+		}
+	}
+	return items
+}
+
+// appendSettingItems appends the fields of the struct v to items.
+func appendSettingItems(items []settingItem, section string, prefix string, v reflect.Value) []settingItem {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if isSettingKind(v.Field(i).Kind()) {
+			items = append(items, settingItem{section: section, name: prefix + t.Field(i).Name, value: v.Field(i)})
+		}
+	}
+	return items
+}
+
+// isSettingKind returns true if the kind can be changed as a setting.
+func isSettingKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.Int, reflect.String:
+		return true
+	}
+	return false
+}
+
+// settingsString returns the current settings as a string.
+func (root *Root) settingsString() string {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "\n\tSettings\n")
+	section := ""
+	for _, item := range root.settingItems() {
+		if item.section != section {
+			section = item.section
+			fmt.Fprintf(&b, "\n\t%s\n\n", section)
+		}
+		fmt.Fprintf(&b, "  %-36s %s\n", item.name, item)
+	}
+	return b.String()
+}
+
+// newSettingsDoc generates a document for the current settings.
+func (root *Root) newSettingsDoc() (*Document, error) {
+	m, err := NewDocument()
+	if err != nil {
+		return nil, err
+	}
+	m.FileName = "Settings"
+	m.lines = append(m.lines, strings.Split(root.settingsString(), "\n")...)
+	m.eof = 1
+	m.endNum = len(m.lines)
+	return m, nil
+}
+
+// setOption sets the setting from the input in the form of "name=value".
+func (root *Root) setOption(input string) {
+	name, value := input, ""
+	if n := strings.Index(input, "="); n >= 0 {
+		name, value = strings.TrimSpace(input[:n]), strings.TrimSpace(input[n+1:])
+	}
+
+	for _, item := range root.settingItems() {
+		if !strings.EqualFold(item.name, name) {
+			continue
+		}
+		if err := item.set(value); err != nil {
+			root.setMessage(fmt.Sprintf("%s: %s", item.name, err))
+			return
+		}
+		if root.changedSettings == nil {
+			root.changedSettings = make(map[string]bool)
+		}
+		root.changedSettings[item.key()] = true
+		root.applySettings()
+		root.setMessage(fmt.Sprintf("Set %s=%s", item.name, item))
+		return
+	}
+	root.setMessage(fmt.Sprintf("%s setting not found", name))
+}
+
+// applySettings applies the changed settings to the screen.
+func (root *Root) applySettings() {
+	root.setGlobalStyle()
+	if root.Config.DisableMouse {
+		root.Screen.DisableMouse()
+	} else {
+		root.Screen.EnableMouse()
+	}
+
+	m := root.targetDoc()
+	m.ClearCache()
+	if root.screenMode == Settings {
+		if doc, err := root.newSettingsDoc(); err == nil {
+			root.settingsDoc = doc
+			root.setDocument(doc)
+			return
+		}
+	}
+	root.setWrapHeaderLen()
+	root.ViewSync()
+}
+
+// settings is to switch between Settings screen and normal screen.
+func (root *Root) settings() {
+	if root.screenMode == Settings {
+		root.toNormal()
+		return
+	}
+	doc, err := root.newSettingsDoc()
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	root.settingsDoc = doc
+	root.setDocument(doc)
+	root.screenMode = Settings
+}
+
+// SetSaveConfig sets the function to write the settings to the config file.
+// The function receives the settings changed at runtime by their keys in the config file
+// (e.g. "General.WrapMode", "StyleHeader.Foreground"),
+// so that the options only for the run (e.g. QuitOnMatch) are not written.
+func (root *Root) SetSaveConfig(f func(map[string]interface{}) error) {
+	root.saveConfig = f
+}
+
+// writeConfig writes the current settings to the config file.
+func (root *Root) writeConfig() {
+	if root.restricted() {
+		return
+	}
+	if root.saveConfig == nil {
+		root.setMessage("cannot write config")
+		return
+	}
+	settings := make(map[string]interface{})
+	for _, item := range root.settingItems() {
+		if key := item.key(); root.changedSettings[key] {
+			settings[key] = item.value.Interface()
+		}
+	}
+	if len(settings) == 0 {
+		root.setMessage("no settings changed")
+		return
+	}
+	if err := root.saveConfig(settings); err != nil {
+		root.setMessage(fmt.Sprintf("write config: %s", err))
+		return
+	}
+	root.setMessage("Wrote config")
+}
+
+// settingCandidates returns the current settings in the form of "name=value".
+func (root *Root) settingCandidates() []string {
+	items := root.settingItems()
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		list = append(list, fmt.Sprintf("%s=%s", item.name, item))
+	}
+	return list
+}
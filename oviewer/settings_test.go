@@ -0,0 +1,93 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_settingItems(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &Root{
+		Config:  NewConfig(),
+		DocList: []*Document{m},
+	}
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "TabWidth", value: "4", wantErr: false},
+		{name: "TabWidth", value: "x", wantErr: true},
+		{name: "ColumnDelimiter", value: `"\t"`, wantErr: false},
+		{name: "StyleHeader.Bold", value: "false", wantErr: false},
+		{name: "CaseSensitive", value: "true", wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var found bool
+			for _, item := range root.settingItems() {
+				if item.name != tt.name {
+					continue
+				}
+				found = true
+				if err := item.set(tt.value); (err != nil) != tt.wantErr {
+					t.Errorf("settingItem.set() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if !found {
+				t.Errorf("settingItems() does not contain %s", tt.name)
+			}
+		})
+	}
+	if m.TabWidth != 4 {
+		t.Errorf("TabWidth = %d, want 4", m.TabWidth)
+	}
+	if m.ColumnDelimiter != "\t" {
+		t.Errorf("ColumnDelimiter = %q, want %q", m.ColumnDelimiter, "\t")
+	}
+	if root.StyleHeader.Bold {
+		t.Errorf("StyleHeader.Bold = true, want false")
+	}
+	if !root.CaseSensitive {
+		t.Errorf("CaseSensitive = false, want true")
+	}
+}
+
+func TestRoot_writeConfig(t *testing.T) {
+	m := testLineDocument(t, 0, "line")
+	root, err := NewOviewerScreen(tcell.NewSimulationScreen(""), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	root.SetSaveConfig(func(settings map[string]interface{}) error {
+		got = settings
+		return nil
+	})
+	// The options only for the run are not written.
+	root.QuitOnMatch = true
+	root.ExitPattern = "ERROR"
+
+	root.writeConfig()
+	if got != nil {
+		t.Errorf("writeConfig() wrote %v without changes", got)
+	}
+
+	root.setOption("ScrollBar=true")
+	root.setOption("WrapMode=false")
+	root.setOption("StyleHeader.Bold=false")
+	root.writeConfig()
+	want := map[string]interface{}{
+		"ScrollBar":        true,
+		"General.WrapMode": false,
+		"StyleHeader.Bold": false,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("writeConfig() wrote %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,138 @@
+package oviewer
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// shellInput represents the shell command input mode.
+type shellInput struct {
+	value string
+	clist *candidate
+	pathCompletion
+	tcell.EventTime
+}
+
+// newShellInput returns shellInput.
+func newShellInput(clist *candidate) *shellInput {
+	return &shellInput{clist: clist}
+}
+
+// Prompt returns the prompt string in the input field.
+func (s *shellInput) Prompt() string {
+	return "!"
+}
+
+// Confirm returns the event when the input is confirmed.
+func (s *shellInput) Confirm(str string) tcell.Event {
+	s.value = str
+	s.clist.list = toLast(s.clist.list, str)
+	s.clist.p = 0
+	s.SetEventNow()
+	return s
+}
+
+// Up returns strings when the up key is pressed during input.
+func (s *shellInput) Up(str string) string {
+	return s.clist.match(str).up()
+}
+
+// Down returns strings when the down key is pressed during input.
+func (s *shellInput) Down(str string) string {
+	return s.clist.match(str).down()
+}
+
+// Complete completes the last word of the command as a file path.
+func (s *shellInput) Complete(str string) (string, string) {
+	i := strings.LastIndexByte(str, ' ') + 1
+	word, hint := s.pathCompletion.Complete(str[i:])
+	return str[:i] + word, hint
+}
+
+// setShellMode sets the shell command input mode.
+func (root *Root) setShellMode() {
+	input := root.input
+	input.value = ""
+	input.cursorX = 0
+	input.mode = Shell
+	input.EventInput = newShellInput(input.ShellCandidate)
+}
+
+// shellCommand runs the command in the shell with the screen suspended,
+// and returns to the screen after the enter key is pressed.
+// An empty command runs the interactive shell.
+func (root *Root) shellCommand(cmd string) {
+	if root.restricted() {
+		return
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "sh"
+	}
+	command := exec.Command(shell)
+	if cmd != "" {
+		command = exec.Command(shell, "-c", cmd)
+	}
+
+	tty, err := openTTY()
+	if err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	defer tty.Close()
+	command.Stdin = tty
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	if err := root.Screen.Suspend(); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	if err := command.Run(); err != nil {
+		log.Printf("shell: %s: %v", cmd, err)
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if cmd != "" {
+		fmt.Fprint(os.Stderr, "[Press Enter to return]")
+		if _, err := bufio.NewReader(tty).ReadString('\n'); err != nil {
+			log.Println(err)
+		}
+	}
+	root.resume()
+	root.setMessage(fmt.Sprintf("!%s", cmd))
+}
+
+// suspend stops the process with the screen suspended like the shell's job control,
+// and restores the screen when the process is continued.
+func (root *Root) suspend() {
+	if root.restricted() {
+		return
+	}
+	if err := root.Screen.Suspend(); err != nil {
+		root.setMessage(err.Error())
+		return
+	}
+	if err := suspendProcess(); err != nil {
+		log.Printf("suspend: %v", err)
+	}
+	root.resume()
+}
+
+// resume restores the screen after Suspend.
+func (root *Root) resume() {
+	if err := root.Screen.Resume(); err != nil {
+		log.Printf("resume: %v", err)
+		return
+	}
+	if !root.Config.DisableMouse {
+		root.Screen.EnableMouse()
+	}
+	root.Screen.EnablePaste()
+	root.Screen.Sync()
+}
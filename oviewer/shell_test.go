@@ -0,0 +1,22 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_shellInput_Confirm(t *testing.T) {
+	clist := &candidate{list: []string{"ls", "make"}}
+	s := newShellInput(clist)
+	ev := s.Confirm("ls")
+	got, ok := ev.(*shellInput)
+	if !ok {
+		t.Fatalf("shellInput.Confirm() = %T, want *shellInput", ev)
+	}
+	if got.value != "ls" {
+		t.Errorf("shellInput.Confirm() value = %v, want %v", got.value, "ls")
+	}
+	if want := []string{"make", "ls"}; !reflect.DeepEqual(clist.list, want) {
+		t.Errorf("shellInput.Confirm() candidates = %v, want %v", clist.list, want)
+	}
+}
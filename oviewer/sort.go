@@ -0,0 +1,228 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// sortType represents how to compare the values in sorting.
+type sortType int
+
+const (
+	// sortAuto detects the type from the values.
+	sortAuto sortType = iota
+	// sortString compares as strings.
+	sortString
+	// sortNumber compares as numbers.
+	sortNumber
+	// sortSize compares as human-readable sizes (10K, 2.3G).
+	sortSize
+	// sortTime compares as timestamps.
+	sortTime
+)
+
+func (t sortType) String() string {
+	switch t {
+	case sortString:
+		return "string"
+	case sortNumber:
+		return "number"
+	case sortSize:
+		return "size"
+	case sortTime:
+		return "time"
+	}
+	return "auto"
+}
+
+// sortTimeLayouts is a list of the timestamp layouts to sort as time.
+var sortTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"02/Jan/2006:15:04:05 -0700",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.UnixDate,
+	time.Stamp,
+}
+
+// sizePattern is a regular expression of human-readable sizes.
+var sizePattern = regexp.MustCompile(`^(?i)([0-9]+(?:\.[0-9]+)?)\s*([KMGTPE]?)(?:I?B)?$`)
+
+// parseNumber returns the value of the number string.
+func parseNumber(s string) (float64, bool) {
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, false
+	}
+	return v, true
+}
+
+// parseSize returns the number of bytes of the human-readable size string.
+func parseSize(s string) (float64, bool) {
+	m := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	exp := 0
+	if m[2] != "" {
+		exp = strings.Index("KMGTPE", strings.ToUpper(m[2])) + 1
+	}
+	return v * math.Pow(1024, float64(exp)), true
+}
+
+// parseTime returns the unix nano time of the timestamp string.
+func parseTime(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range sortTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return float64(t.UnixNano()), true
+		}
+	}
+	return 0, false
+}
+
+// sortParser returns the function to convert the value of the sort type.
+func sortParser(t sortType) func(string) (float64, bool) {
+	switch t {
+	case sortNumber:
+		return parseNumber
+	case sortSize:
+		return parseSize
+	case sortTime:
+		return parseTime
+	}
+	return nil
+}
+
+// detectSortType returns the sort type that can parse all non-empty values.
+func detectSortType(values []string) sortType {
+	for _, t := range []sortType{sortNumber, sortSize, sortTime} {
+		parse := sortParser(t)
+		ok := false
+		for _, v := range values {
+			if strings.TrimSpace(v) == "" {
+				continue
+			}
+			if _, ok = parse(v); !ok {
+				break
+			}
+		}
+		if ok {
+			return t
+		}
+	}
+	return sortString
+}
+
+// sortLines sorts the lines by the values of keys.
+// The values that cannot be parsed are sorted to the end.
+func sortLines(lines []string, keys []string, t sortType) sortType {
+	if t == sortAuto {
+		t = detectSortType(keys)
+	}
+
+	idx := make([]int, len(lines))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	parse := sortParser(t)
+	if parse == nil {
+		sort.SliceStable(idx, func(i, j int) bool {
+			return keys[idx[i]] < keys[idx[j]]
+		})
+	} else {
+		values := make([]float64, len(keys))
+		valid := make([]bool, len(keys))
+		for i, k := range keys {
+			values[i], valid[i] = parse(k)
+		}
+		sort.SliceStable(idx, func(i, j int) bool {
+			a, b := idx[i], idx[j]
+			if valid[a] != valid[b] {
+				return valid[a]
+			}
+			return values[a] < values[b]
+		})
+	}
+
+	sorted := make([]string, len(lines))
+	for i, n := range idx {
+		sorted[i] = lines[n]
+	}
+	copy(lines, sorted)
+	return t
+}
+
+// sortDocument adds a document sorted by the selected column (the whole line if not column mode).
+func (root *Root) sortDocument() {
+	src := root.Doc
+	column := -1
+	if src.ColumnMode {
+		column = src.columnNum
+	}
+
+	end := src.BufEndNum()
+	header := min(src.Header, end)
+	lines := make([]string, 0, end-header)
+	keys := make([]string, 0, end-header)
+	for n := header; n < end; n++ {
+		line := src.GetLine(n)
+		key := line
+		if strings.ContainsAny(key, "\x1b\b") {
+			key = stripEscapeSequence.ReplaceAllString(key, "")
+		}
+		if column >= 0 {
+			key = columnString(key, src.ColumnDelimiter, column)
+		}
+		lines = append(lines, line)
+		keys = append(keys, key)
+	}
+	t := sortLines(lines, keys, root.sortType)
+
+	m, err := NewDocument()
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	for n := 0; n < header; n++ {
+		m.append(src.GetLine(n))
+	}
+	for _, line := range lines {
+		m.append(line)
+	}
+	close(m.eofCh)
+	atomic.StoreInt32(&m.eof, 1)
+
+	target := "line"
+	if column >= 0 {
+		target = fmt.Sprintf("column %d", column)
+	}
+	m.FileName = fmt.Sprintf("sort:%s:%s", target, src.FileName)
+	m.derived = true
+	root.addDocument(m)
+	m.general = src.general
+	root.ViewSync()
+	root.setMessage(fmt.Sprintf("Sorted by %s (%s)", target, t))
+}
+
+// cycleSortType changes the comparison type of the sort.
+func (root *Root) cycleSortType() {
+	root.sortType = (root.sortType + 1) % (sortTime + 1)
+	root.setMessage(fmt.Sprintf("Set sort type %s", root.sortType))
+}
@@ -0,0 +1,101 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		s      string
+		want   float64
+		wantOk bool
+	}{
+		{name: "bytes", s: "512", want: 512, wantOk: true},
+		{name: "kilo", s: "10K", want: 10 * 1024, wantOk: true},
+		{name: "giga", s: "2.5G", want: 2.5 * 1024 * 1024 * 1024, wantOk: true},
+		{name: "unit", s: "1 MiB", want: 1024 * 1024, wantOk: true},
+		{name: "lower", s: "3kb", want: 3 * 1024, wantOk: true},
+		{name: "invalid", s: "10X", want: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := parseSize(tt.s)
+			if got != tt.want || gotOk != tt.wantOk {
+				t.Errorf("parseSize() = %v, %v, want %v, %v", got, gotOk, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_detectSortType(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   sortType
+	}{
+		{name: "number", values: []string{"10", "9", "", "-1.5"}, want: sortNumber},
+		{name: "size", values: []string{"10K", "900", "1.2G"}, want: sortSize},
+		{name: "time", values: []string{"2021-10-01", "2021-09-30"}, want: sortTime},
+		{name: "string", values: []string{"10", "abc"}, want: sortString},
+		{name: "empty", values: []string{""}, want: sortString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectSortType(tt.values); got != tt.want {
+				t.Errorf("detectSortType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_sortLines(t *testing.T) {
+	type args struct {
+		keys []string
+		t    sortType
+	}
+	tests := []struct {
+		name     string
+		args     args
+		want     []string
+		wantType sortType
+	}{
+		{
+			name:     "number",
+			args:     args{keys: []string{"10", "9", "100"}, t: sortAuto},
+			want:     []string{"9", "10", "100"},
+			wantType: sortNumber,
+		},
+		{
+			name:     "size",
+			args:     args{keys: []string{"1G", "10K", "2M"}, t: sortAuto},
+			want:     []string{"10K", "2M", "1G"},
+			wantType: sortSize,
+		},
+		{
+			name:     "string",
+			args:     args{keys: []string{"10", "9", "100"}, t: sortString},
+			want:     []string{"10", "100", "9"},
+			wantType: sortString,
+		},
+		{
+			name:     "invalidLast",
+			args:     args{keys: []string{"x", "2", "1"}, t: sortNumber},
+			want:     []string{"1", "2", "x"},
+			wantType: sortNumber,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := make([]string, len(tt.args.keys))
+			copy(lines, tt.args.keys)
+			if got := sortLines(lines, tt.args.keys, tt.args.t); got != tt.wantType {
+				t.Errorf("sortLines() type = %v, want %v", got, tt.wantType)
+			}
+			if !reflect.DeepEqual(lines, tt.want) {
+				t.Errorf("sortLines() = %v, want %v", lines, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package oviewer
+
+// unusualSpaces is the whitespace characters other than the space and the tab,
+// which are invisible or look like the space but break the column alignment.
+var unusualSpaces = map[rune]bool{
+	'\u00A0': true, // no-break space
+	'\u1680': true, // ogham space mark
+	'\u180E': true, // mongolian vowel separator
+	'\u2000': true, // en quad
+	'\u2001': true, // em quad
+	'\u2002': true, // en space
+	'\u2003': true, // em space
+	'\u2004': true, // three-per-em space
+	'\u2005': true, // four-per-em space
+	'\u2006': true, // six-per-em space
+	'\u2007': true, // figure space
+	'\u2008': true, // punctuation space
+	'\u2009': true, // thin space
+	'\u200A': true, // hair space
+	'\u200B': true, // zero width space
+	'\u2028': true, // line separator
+	'\u2029': true, // paragraph separator
+	'\u202F': true, // narrow no-break space
+	'\u205F': true, // medium mathematical space
+	'\u2060': true, // word joiner
+	'\u3000': true, // ideographic (full-width) space
+	'\uFEFF': true, // zero width no-break space (BOM)
+}
+
+// hasUnusualSpace returns true if the content is or has an unusual space.
+// The zero width characters are combined with the previous character.
+func (c content) hasUnusualSpace() bool {
+	if unusualSpaces[c.mainc] {
+		return true
+	}
+	for _, r := range c.combc {
+		if unusualSpaces[r] {
+			return true
+		}
+	}
+	return false
+}
+
+// unusualSpaceStyle applies StyleUnusualSpace to the unusual spaces of the line.
+func (root *Root) unusualSpaceStyle(lc lineContents) {
+	for x := 0; x < len(lc); x++ {
+		if lc[x].hasUnusualSpace() {
+			RangeStyle(lc, x, min(x+max(lc[x].width, 1), len(lc)), root.StyleUnusualSpace)
+		}
+	}
+}
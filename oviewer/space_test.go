@@ -0,0 +1,40 @@
+package oviewer
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRoot_unusualSpaceStyle(t *testing.T) {
+	root := &Root{
+		Config: Config{
+			StyleUnusualSpace: ovStyle{Background: "red"},
+		},
+	}
+	tests := []struct {
+		name string
+		str  string
+		want []bool
+	}{
+		{name: "space", str: "a b", want: []bool{false, false, false}},
+		{name: "no-break space", str: "a\u00a0b", want: []bool{false, true, false}},
+		{name: "full-width space", str: "a\u3000b", want: []bool{false, true, true, false}},
+		{name: "tab", str: "a\tb", want: []bool{false, false, false, false, false, false, false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lc := strToContents(tt.str, 8)
+			root.unusualSpaceStyle(lc)
+			if len(lc) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(lc), len(tt.want))
+			}
+			for x, want := range tt.want {
+				_, bg, _ := lc[x].style.Decompose()
+				if got := bg == tcell.ColorRed; got != want {
+					t.Errorf("unusualSpaceStyle() x=%d styled = %v, want %v", x, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+//go:build !windows
+// +build !windows
+
+package oviewer
+
+import (
+	"os"
+	"syscall"
+)
+
+// suspendProcess stops the process until it receives SIGCONT.
+func suspendProcess() error {
+	return syscall.Kill(syscall.Getpid(), syscall.SIGTSTP)
+}
+
+// openTTY opens the terminal for the input of the shell command,
+// because the standard input may be the input of the document.
+func openTTY() (*os.File, error) {
+	return os.Open("/dev/tty")
+}
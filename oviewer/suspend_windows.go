@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package oviewer
+
+import (
+	"os"
+)
+
+// suspendProcess returns ErrNotSupported because Windows has no job control.
+func suspendProcess() error {
+	return ErrNotSupported
+}
+
+// openTTY opens the console for the input of the shell command,
+// because the standard input may be the input of the document.
+func openTTY() (*os.File, error) {
+	return os.Open("CONIN$")
+}
@@ -0,0 +1,87 @@
+package oviewer
+
+import (
+	"log"
+	"strings"
+	"sync/atomic"
+)
+
+// tableDetectLines is the number of the lines to read before detecting the table.
+const tableDetectLines = 5
+
+// tableBorderChars is the characters that make up the border lines of the tables.
+const tableBorderChars = "-+=|─━┼┬┴├┤┌┐└┘│═╪╤╧╞╡╔╗╚╝║╬╦╩╠╣ "
+
+// isTableBorder returns true if the line is a border line of the table,
+// such as "----+----", "+----+----+" or "├────┼────┤".
+func isTableBorder(line string) bool {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return false
+	}
+	if !strings.ContainsAny(line, "-─━=═") {
+		return false
+	}
+	return strings.Trim(line, tableBorderChars) == ""
+}
+
+// detectTable detects the bordered table output of psql or mysql in the first lines,
+// and returns the number of header lines and the column delimiter.
+func detectTable(lines []string) (int, string, bool) {
+	start := 0
+	if len(lines) > 0 && isTableBorder(lines[0]) {
+		// mysql or box-drawing table with the top border.
+		start = 1
+	}
+	for n := start; n < len(lines); n++ {
+		if !isTableBorder(lines[n]) {
+			continue
+		}
+		if n == start {
+			return 0, "", false
+		}
+		title := lines[n-1]
+		delimiter := "|"
+		if strings.Contains(title, "│") {
+			delimiter = "│"
+		} else if strings.Contains(title, "║") {
+			delimiter = "║"
+		}
+		if !strings.Contains(title, delimiter) {
+			return 0, "", false
+		}
+		return n + 1, delimiter, true
+	}
+	return 0, "", false
+}
+
+// autoTable sets the header and the column mode of the current document
+// if it starts with the bordered table.
+// It is checked only once for each document.
+func (root *Root) autoTable() {
+	m := root.Doc
+	if m.tableChecked || root.screenMode != Docs {
+		return
+	}
+	end := m.BufEndNum()
+	if end < tableDetectLines && atomic.LoadInt32(&m.eof) == 0 {
+		return
+	}
+	m.tableChecked = true
+
+	lines := make([]string, 0, tableDetectLines)
+	for n := 0; n < min(end, tableDetectLines); n++ {
+		lines = append(lines, plainLine(m.GetLine(n)))
+	}
+	header, delimiter, ok := detectTable(lines)
+	if !ok {
+		return
+	}
+	log.Printf("%s: table header %d delimiter %s", m.FileName, header, delimiter)
+	m.Header = header
+	m.ColumnDelimiter = delimiter
+	m.ColumnMode = true
+	m.WrapMode = false
+	root.setWrapHeaderLen()
+	m.ClearCache()
+}
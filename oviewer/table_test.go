@@ -0,0 +1,85 @@
+package oviewer
+
+import "testing"
+
+func Test_detectTable(t *testing.T) {
+	tests := []struct {
+		name          string
+		lines         []string
+		wantHeader    int
+		wantDelimiter string
+		wantOK        bool
+	}{
+		{
+			name: "psql",
+			lines: []string{
+				" id | name",
+				"----+------",
+				"  1 | a",
+			},
+			wantHeader:    2,
+			wantDelimiter: "|",
+			wantOK:        true,
+		},
+		{
+			name: "mysql",
+			lines: []string{
+				"+----+------+",
+				"| id | name |",
+				"+----+------+",
+				"|  1 | a    |",
+			},
+			wantHeader:    3,
+			wantDelimiter: "|",
+			wantOK:        true,
+		},
+		{
+			name: "psqlUnicode",
+			lines: []string{
+				"┌────┬──────┐",
+				"│ id │ name │",
+				"├────┼──────┤",
+				"│  1 │ a    │",
+			},
+			wantHeader:    3,
+			wantDelimiter: "│",
+			wantOK:        true,
+		},
+		{
+			name: "underline",
+			lines: []string{
+				"Title",
+				"-----",
+				"text",
+			},
+			wantOK: false,
+		},
+		{
+			name: "text",
+			lines: []string{
+				"a | b",
+				"c | d",
+			},
+			wantOK: false,
+		},
+		{
+			name: "borderOnly",
+			lines: []string{
+				"+----+",
+				"+----+",
+			},
+			wantOK: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, delimiter, ok := detectTable(tt.lines)
+			if ok != tt.wantOK {
+				t.Fatalf("detectTable() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if header != tt.wantHeader || delimiter != tt.wantDelimiter {
+				t.Errorf("detectTable() = %v, %v, want %v, %v", header, delimiter, tt.wantHeader, tt.wantDelimiter)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// trimLines drops n lines after the header lines from the buffer,
+// and returns the number of the dropped lines.
+// The line numbers of the document are shifted by the dropped lines.
+func (m *Document) trimLines(n int) int {
+	if m.diffOps != nil || m.mergeSource != nil || m.jsonSourceLN != nil {
+		return 0
+	}
+	m.mu.Lock()
+	start := min(m.Header, m.endNum)
+	n = max(0, min(n, m.endNum-start))
+	if n == 0 {
+		m.mu.Unlock()
+		return 0
+	}
+	lines := make([]string, 0, m.endNum-n)
+	lines = append(lines, m.lines[:start]...)
+	for _, line := range m.lines[start : start+n] {
+		m.size -= int64(len(line))
+	}
+	lines = append(lines, m.lines[start+n:m.endNum]...)
+	m.lines = lines
+	m.endNum -= n
+
+	// The segments that continue to the next line are not counted in the line numbers.
+	var continued []int
+	dropped := 0
+	for _, lN := range m.continued {
+		if l, ok := trimmedLN(lN, start, n); ok {
+			continued = append(continued, l)
+			continue
+		}
+		dropped++
+	}
+	if m.continued != nil {
+		m.continued = continued
+	}
+	m.trimmed += n - dropped
+	if m.arrival != nil {
+		arrival := make(map[int]time.Time, len(m.arrival))
+		for lN, t := range m.arrival {
+			if l, ok := trimmedLN(lN, start, n); ok {
+				arrival[l] = t
+			}
+		}
+		m.arrival = arrival
+	}
+	if m.watchChanged != nil {
+		changed := make(map[int]string, len(m.watchChanged))
+		for lN, prev := range m.watchChanged {
+			if l, ok := trimmedLN(lN, start, n); ok {
+				changed[l] = prev
+			}
+		}
+		m.watchChanged = changed
+	}
+	if m.concatFiles != nil {
+		m.concatFiles = trimConcatFiles(m.concatFiles, start, n)
+	}
+	m.mu.Unlock()
+
+	if m.selected != nil {
+		selected := make(map[int]bool, len(m.selected))
+		for lN := range m.selected {
+			if l, ok := trimmedLN(lN, start, n); ok {
+				selected[l] = true
+			}
+		}
+		m.selected = selected
+	}
+	m.topLN = max(0, m.topLN-n)
+	m.jumpLN = -1
+	m.latestNum = max(0, m.latestNum-n)
+	m.resetSectionCache()
+	m.ClearCache()
+	return n
+}
+
+// ringBufferSlack is the fraction of MaxLines that can be exceeded before the oldest lines are dropped,
+// so that the buffer is not copied for every line.
+const ringBufferSlack = 8
+
+// ringBuffer drops the oldest lines of the documents that have more than MaxLines lines.
+func (root *Root) ringBuffer() {
+	root.mu.RLock()
+	defer root.mu.RUnlock()
+	for _, doc := range root.DocList {
+		if doc.MaxLines <= 0 {
+			continue
+		}
+		over := doc.BufEndNum() - doc.Header - doc.MaxLines
+		if over > doc.MaxLines/ringBufferSlack {
+			doc.trimLines(over)
+		}
+	}
+}
+
+// lineNumber returns the line number displayed for the line,
+// which is the position in the stream including the dropped lines.
+func (m *Document) lineNumber(lN int) int {
+	if lN < m.Header {
+		return lN - m.Header + 1
+	}
+	return m.streamLN(lN) - m.Header + 1
+}
+
+// bufferLN returns the line of the buffer for the line lN counted in the stream
+// including the dropped lines, and false if the line has been dropped.
+// The line of a long line split into segments is its first segment.
+func (m *Document) bufferLN(lN int) (int, bool) {
+	switch {
+	case lN < m.Header:
+		return lN, true
+	case lN < m.Header+m.trimmed:
+		return 0, false
+	}
+	lN -= m.trimmed
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.continued) == 0 {
+		return lN, true
+	}
+	end := m.endNum
+	if lN >= end-len(m.continued) {
+		return lN + len(m.continued), true
+	}
+	return sort.Search(end, func(n int) bool {
+		return n-sort.SearchInts(m.continued, n) >= lN
+	}), true
+}
+
+// streamLN returns the line counted in the stream including the dropped lines
+// for the line lN of the buffer.
+// The segments of a long line are counted as one line.
+func (m *Document) streamLN(lN int) int {
+	if lN < m.Header {
+		return lN
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return lN - sort.SearchInts(m.continued, lN) + m.trimmed
+}
+
+// trimmedLN returns the line number after n lines from start are dropped,
+// and false if the line is dropped.
+func trimmedLN(lN int, start int, n int) (int, bool) {
+	switch {
+	case lN < start:
+		return lN, true
+	case lN < start+n:
+		return 0, false
+	}
+	return lN - n, true
+}
+
+// bufferSize returns the size in bytes of the lines in the buffer.
+func (m *Document) bufferSize() int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.size
+}
+
+// humanSize returns the size in bytes in a human-readable form.
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%dB", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit && exp < 3; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(size)/float64(div), "KMGT"[exp])
+}
+
+// trimBuffer drops the lines of the count prefix,
+// or the lines above the current view from the buffer of the current document.
+func (root *Root) trimBuffer() {
+	m := root.Doc
+	n := m.topLN
+	if root.count > 0 {
+		n = root.takeCount()
+	}
+	before := m.bufferSize()
+	dropped := m.trimLines(n)
+	if dropped == 0 {
+		root.setMessage("No lines to trim")
+		return
+	}
+	log.Printf("%s: trim %d lines", m.FileName, dropped)
+	root.setMessage(fmt.Sprintf("Trimmed %d lines (%s -> %s)", dropped, humanSize(before), humanSize(m.bufferSize())))
+}
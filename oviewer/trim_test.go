@@ -0,0 +1,224 @@
+package oviewer
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestDocument_trimLines(t *testing.T) {
+	tests := []struct {
+		name         string
+		header       int
+		n            int
+		want         int
+		wantLines    []string
+		wantSelected map[int]bool
+	}{
+		{
+			name:         "two",
+			header:       0,
+			n:            2,
+			want:         2,
+			wantLines:    []string{"2", "3", "4"},
+			wantSelected: map[int]bool{1: true},
+		},
+		{
+			name:         "header",
+			header:       1,
+			n:            2,
+			want:         2,
+			wantLines:    []string{"0", "3", "4"},
+			wantSelected: map[int]bool{0: true, 1: true},
+		},
+		{
+			name:         "over",
+			header:       0,
+			n:            10,
+			want:         5,
+			wantLines:    []string{},
+			wantSelected: map[int]bool{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewDocument()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := m.ReadAll(strings.NewReader("0\n1\n2\n3\n4\n")); err != nil {
+				t.Fatal(err)
+			}
+			<-m.eofCh
+			m.Header = tt.header
+			m.selected = map[int]bool{0: true, 1: true, 3: true}
+			m.beginLines()
+			lc, err := m.lineToContents(1, 8)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m.contentsStr(1, lc)
+			if got := m.trimLines(tt.n); got != tt.want {
+				t.Errorf("Document.trimLines() = %v, want %v", got, tt.want)
+			}
+			lines := []string{}
+			for n := 0; n < m.BufEndNum(); n++ {
+				lines = append(lines, m.GetLine(n))
+			}
+			if !reflect.DeepEqual(lines, tt.wantLines) {
+				t.Errorf("Document.trimLines() lines = %v, want %v", lines, tt.wantLines)
+			}
+			if !reflect.DeepEqual(m.selected, tt.wantSelected) {
+				t.Errorf("Document.trimLines() selected = %v, want %v", m.selected, tt.wantSelected)
+			}
+			if got, want := m.bufferSize(), int64(len(strings.Join(lines, ""))); got != want {
+				t.Errorf("Document.bufferSize() = %v, want %v", got, want)
+			}
+			// The converted string of the line that was at the number is not reused.
+			m.beginLines()
+			if l, ok := m.drawLines[1]; ok && !l.dirty {
+				t.Errorf("Document.trimLines() the line cache is not dirty")
+			}
+		})
+	}
+}
+
+func TestDocument_trimLinesConcat(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a: 0-3, b: 4-6, c: 7-9
+	m.concatFiles = []concatFile{{lN: 0, name: "a"}, {lN: 4, name: "b"}, {lN: 7, name: "c"}}
+	for n := 0; n < 10; n++ {
+		m.append(strconv.Itoa(n))
+	}
+	// The lines of a and the separator of b are dropped.
+	if got := m.trimLines(5); got != 5 {
+		t.Fatalf("Document.trimLines() = %v, want 5", got)
+	}
+	want := []concatFile{{lN: 0, name: "b", trimmed: true}, {lN: 2, name: "c"}}
+	if !reflect.DeepEqual(m.concatFiles, want) {
+		t.Errorf("Document.trimLines() concatFiles = %v, want %v", m.concatFiles, want)
+	}
+	if got := m.concatFileName(1); got != "b" {
+		t.Errorf("Document.concatFileName(1) = %q, want %q", got, "b")
+	}
+	if m.isConcatSeparator(0) {
+		t.Error("the first line left is the separator")
+	}
+	if !m.isConcatSeparator(2) {
+		t.Error("the separator of c is not the separator")
+	}
+}
+
+func TestDocument_lineNumberSegment(t *testing.T) {
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.segmentSize = 4
+	if err := m.readAll(bufio.NewReaderSize(strings.NewReader("a\nbbbbbbbbbb\nc\nd\n"), 16)); !errors.Is(err, io.EOF) {
+		t.Fatal(err)
+	}
+	// a, bbbb, bbbb, bb, c, d
+	wantNumbers := []int{1, 2, 2, 2, 3, 4}
+	for lN, want := range wantNumbers {
+		if got := m.lineNumber(lN); got != want {
+			t.Errorf("Document.lineNumber(%d) = %v, want %v", lN, got, want)
+		}
+	}
+	for lN, want := range []int{0, 1, 4, 5} {
+		if got, ok := m.bufferLN(lN); !ok || got != want {
+			t.Errorf("Document.bufferLN(%d) = %v, %v, want %v", lN, got, ok, want)
+		}
+	}
+
+	// The segments dropped are not counted.
+	if got := m.trimLines(2); got != 2 {
+		t.Fatalf("Document.trimLines() = %v, want 2", got)
+	}
+	// bbbb, bb, c, d
+	for lN, want := range []int{2, 2, 3, 4} {
+		if got := m.lineNumber(lN); got != want {
+			t.Errorf("Document.lineNumber(%d) after trim = %v, want %v", lN, got, want)
+		}
+	}
+	if got, ok := m.bufferLN(2); !ok || got != 2 {
+		t.Errorf("Document.bufferLN(2) after trim = %v, %v, want 2", got, ok)
+	}
+}
+
+func Test_humanSize(t *testing.T) {
+	tests := []struct {
+		size int64
+		want string
+	}{
+		{size: 100, want: "100B"},
+		{size: 2048, want: "2.0KB"},
+		{size: 1536 * 1024, want: "1.5MB"},
+		{size: 3 << 30, want: "3.0GB"},
+	}
+	for _, tt := range tests {
+		if got := humanSize(tt.size); got != tt.want {
+			t.Errorf("humanSize(%d) = %v, want %v", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestRoot_ringBuffer(t *testing.T) {
+	tcellNewScreen = fakeScreen
+	defer func() {
+		tcellNewScreen = tcell.NewScreen
+	}()
+	m, err := NewDocument()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 0; n < 100; n++ {
+		m.append(strconv.Itoa(n))
+	}
+	root, err := NewOviewer(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.MaxLines = 16
+	root.ringBuffer()
+	if got := m.BufEndNum(); got != 16 {
+		t.Errorf("Root.ringBuffer() lines = %v, want %v", got, 16)
+	}
+	if got := m.GetLine(0); got != "84" {
+		t.Errorf("Root.ringBuffer() first line = %v, want %v", got, "84")
+	}
+	if got := m.lineNumber(0); got != 85 {
+		t.Errorf("Document.lineNumber() = %v, want %v", got, 85)
+	}
+
+	// Goto and mark use the line numbers in the stream.
+	root.prepareView()
+	root.goLine("90")
+	if m.jumpLN != 5 || m.GetLine(m.jumpLN) != "89" {
+		t.Errorf("Root.goLine(90) = %d, want the line %q", m.jumpLN, "89")
+	}
+	root.markLineNum()
+	if got := root.input.GoCandidate.list[len(root.input.GoCandidate.list)-1]; got != strconv.Itoa(m.topLN+85) {
+		t.Errorf("Root.markLineNum() = %v, want %v", got, m.topLN+85)
+	}
+	root.goLine("10")
+	if root.message != "line 10 dropped" {
+		t.Errorf("Root.goLine(10) message = %q, want %q", root.message, "line 10 dropped")
+	}
+
+	// Within the slack.
+	m.append("100")
+	root.ringBuffer()
+	if got := m.BufEndNum(); got != 17 {
+		t.Errorf("Root.ringBuffer() lines = %v, want %v", got, 17)
+	}
+}
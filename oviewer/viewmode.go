@@ -0,0 +1,233 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// ViewModeRule represents a rule that selects the view mode when a document is opened.
+type ViewModeRule struct {
+	// Pattern is a file name pattern (e.g. "*.csv").
+	// It matches the base name, or the full path if it contains a path separator.
+	Pattern string
+	// MIME is a prefix of the MIME type determined by the extension (e.g. "text/csv").
+	MIME string
+	// Mode is the name of the view mode to apply.
+	Mode string
+}
+
+// match returns true if the file name matches the rule.
+func (r ViewModeRule) match(fileName string) bool {
+	if r.Pattern != "" {
+		name := filepath.Base(fileName)
+		if strings.ContainsRune(r.Pattern, filepath.Separator) {
+			name = fileName
+		}
+		if ok, err := filepath.Match(r.Pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	if r.MIME != "" {
+		mimeType := mimeTypeByExtension(filepath.Ext(fileName))
+		if mimeType != "" && strings.HasPrefix(mimeType, r.MIME) {
+			return true
+		}
+	}
+	return false
+}
+
+// textMIMETypes is the MIME types of the text files that are not in the built-in table
+// of the mime package, used when the system has no MIME type files.
+var textMIMETypes = map[string]string{
+	".tsv":   "text/tab-separated-values",
+	".md":    "text/markdown",
+	".log":   "text/plain",
+	".diff":  "text/x-diff",
+	".patch": "text/x-diff",
+	".yaml":  "application/yaml",
+	".yml":   "application/yaml",
+}
+
+// mimeTypeByExtension returns the MIME type of the extension.
+func mimeTypeByExtension(ext string) string {
+	if mimeType := mime.TypeByExtension(ext); mimeType != "" {
+		return mimeType
+	}
+	return textMIMETypes[strings.ToLower(ext)]
+}
+
+// ModeConfig represents a view mode in the config.
+// Only the specified fields are applied, so unspecified fields are
+// inherited from the mode of Inherit (or the general setting).
+type ModeConfig struct {
+	// Inherit is the name of the mode to inherit.
+	Inherit string `json:",omitempty"`
+
+	TabWidth         *int    `json:",omitempty"`
+	Header           *int    `json:",omitempty"`
+	HeaderLine       *int    `json:",omitempty"`
+	AlternateRows    *bool   `json:",omitempty"`
+	AlternateEvery   *int    `json:",omitempty"`
+	ColumnMode       *bool   `json:",omitempty"`
+	ColumnRainbow    *bool   `json:",omitempty"`
+	ColumnBand       *bool   `json:",omitempty"`
+	LineNumMode      *bool   `json:",omitempty"`
+	CursorLine       *bool   `json:",omitempty"`
+	DimUnmatched     *bool   `json:",omitempty"`
+	WrapMode         *bool   `json:",omitempty"`
+	TruncateMode     *bool   `json:",omitempty"`
+	CursorMove       *bool   `json:",omitempty"`
+	CollapseCR       *bool   `json:",omitempty"`
+	ColumnDelimiter  *string `json:",omitempty"`
+	SectionDelimiter *string `json:",omitempty"`
+	SectionHeaderNum *int    `json:",omitempty"`
+	LogLevel         *bool   `json:",omitempty"`
+	UnusualSpace     *bool   `json:",omitempty"`
+	Timestamp        *bool   `json:",omitempty"`
+	MaxLines         *int    `json:",omitempty"`
+	FollowMode       *bool   `json:",omitempty"`
+	FollowAll        *bool   `json:",omitempty"`
+}
+
+// apply returns g overwritten by the specified fields of the mode.
+func (v ModeConfig) apply(g general) general {
+	if v.TabWidth != nil {
+		g.TabWidth = *v.TabWidth
+	}
+	if v.Header != nil {
+		g.Header = *v.Header
+	}
+	if v.HeaderLine != nil {
+		g.HeaderLine = *v.HeaderLine
+	}
+	if v.AlternateRows != nil {
+		g.AlternateRows = *v.AlternateRows
+	}
+	if v.AlternateEvery != nil {
+		g.AlternateEvery = *v.AlternateEvery
+	}
+	if v.ColumnMode != nil {
+		g.ColumnMode = *v.ColumnMode
+	}
+	if v.ColumnRainbow != nil {
+		g.ColumnRainbow = *v.ColumnRainbow
+	}
+	if v.ColumnBand != nil {
+		g.ColumnBand = *v.ColumnBand
+	}
+	if v.LineNumMode != nil {
+		g.LineNumMode = *v.LineNumMode
+	}
+	if v.CursorLine != nil {
+		g.CursorLine = *v.CursorLine
+	}
+	if v.DimUnmatched != nil {
+		g.DimUnmatched = *v.DimUnmatched
+	}
+	if v.WrapMode != nil {
+		g.WrapMode = *v.WrapMode
+	}
+	if v.TruncateMode != nil {
+		g.TruncateMode = *v.TruncateMode
+	}
+	if v.CursorMove != nil {
+		g.CursorMove = *v.CursorMove
+	}
+	if v.CollapseCR != nil {
+		g.CollapseCR = *v.CollapseCR
+	}
+	if v.ColumnDelimiter != nil {
+		g.ColumnDelimiter = *v.ColumnDelimiter
+	}
+	if v.SectionDelimiter != nil {
+		g.SectionDelimiter = *v.SectionDelimiter
+	}
+	if v.SectionHeaderNum != nil {
+		g.SectionHeaderNum = *v.SectionHeaderNum
+	}
+	if v.LogLevel != nil {
+		g.LogLevel = *v.LogLevel
+	}
+	if v.UnusualSpace != nil {
+		g.UnusualSpace = *v.UnusualSpace
+	}
+	if v.Timestamp != nil {
+		g.Timestamp = *v.Timestamp
+	}
+	if v.MaxLines != nil {
+		g.MaxLines = *v.MaxLines
+	}
+	if v.FollowMode != nil {
+		g.FollowMode = *v.FollowMode
+	}
+	if v.FollowAll != nil {
+		g.FollowAll = *v.FollowAll
+	}
+	return g
+}
+
+// lookupMode returns the view mode of the name.
+// The name is case-insensitive because the config keys are lowercased.
+func (root *Root) lookupMode(name string) (ModeConfig, bool) {
+	if mode, ok := root.Config.Mode[name]; ok {
+		return mode, true
+	}
+	for n, mode := range root.Config.Mode {
+		if strings.EqualFold(n, name) {
+			return mode, true
+		}
+	}
+	return ModeConfig{}, false
+}
+
+// modeGeneral returns the mode of the name applied on top of base.
+// The inherited modes are applied in order from the root of the inheritance.
+func (root *Root) modeGeneral(base general, name string) (general, error) {
+	chain := make([]ModeConfig, 0, 1)
+	seen := make(map[string]bool)
+	for n := name; n != ""; {
+		key := strings.ToLower(n)
+		if seen[key] {
+			return base, fmt.Errorf("%w: %s", ErrModeInheritLoop, n)
+		}
+		seen[key] = true
+
+		mode, ok := root.lookupMode(n)
+		if !ok {
+			return base, fmt.Errorf("%s mode %w", n, ErrNotFound)
+		}
+		chain = append(chain, mode)
+		n = mode.Inherit
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		base = chain[i].apply(base)
+	}
+	return base, nil
+}
+
+// applyModeRules applies the view mode of the first matching rule to the document.
+func (root *Root) applyModeRules(m *Document) {
+	m.general = root.configGeneral(m)
+}
+
+// configGeneral returns the general settings of the document given by the config,
+// which is General with the view mode of the first matching rule applied.
+func (root *Root) configGeneral(m *Document) general {
+	for _, rule := range root.Config.ModeRules {
+		if !rule.match(m.FileName) {
+			continue
+		}
+		g, err := root.modeGeneral(root.General, rule.Mode)
+		if err != nil {
+			log.Println(err)
+			return root.General
+		}
+		log.Printf("%s: mode %s", m.FileName, rule.Mode)
+		return g
+	}
+	return root.General
+}
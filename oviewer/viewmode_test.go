@@ -0,0 +1,123 @@
+package oviewer
+
+import (
+	"testing"
+)
+
+func TestViewModeRule_match(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     ViewModeRule
+		fileName string
+		want     bool
+	}{
+		{
+			name:     "testPattern",
+			rule:     ViewModeRule{Pattern: "*.csv", Mode: "csv"},
+			fileName: "/tmp/test.csv",
+			want:     true,
+		},
+		{
+			name:     "testPatternNotMatch",
+			rule:     ViewModeRule{Pattern: "*.csv", Mode: "csv"},
+			fileName: "/tmp/test.tsv",
+			want:     false,
+		},
+		{
+			name:     "testPathPattern",
+			rule:     ViewModeRule{Pattern: "/var/log/*", Mode: "log"},
+			fileName: "/var/log/syslog",
+			want:     true,
+		},
+		{
+			name:     "testMIME",
+			rule:     ViewModeRule{MIME: "text/html", Mode: "html"},
+			fileName: "index.html",
+			want:     true,
+		},
+		{
+			name:     "testMIMETsv",
+			rule:     ViewModeRule{MIME: "text/tab-separated-values", Mode: "tsv"},
+			fileName: "data.TSV",
+			want:     true,
+		},
+		{
+			name:     "testEmpty",
+			rule:     ViewModeRule{Mode: "none"},
+			fileName: "test.txt",
+			want:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.match(tt.fileName); got != tt.want {
+				t.Errorf("ViewModeRule.match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoot_modeGeneral(t *testing.T) {
+	intP := func(n int) *int { return &n }
+	boolP := func(b bool) *bool { return &b }
+	strP := func(s string) *string { return &s }
+	root := &Root{
+		Config: Config{
+			Mode: map[string]ModeConfig{
+				"csv": {
+					Header:          intP(1),
+					ColumnMode:      boolP(true),
+					ColumnDelimiter: strP(","),
+				},
+				"tsv": {
+					Inherit:         "Csv",
+					ColumnDelimiter: strP("\t"),
+				},
+				"loop1": {Inherit: "loop2"},
+				"loop2": {Inherit: "loop1"},
+			},
+		},
+	}
+	base := general{TabWidth: 8, WrapMode: true}
+	tests := []struct {
+		name    string
+		mode    string
+		want    general
+		wantErr bool
+	}{
+		{
+			name: "testMode",
+			mode: "csv",
+			want: general{TabWidth: 8, WrapMode: true, Header: 1, ColumnMode: true, ColumnDelimiter: ","},
+		},
+		{
+			name: "testInherit",
+			mode: "tsv",
+			want: general{TabWidth: 8, WrapMode: true, Header: 1, ColumnMode: true, ColumnDelimiter: "\t"},
+		},
+		{
+			name:    "testLoop",
+			mode:    "loop1",
+			want:    base,
+			wantErr: true,
+		},
+		{
+			name:    "testNotFound",
+			mode:    "notfound",
+			want:    base,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := root.modeGeneral(base, tt.mode)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Root.modeGeneral() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Root.modeGeneral() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
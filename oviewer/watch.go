@@ -0,0 +1,202 @@
+package oviewer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// defaultWatchInterval is the interval of the watch command when WatchInterval is not set.
+const defaultWatchInterval = 2 * time.Second
+
+// watchSeparator is the format of the separator line of each run in the append mode.
+const watchSeparator = "==> %s <=="
+
+// watchCommand represents the command that is run periodically.
+type watchCommand struct {
+	args []string
+	// doc is the document of the last run.
+	doc *Document
+	// prev is the output lines of the last run.
+	prev []string
+}
+
+// WatchCommand returns the structure of oviewer
+// that runs the command periodically and displays the output.
+// The lines changed from the previous run are highlighted with StyleWatchChange.
+func WatchCommand(args ...string) (*Root, error) {
+	if len(args) == 0 {
+		return nil, ErrMissingFile
+	}
+	w := &watchCommand{args: args}
+	m, err := w.run(nil, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	root, err := NewOviewer(m)
+	if err != nil {
+		return nil, err
+	}
+	root.watch = w
+	return root, nil
+}
+
+// output runs the command and returns the lines of stdout and stderr.
+func (w *watchCommand) output() []string {
+	command := exec.Command(w.args[0], w.args[1:]...)
+	out, err := command.CombinedOutput()
+	if err != nil {
+		log.Printf("watch: %v", err)
+		if len(out) == 0 {
+			out = []byte(err.Error())
+		}
+	}
+	str := strings.TrimSuffix(string(bytes.ReplaceAll(out, []byte("\r\n"), []byte("\n"))), "\n")
+	return strings.Split(str, "\n")
+}
+
+// run runs the command and returns the document of the output.
+// In the append mode (m is not nil), the output is appended to m after a separator line.
+func (w *watchCommand) run(m *Document, now time.Time) (*Document, error) {
+	lines := w.output()
+	changed := watchChanges(w.prev, lines)
+	w.prev = lines
+
+	if m == nil {
+		var err error
+		m, err = NewDocument()
+		if err != nil {
+			return nil, err
+		}
+		m.FileName = fmt.Sprintf("watch:%s", strings.Join(w.args, " "))
+		m.watchChanged = changed
+		for _, line := range lines {
+			m.append(line)
+		}
+		close(m.eofCh)
+		atomic.StoreInt32(&m.eof, 1)
+		w.doc = m
+		return m, nil
+	}
+
+	m.append(fmt.Sprintf(watchSeparator, now.Format("15:04:05")))
+	start := m.BufEndNum()
+	for _, line := range lines {
+		m.append(line)
+	}
+	m.mu.Lock()
+	for n, prev := range changed {
+		m.watchChanged[start+n] = prev
+	}
+	m.mu.Unlock()
+	atomic.StoreInt32(&m.changed, 1)
+	return m, nil
+}
+
+// watchChanges returns the lines of cur that are changed or added from prev,
+// mapped to the lines of prev that they replace ("" for the added lines).
+func watchChanges(prev []string, cur []string) map[int]string {
+	changed := make(map[int]string)
+	if prev == nil {
+		return changed
+	}
+	x, y := 0, 0
+	var dels []string
+	for _, op := range diffLines(prev, cur) {
+		switch op {
+		case diffEqual:
+			dels = dels[:0]
+			x++
+			y++
+		case diffDelete:
+			dels = append(dels, prev[x])
+			x++
+		case diffInsert:
+			changed[y] = ""
+			if len(dels) > 0 {
+				changed[y] = dels[0]
+				dels = dels[1:]
+			}
+			y++
+		}
+	}
+	return changed
+}
+
+// watchPrevLine returns the line of the previous run that the line replaces,
+// and true if the line is changed from the previous run.
+func (m *Document) watchPrevLine(lN int) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	prev, ok := m.watchChanged[lN]
+	return prev, ok
+}
+
+// watchStyle applies the style of the change from the previous run to the line.
+func (root *Root) watchStyle(lc lineContents, lN int) {
+	m := root.Doc
+	prev, ok := m.watchPrevLine(lN)
+	if !ok {
+		return
+	}
+	root.lineStyle(lc, root.StyleWatchChange)
+	_, spans := charDiff(expandTabs(plainLine(prev), m.TabWidth), expandTabs(plainLine(m.GetLine(lN)), m.TabWidth))
+	spanStyle(lc, spans, 0, root.StyleDiffChar)
+}
+
+// eventWatch represents the event of the new output of the watch command.
+type eventWatch struct {
+	old *Document
+	m   *Document
+	tcell.EventTime
+}
+
+// watchLoop runs the watch command at WatchInterval.
+func (root *Root) watchLoop(ctx context.Context) {
+	interval := time.Duration(root.WatchInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	ticker := root.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C():
+			w := root.watch
+			if root.WatchAppend {
+				if _, err := w.run(w.doc, now); err != nil {
+					log.Println(err)
+				}
+				continue
+			}
+			old := w.doc
+			m, err := w.run(nil, now)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			ev := &eventWatch{old: old, m: m}
+			ev.SetEventNow()
+			if err := root.Screen.PostEvent(ev); err != nil {
+				log.Println(err)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchReplace replaces the document of the previous run with the new one,
+// keeping the position and the modes.
+func (root *Root) watchReplace(old *Document, m *Document) {
+	root.replaceDocument(old, m)
+	old.release()
+	root.setMessage(fmt.Sprintf("watch: %s", root.clock.Now().Format("15:04:05")))
+}
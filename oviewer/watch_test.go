@@ -0,0 +1,75 @@
+package oviewer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_watchChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []string
+		cur  []string
+		want map[int]string
+	}{
+		{
+			name: "first",
+			prev: nil,
+			cur:  []string{"a", "b"},
+			want: map[int]string{},
+		},
+		{
+			name: "same",
+			prev: []string{"a", "b"},
+			cur:  []string{"a", "b"},
+			want: map[int]string{},
+		},
+		{
+			name: "changed",
+			prev: []string{"a", "b", "c"},
+			cur:  []string{"a", "x", "c"},
+			want: map[int]string{1: "b"},
+		},
+		{
+			name: "added",
+			prev: []string{"a"},
+			cur:  []string{"a", "b", "c"},
+			want: map[int]string{1: "", 2: ""},
+		},
+		{
+			name: "removed",
+			prev: []string{"a", "b", "c"},
+			cur:  []string{"a", "c"},
+			want: map[int]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watchChanges(tt.prev, tt.cur); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("watchChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_watchCommand_run(t *testing.T) {
+	w := &watchCommand{args: []string{"echo", "a"}}
+	m, err := w.run(nil, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetLine(0); got != "a" {
+		t.Errorf("watchCommand.run() = %v, want %v", got, "a")
+	}
+	w.args = []string{"echo", "b"}
+	if _, err := w.run(m, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.BufEndNum(); got != 3 {
+		t.Errorf("watchCommand.run() lines = %v, want %v", got, 3)
+	}
+	if prev, ok := m.watchPrevLine(2); !ok || prev != "a" {
+		t.Errorf("watchCommand.run() watchPrevLine(2) = %v, %v, want %v, %v", prev, ok, "a", true)
+	}
+}
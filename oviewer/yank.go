@@ -0,0 +1,67 @@
+package oviewer
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// yankLine copies the current line, or the number of lines of the count prefix,
+// to the clipboard.
+func (root *Root) yankLine() {
+	m := root.Doc
+	n := root.takeCount()
+	start := m.topLN + m.Header
+	end := min(start+n, m.BufEndNum())
+	if start >= end {
+		return
+	}
+	root.yank(yankLines(m, start, end))
+	root.setMessage(fmt.Sprintf("Yanked %d lines", end-start))
+}
+
+// yankCell copies the cell of the selected column of the current line to the clipboard.
+func (root *Root) yankCell() {
+	m := root.Doc
+	if !m.ColumnMode {
+		root.setMessage("column mode is not enabled")
+		return
+	}
+	line := plainLine(m.GetLine(m.topLN + m.Header))
+	start, end := columnRange(line, m.ColumnDelimiter, m.columnNum)
+	if start < 0 {
+		root.setMessage("no column")
+		return
+	}
+	cell := strings.TrimSpace(line[start:end])
+	root.yank(cell)
+	root.setMessage(fmt.Sprintf("Yanked %s", cell))
+}
+
+// yank writes the string to the clipboard and the register.
+// The register is used for paste when the clipboard is not available.
+func (root *Root) yank(str string) {
+	root.register = str
+	if err := clipboard.WriteAll(str); err != nil {
+		log.Printf("yank: %v", err)
+	}
+}
+
+// yankLines returns the lines [start, end) without the escape sequences.
+func yankLines(m *Document, start int, end int) string {
+	lines := make([]string, 0, end-start)
+	for n := start; n < end; n++ {
+		lines = append(lines, plainLine(m.GetLine(n)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// plainLine returns the line without the escape sequences.
+func plainLine(line string) string {
+	if strings.ContainsAny(line, "\x1b\b") {
+		return stripEscapeSequence.ReplaceAllString(line, "")
+	}
+	return line
+}
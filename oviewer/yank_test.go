@@ -0,0 +1,24 @@
+package oviewer
+
+import "testing"
+
+func Test_yankLines(t *testing.T) {
+	m := testLineDocument(t, 0, "a", "\x1b[1mb\x1b[0m", "c")
+	tests := []struct {
+		name  string
+		start int
+		end   int
+		want  string
+	}{
+		{name: "one", start: 0, end: 1, want: "a"},
+		{name: "escape", start: 1, end: 3, want: "b\nc"},
+		{name: "all", start: 0, end: 3, want: "a\nb\nc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := yankLines(m, tt.start, tt.end); got != tt.want {
+				t.Errorf("yankLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}